@@ -51,11 +51,14 @@ import (
 import (
 	"github.com/timtadh/regrax/config"
 	"github.com/timtadh/regrax/lattice"
+	"github.com/timtadh/regrax/metrics"
 	"github.com/timtadh/regrax/reporters"
 	"github.com/timtadh/regrax/sample/miners"
 	"github.com/timtadh/regrax/types/digraph"
 	"github.com/timtadh/regrax/types/digraph/subgraph"
 	"github.com/timtadh/regrax/types/itemset"
+	"github.com/timtadh/regrax/types/sequence"
+	"github.com/timtadh/regrax/types/tree"
 )
 
 func init() {
@@ -84,12 +87,14 @@ var ErrorCodes map[string]int = map[string]int{
 var UsageMessage string
 var ExtendedMessage string
 
-var CommonUsage string = TypesUsage + ReportersUsage
+var CommonUsage string = TypesUsage + ReportersUsage + ServingUsage
 
 var TypesUsage string = `
 Types
 
-    itemset                   sets of items, treated as sets of integers
+    itemset                   sets of items (integers or strings)
+    sequence                  ordered sequences of items (gaps allowed)
+    tree                      forests of rooted labeled trees (eg. ASTs)
     digraph                   large directed graphs
 
     itemset Exmaple
@@ -104,6 +109,10 @@ Types
         -l, loader=<loader-name> the loader to use (default int)
         --min-items=<int>        minimum items in a samplable set
         --max-items=<int>        maximum items in a samplable set
+        --separator=<string>     item separator for the labeled loader
+                                 (default: any whitespace)
+        --tx-id-column=<int>     column (0 based) of the labeled loader
+                                 holding the transaction id (default: none)
 
     itemset Loaders
 
@@ -117,6 +126,85 @@ Types
             23 1 4 5 7
             3 4 1
 
+       labeled                     each line is a transaction
+                                   the items are strings
+                                   the items are separated by --separator
+                                   patterns are printed with the item names
+
+       labeled Example file (--separator=, --tx-id-column=0):
+            order-1,bread,milk,eggs
+            order-2,milk,beer
+            order-3,bread,milk,beer
+
+
+    sequence Example
+
+        $ regrax mine -o /tmp/seqs --support=10 \
+            sequence --max-length=5 ./data/traces.txt \
+            vsigram
+
+    sequence Options
+
+        -h, help                 view this message
+        -l, loader=<loader-name> the loader to use (default line)
+        --min-length=<int>       minimum length of a samplable sequence
+        --max-length=<int>       maximum length of a samplable sequence
+        --separator=<string>     item separator (default: any whitespace)
+
+    sequence Loaders
+
+       line                        each line is a sequence
+                                   the items are strings
+                                   the items are separated by --separator
+
+       line Example file:
+            open read read close
+            open write close
+            open read write close
+
+       A sequence supports a pattern if the pattern can be obtained from it
+       by deleting items. The children of a pattern insert one item at any
+       position. The canonical kids only append an item to the end.
+
+
+    tree Example
+
+        $ regrax mine -o /tmp/asts --support=50 \
+            tree --unordered --max-nodes=8 ./data/asts.sexpr \
+            vsigram
+
+    tree Options
+
+        -h, help                 view this message
+        -l, loader=<loader-name> the loader to use (default sexpr)
+        --min-nodes=<int>        minimum nodes in a samplable tree
+        --max-nodes=<int>        maximum nodes in a samplable tree
+        --embedded               match embedded subtrees: a parent-child edge
+                                 in a pattern may match any ancestor-descendant
+                                 pair (default: induced subtrees, edges must
+                                 match parent-child edges)
+        --unordered              ignore the order of siblings
+
+        The support of a pattern is the number of input trees it occurs in.
+        The canonical kids of a pattern are its rightmost path extensions.
+
+    tree Loaders
+
+       sexpr                       a stream of s-expressions, one tree per
+                                   top level expression. The first atom of a
+                                   list is the label, the rest are children.
+                                   Atoms may be "quoted". ; starts a comment.
+
+       sexpr Example file:
+            (if (< a b) (call f a) (return b))
+            (while (< i n) (assign i (+ i 1)))
+
+       json                        a stream of json trees
+
+       json Example file:
+            {"label": "if", "children": [{"label": "<"}, {"label": "call"}]}
+            {"label": "return", "children": [{"label": "b"}]}
+
 
     digraph Example
 
@@ -137,6 +225,72 @@ Types
         --overlap-pruning        (see below)
         --extension-pruning      (see below)
         --no-caching             do not cache any lattice nodes.
+        --transactions           treat each file in the input directory as a
+                                 separate transaction graph. The support of
+                                 a pattern is the number of files it is
+                                 embedded in. (requires a directory input)
+        --induced                only count induced embeddings: an embedding
+                                 is discarded if the graph has edges between
+                                 the embedded vertices which are not in the
+                                 pattern (eg. a path does not match a
+                                 triangle). The patterns grow by a vertex
+                                 (with all of its edges) at a time.
+        --homomorphism           match patterns under homomorphism (see the
+                                 support counting section below)
+        --approximate            estimate MNI support by sampling embeddings
+                                 (see the support counting section below)
+        --approx-samples=<int>   vertices to sample for each vertex of a
+                                 pattern when approximating (default: 100)
+        --approx-confidence=<float>
+                                 confidence of the approximate support bounds
+                                 (default: .95)
+        --max-embeddings=<int>   keep at most this many embeddings of each
+                                 pattern once its support is known. Support
+                                 and extensions are still computed from all
+                                 of them. Truncated patterns are flagged in
+                                 the output. (default: 0, keep all)
+        --shape=<shape>          only generate patterns of the given shape.
+                                 May be given more than once (or as a comma
+                                 separated list) in which case the patterns
+                                 must have every shape. Shapes:
+                                   path           (edge direction ignored)
+                                   directed-path  (eg. call chains)
+                                   tree           (edge direction ignored)
+                                   dag            (no directed cycles)
+                                 A rooted tree (eg. a dominator tree fragment)
+                                 is a tree with --max-in-degree=1.
+        --max-in-degree=<int>    maximum in degree of a pattern vertex
+        --max-out-degree=<int>   maximum out degree of a pattern vertex
+        --weight=<attr>          the numeric vertex and edge attribute the
+                                 weighted support modes read (eg. an execution
+                                 count). Vertices and edges without it weigh
+                                 1. (veg and dot loaders)
+        --temporal               mine time respecting patterns. Every edge
+                                 needs a numeric "time" attribute (veg and dot
+                                 loaders). The edges of a pattern are ordered
+                                 (shown as @1, @2, ...) and an embedding only
+                                 counts if the edges it maps to have strictly
+                                 increasing times in that order. Parallel
+                                 edges between the same vertices are allowed
+                                 in the input (eg. repeated interactions).
+                                 Cannot be used with --extension-pruning.
+        --time-window=<int>      with --temporal, the last edge of an embedding
+                                 must happen at most this long after the first
+                                 (default: 0, unbounded)
+        --label-sets             vertices may have extra labels (eg. modifiers
+                                 and annotations) given by their "labels"
+                                 attribute: a list of strings (veg) or a comma
+                                 separated string (dot). A pattern vertex
+                                 matches a vertex with the same label whose
+                                 extra labels contain the pattern vertex's
+                                 extra labels. Patterns grow by adding edges
+                                 or by adding an extra label to a vertex.
+                                 Label sets are shown as label{extra,...}.
+                                 The label is always part of a pattern
+                                 vertex's set: a pattern vertex never matches
+                                 vertices with different labels (eg. "any
+                                 vertex annotated @x") even if they share the
+                                 extra labels.
         --min-edges=<int>        minimum edges in a samplable digraph
         --max-edges=<int>        maximum edges in a samplable digraph
         --min-vertices=<int>     minimum vertices in a samplable digraph
@@ -200,6 +354,78 @@ Types
                                  higher support number than FIS but is otherwise
                                  equivalent. GIS is an unsound counting mode.
 
+        MIS (Max. Indep. Set)    The support of a subgraph is the size of the
+                                 maximum independent set of its overlap graph.
+                                 The overlap graph has a vertex for each
+                                 embedding and an edge between embeddings which
+                                 share a vertex [2]. MIS is sound. Automorphic
+                                 rotations of an embedding overlap so they are
+                                 only counted once. Each connected component of
+                                 the overlap graph with at most 64 embeddings is
+                                 solved exactly, larger components use a greedy
+                                 minimum degree heuristic (a lower bound).
+
+        [2] N. Vanetik, E. Gudes, and S. E. Shimony, "Computing frequent graph
+            patterns from semistructured data," in Proceedings of the 2002 IEEE
+            International Conference on Data Mining, 2002, pp. 458–465.
+
+        WSUM (Weighted Sum)      The support of a subgraph is the sum of the
+                                 weights of its embeddings. The weight of an
+                                 embedding is the total weight (see --weight)
+                                 of the vertices and edges it maps to. So an
+                                 embedding on a hot path can outweigh many in
+                                 dead code. WSUM is not anti-monotone: a child
+                                 may outweigh its parent so patterns below a
+                                 parent under the threshold may be missed. It
+                                 cannot be combined with --extension-pruning.
+
+        WMIN (Weighted Min)      As WSUM but the weight of an embedding is the
+                                 least weight of the vertices and edges it maps
+                                 to (eg. how often the whole embedding ran).
+                                 WMIN is not anti-monotone either (see WSUM).
+
+        WMNI (Weighted MNI)      Minimum image support where each distinct
+                                 image of a pattern vertex counts the (WMIN)
+                                 weight of the heaviest embedding through it
+                                 rather than 1. WMNI is anti-monotone.
+
+                                 The weighted supports are rounded down when
+                                 compared to --support and shown in the output.
+
+        Homomorphism (--homomorphism)
+                                 By default embeddings are subgraph
+                                 isomorphisms: each vertex of the pattern maps
+                                 to a distinct vertex of the graph. Under
+                                 homomorphism the mapping need not be injective
+                                 (only edges must map to edges) so a path
+                                 a -> b -> a' may be embedded in a loop
+                                 a <-> b by mapping a and a' to the same vertex.
+                                 The embedding search is much cheaper as it can
+                                 not fail on injectivity or degree checks. It
+                                 may be combined with any of the support modes
+                                 above which are then computed over the
+                                 homomorphic embeddings. Homomorphic MNI support
+                                 is at least the isomorphic MNI support.
+
+        Approximate MNI (--approximate)
+                                 Computing MNI support requires finding every
+                                 embedding. Instead, for each vertex of the
+                                 pattern --approx-samples vertices of the graph
+                                 with the same label are sampled and a
+                                 randomized search looks for an embedding
+                                 through each of them. The fraction found
+                                 estimates the number of images of the vertex
+                                 and a Hoeffding bound gives an interval which
+                                 holds with probability --approx-confidence. A
+                                 pattern is accepted if the lower bound is at
+                                 least --support and rejected if the upper bound
+                                 is below it. Otherwise the support is computed
+                                 exactly. Accepted patterns are flagged with
+                                 their estimate in the output and only their
+                                 sampled embeddings are reported. Approximate
+                                 mining may miss frequent patterns or report
+                                 infrequent ones (at the given confidence).
+
         Notes on support:
 
             Most of the time the best support option to use is MNI and it is the
@@ -219,6 +445,7 @@ Types
                 FIS support: 1
                 GIS support: 4
                 MNI support: 4
+                MIS support: 4
 
             FIS is a partially unsound support counting metric. Here is an
             example where it will violate downward closure. Downward closure
@@ -337,11 +564,28 @@ Types
             edge -> "edge" "\t" edge_json
 
             vertex_json -> {"id": int, "label": string, ...}
-            // other items are optional
+            // other items are optional (eg. "labels": [string, ...] with
+            // --label-sets)
 
             edge_json -> {"src": int, "targ": int, "label": int, ...}
             // other items are  optional
 
+        Self-Loops and Parallel Edges
+            Edges from a vertex to itself and several edges between the same
+            pair of vertices (eg. the true and false branches of a control
+            flow graph) are supported by every loader and count mode.
+            Patterns may contain them as well. Parallel edges with the same
+            label are one edge as far as matching is concerned.
+
+        Directory Inputs
+            If the <input-path> is a directory every file in it is loaded
+            (using the chosen loader) into one graph. Vertex ids only need to
+            be unique within a file: ids are namespaced per file so the same
+            id in two files refers to two different vertices. The name of the
+            file each vertex came from is recorded in its "file" attribute.
+            Use --transactions to count support per file rather than over
+            the combined graph.
+
 `
 
 var ReportersUsage string = `
@@ -354,13 +598,39 @@ Reporters
                                 canonical-edge frequent pattern tree
     skip                      skip a specified (-s) number of patterns between
                                 each reported pattern
+    filter                    only pass the patterns satisfying an expression
+                                (-e) to the inner reporter
+    top                       pass the N highest ranked patterns (best first) to
+                                the inner reporter when the miner finishes
     log                       log the samples
     file                      write the samples to a file in the output dir
     dir                       write samples to a nested dir format
+    html                      write a browsable static site of the samples
     count                     write the count of samples to a file
     unique                    takes an "inner reporter" but only passes the
                                 unique samples to inner reporter. (useful in
                                 conjunction with --non-unique)
+    rules                     write the association rules derived from each
+                                itemset (itemset type only)
+    lattice                   write the explored part of the lattice: the
+                                reported nodes and the parent/child edges
+                                among them
+    coverage                  write which vertices and edges of the graph are
+                                covered by embeddings of the reported
+                                patterns (digraph type only)
+    occurrences               write a table mapping every embedding vertex back
+                                to its original id and attributes (digraph
+                                type only)
+    compress                  write the set of reported patterns which best
+                                compresses the graph (minimum description
+                                length) with the gain of each (digraph type
+                                only)
+    hierarchical              cluster the patterns agglomeratively and write
+                                the dendrogram, the clusters and their
+                                quality (digraph type only)
+    k-medoids                 cluster the patterns around k medoids and write
+                                the clusters and their quality (digraph type
+                                only)
 
     log Options
         -l, level=<string>    log level the logger should use
@@ -397,6 +667,33 @@ Reporters
         --show-pr             show the selection probability (when applicable)
                               NB: may cause extra (and excessive computation)
 
+    html Options
+        -d, dir-name=<name>   name of the directory. (default: html)
+        --show-pr             show the selection probability (when applicable)
+                              NB: may cause extra (and excessive computation)
+        --page-size=<int>     embeddings listed per page (default: 50)
+
+        Note: index.html lists the patterns. Each pattern has a page (or
+              pages) drawing it and listing its embeddings. For digraphs
+              the pattern is drawn as inline SVG (no graphviz needed) and
+              each embedding shows the oid and attributes of its vertices.
+
+    filter Options
+        -e, expr=<expr>       the predicate. eg.
+                                support >= 50 && vertices >= 4 &&
+                                has_label("^java/io")
+
+` + reporters.FilterUsage + `
+
+    top Options
+        -n, count=<int>       the number of patterns to keep (default: 10)
+        --by=<expr>           the rank of a pattern, an expression in the
+                              filter language, eg. support, vertices,
+                              coverage or support*vertices (default: support)
+        --in-memory=<int>     patterns kept in memory before spilling to the
+                              cache (default: 1000). Only digraph patterns
+                              with --cache and caching on are spilled.
+
     count Options
         -f, --filename=<name> name of the file to write the count.
                               (default: count)
@@ -405,6 +702,99 @@ Reporters
         --histogram=<name>    if set unique will write the histogram of how many
                               times each node is sampled.
 
+    rules Options
+        -f, --filename=<name> name of the file to write the rules.
+                              (default: rules.<format>)
+        --format=<format>     csv or json (default: csv)
+        --min-confidence=<float>
+                              only write rules with at least this confidence
+                              supp(X u Y)/supp(X) (default: 0)
+        --min-lift=<float>    only write rules with at least this lift
+                              conf(X -> Y)/(supp(Y)/|transactions|)
+                              (default: 0)
+
+    coverage Options
+        -f, --filename=<name> name of the file to write the coverage.
+                              (default: coverage.json)
+
+        Note: the file has the overall and per-label coverage and the
+              uncovered vertices (by oid) and edges. Every embedding of the
+              reported patterns counts, not only the ones kept on them
+              (eg. under --max-embeddings or MNI support).
+
+    occurrences Options
+        -f, --filename=<name> name of the file to write the table.
+                              (default: occurrences.<format>)
+        --format=<format>     csv or jsonl (default: csv)
+        -a, --attrs=<names>   comma separated vertex attributes to add as
+                              columns, eg. file,start_line (default: none)
+
+        Note: there is a row per vertex of every embedding (not only the
+              ones kept on the pattern) with the columns pattern,
+              pattern_name, embedding, vertex, oid, label and then the
+              selected attributes (empty when a vertex lacks one). The
+              attributes may not be named as one of those columns.
+
+    compress Options
+        -f, --filename=<name> name of the file to write the chosen patterns.
+                              (default: compress.json)
+        --max-patterns=<int>  choose at most this many patterns (default: 0,
+                              no limit)
+
+        Note: patterns are chosen greedily (as in SUBDUE and Krimp). Each step
+              adds the pattern whose disjoint embeddings, each replaced by a
+              single vertex, shorten the description of the graph (in bits)
+              the most, counting the description of the pattern itself. It
+              stops when no pattern shortens the description. Every
+              embedding of a pattern is considered, not only the ones kept
+              on it.
+
+    hierarchical Options
+        -c, --clusters-name=<name>   the file for the clusters (default:
+                                     clusters)
+        -m, --metrics-name=<name>    the file for the cluster quality metrics
+                                     (default: metrics)
+        -d, --dendrogram-name=<name> the file for the dendrogram (default:
+                                     dendrogram)
+        --metric=<metric>            structure, label or attr (default:
+                                     structure)
+        -a, --attr=<attr>            the vertex attribute for the attr metric
+        --linkage=<linkage>          single, complete or average (default:
+                                     average)
+        -k, --clusters=<int>         cut the dendrogram into this many
+                                     clusters (default: 0, cut at
+                                     --max-distance)
+        --max-distance=<float>       cut the dendrogram at the merges above
+                                     this distance (default: 0.5)
+
+    k-medoids Options
+        -c, --clusters-name=<name>   the file for the clusters (default:
+                                     clusters)
+        -m, --metrics-name=<name>    the file for the cluster quality metrics
+                                     (default: metrics)
+        --metric=<metric>            structure, label or attr (default:
+                                     structure)
+        -a, --attr=<attr>            the vertex attribute for the attr metric
+        -k, --clusters=<int>         the number of clusters (default: 5)
+        --max-iterations=<int>       the most rounds of assigning patterns
+                                     and moving medoids (default: 100)
+
+        Note: the metrics are the structure distance (Pattern.Distance) and
+              the jaccard distances of the labels and of the values of the
+              attribute in the embeddings (as in dbscan). The clusters are
+              written as json lines (k-medoids marks the medoids). The
+              quality metrics are the correlation, intra-distance and mean
+              silhouette of the clusters under the metric, label and attr.
+
+    lattice Options
+        -f, --filename=<name> name of the file to write the lattice.
+                              (default: lattice.<format>)
+        --format=<format>     dot or graphml (default: dot)
+
+        Note: only the parents and children of reported nodes are looked at
+              so the lattice is not explored further than the miner went.
+              Nodes reported more than once are annotated with the count.
+
     Examples
 
         $ regrax sample -o <path> --samples=5 --support=5 \
@@ -435,6 +825,30 @@ Reporters
                 file -e non-unique-embeddings -p non-unique-patterns
 `
 
+var ServingUsage string = `
+Serving
+
+    With --serve=<addr> a small http server reports on the run while it
+    goes. It stops when the run is over.
+
+    GET /progress             counters (nodes expanded, patterns reported,
+                              samples accepted, rejected and duplicated),
+                              the cache hit rate and the elapsed time
+    GET /patterns             the patterns reported so far
+    GET /patterns/<id>        a pattern as json (with its dot for digraphs)
+    GET /patterns/<id><ext>   a pattern in the format of the type, eg. .dot
+    GET /events               a server-sent-events stream of new patterns.
+                              Reconnecting clients get the patterns after
+                              their Last-Event-ID (or ?after=<id>).
+
+    Example
+
+        $ regrax sample --serve=127.0.0.1:8080 -o <path> --samples=5 \
+            --support=5 digraph ./digraph.veg.gz graple
+        $ curl http://127.0.0.1:8080/progress
+        $ curl -N http://127.0.0.1:8080/events
+`
+
 func Usage(code int) {
 	if code == 0 {
 		fmt.Fprintln(os.Stdout, ExtendedMessage)
@@ -458,6 +872,14 @@ func Input(input_path string) (reader io.Reader, closeall func()) {
 	}
 }
 
+func isDir(input_path string) (bool, error) {
+	stat, err := os.Stat(input_path)
+	if err != nil {
+		return false, err
+	}
+	return stat.IsDir(), nil
+}
+
 func InputFile(input_path string) (reader io.Reader, closeall func()) {
 	freader, err := os.Open(input_path)
 	if err != nil {
@@ -501,6 +923,27 @@ func InputDir(input_dir string) (reader io.Reader, closeall func()) {
 	}
 }
 
+func InputFiles(input_dir string) []lattice.NamedInput {
+	dir, err := ioutil.ReadDir(input_dir)
+	if err != nil {
+		panic(err)
+	}
+	inputs := make([]lattice.NamedInput, 0, len(dir))
+	for _, info := range dir {
+		if info.IsDir() {
+			continue
+		}
+		fname := path.Join(input_dir, info.Name())
+		inputs = append(inputs, lattice.NamedInput{
+			Name: info.Name(),
+			Input: func() (io.Reader, func()) {
+				return InputFile(fname)
+			},
+		})
+	}
+	return inputs
+}
+
 func ParseInt(str string) int {
 	i, err := strconv.Atoi(str)
 	if err != nil {
@@ -651,7 +1094,7 @@ type Type func([]string, *config.Config) (lattice.Loader, func(lattice.DataType,
 func itemsetType(argv []string, conf *config.Config) (lattice.Loader, func(lattice.DataType, lattice.PrFormatter) lattice.Formatter, []string) {
 	args, optargs, err := getopt.GetOpt(
 		argv,
-		"hl:", []string{"help", "loader=", "min-items=", "max-items="},
+		"hl:", []string{"help", "loader=", "min-items=", "max-items=", "separator=", "tx-id-column="},
 	)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -661,6 +1104,8 @@ func itemsetType(argv []string, conf *config.Config) (lattice.Loader, func(latti
 	loaderType := "int"
 	min := 0
 	max := int(math.MaxInt32)
+	sep := ""
+	txIdCol := -1
 	for _, oa := range optargs {
 		switch oa.Opt() {
 		case "-h", "--help":
@@ -671,6 +1116,10 @@ func itemsetType(argv []string, conf *config.Config) (lattice.Loader, func(latti
 			min = ParseInt(oa.Arg())
 		case "--max-items":
 			max = ParseInt(oa.Arg())
+		case "--separator":
+			sep = oa.Arg()
+		case "--tx-id-column":
+			txIdCol = ParseInt(oa.Arg())
 		default:
 			fmt.Fprintf(os.Stderr, "Unknown flag '%v'\n", oa.Opt())
 			Usage(ErrorCodes["opts"])
@@ -681,6 +1130,8 @@ func itemsetType(argv []string, conf *config.Config) (lattice.Loader, func(latti
 	switch loaderType {
 	case "int":
 		loader, err = itemset.NewIntLoader(conf, min, max)
+	case "labeled":
+		loader, err = itemset.NewLabeledLoader(conf, min, max, sep, txIdCol)
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown itemset loader '%v'\n", loaderType)
 		Usage(ErrorCodes["opts"])
@@ -694,6 +1145,109 @@ func itemsetType(argv []string, conf *config.Config) (lattice.Loader, func(latti
 	return loader, fmtr, args
 }
 
+func sequenceType(argv []string, conf *config.Config) (lattice.Loader, func(lattice.DataType, lattice.PrFormatter) lattice.Formatter, []string) {
+	args, optargs, err := getopt.GetOpt(
+		argv,
+		"hl:", []string{"help", "loader=", "min-length=", "max-length=", "separator="},
+	)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		Usage(ErrorCodes["opts"])
+	}
+
+	loaderType := "line"
+	min := 0
+	max := int(math.MaxInt32)
+	sep := ""
+	for _, oa := range optargs {
+		switch oa.Opt() {
+		case "-h", "--help":
+			Usage(0)
+		case "-l", "--loader":
+			loaderType = oa.Arg()
+		case "--min-length":
+			min = ParseInt(oa.Arg())
+		case "--max-length":
+			max = ParseInt(oa.Arg())
+		case "--separator":
+			sep = oa.Arg()
+		default:
+			fmt.Fprintf(os.Stderr, "Unknown flag '%v'\n", oa.Opt())
+			Usage(ErrorCodes["opts"])
+		}
+	}
+
+	var loader lattice.Loader
+	switch loaderType {
+	case "line":
+		loader, err = sequence.NewLineLoader(conf, min, max, sep)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown sequence loader '%v'\n", loaderType)
+		Usage(ErrorCodes["opts"])
+	}
+	if err != nil {
+		log.Panic(err)
+	}
+	fmtr := func(_ lattice.DataType, prfmt lattice.PrFormatter) lattice.Formatter {
+		return &sequence.Formatter{prfmt}
+	}
+	return loader, fmtr, args
+}
+
+func treeType(argv []string, conf *config.Config) (lattice.Loader, func(lattice.DataType, lattice.PrFormatter) lattice.Formatter, []string) {
+	args, optargs, err := getopt.GetOpt(
+		argv,
+		"hl:", []string{"help", "loader=", "min-nodes=", "max-nodes=", "embedded", "unordered"},
+	)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		Usage(ErrorCodes["opts"])
+	}
+
+	loaderType := "sexpr"
+	min := 0
+	max := int(math.MaxInt32)
+	embedded := false
+	unordered := false
+	for _, oa := range optargs {
+		switch oa.Opt() {
+		case "-h", "--help":
+			Usage(0)
+		case "-l", "--loader":
+			loaderType = oa.Arg()
+		case "--min-nodes":
+			min = ParseInt(oa.Arg())
+		case "--max-nodes":
+			max = ParseInt(oa.Arg())
+		case "--embedded":
+			embedded = true
+		case "--unordered":
+			unordered = true
+		default:
+			fmt.Fprintf(os.Stderr, "Unknown flag '%v'\n", oa.Opt())
+			Usage(ErrorCodes["opts"])
+		}
+	}
+
+	var loader lattice.Loader
+	switch loaderType {
+	case "sexpr":
+		loader, err = tree.NewSExprLoader(conf, min, max, embedded, unordered)
+	case "json":
+		loader, err = tree.NewJsonLoader(conf, min, max, embedded, unordered)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown tree loader '%v'\n", loaderType)
+		Usage(ErrorCodes["opts"])
+	}
+	if err != nil {
+		log.Panic(err)
+	}
+	fmtr := func(_ lattice.DataType, prfmt lattice.PrFormatter) lattice.Formatter {
+		return &tree.Formatter{prfmt}
+	}
+	return loader, fmtr, args
+}
+
 func digraphType(argv []string, conf *config.Config) (lattice.Loader, func(lattice.DataType, lattice.PrFormatter) lattice.Formatter, []string) {
 	args, optargs, err := getopt.GetOpt(
 		argv,
@@ -708,6 +1262,20 @@ func digraphType(argv []string, conf *config.Config) (lattice.Loader, func(latti
 			"extend-from-embeddings",
 			"extend-from-freq-edges",
 			"no-caching",
+			"transactions",
+			"induced",
+			"homomorphism",
+			"approximate",
+			"approx-samples=",
+			"approx-confidence=",
+			"max-embeddings=",
+			"shape=",
+			"max-in-degree=",
+			"max-out-degree=",
+			"weight=",
+			"temporal",
+			"time-window=",
+			"label-sets",
 			"emb-search-starting-point=",
 			"min-edges=",
 			"max-edges=",
@@ -731,6 +1299,20 @@ func digraphType(argv []string, conf *config.Config) (lattice.Loader, func(latti
 	extendFromEdges := false
 	embSearchStartingPoint := subgraph.MostConnected
 	caching := true
+	transactions := false
+	induced := false
+	homomorphism := false
+	approximate := false
+	approxSamples := 100
+	approxConfidence := .95
+	maxEmbs := 0
+	var shape digraph.Shape
+	maxInDeg := 0
+	maxOutDeg := 0
+	weightAttr := ""
+	temporal := false
+	timeWindow := 0
+	labelSets := false
 	minE := 0
 	maxE := int(math.MaxInt32)
 	minV := 0
@@ -779,6 +1361,49 @@ func digraphType(argv []string, conf *config.Config) (lattice.Loader, func(latti
 			}
 		case "--no-caching":
 			caching = false
+		case "--transactions":
+			transactions = true
+		case "--induced":
+			induced = true
+		case "--homomorphism":
+			homomorphism = true
+		case "--approximate":
+			approximate = true
+		case "--approx-samples":
+			approxSamples = ParseInt(oa.Arg())
+		case "--approx-confidence":
+			approxConfidence = ParseFloat(oa.Arg())
+		case "--max-embeddings":
+			maxEmbs = ParseInt(oa.Arg())
+		case "--shape":
+			for _, name := range strings.Split(oa.Arg(), ",") {
+				switch strings.TrimSpace(name) {
+				case "path":
+					shape |= digraph.Paths
+				case "directed-path":
+					shape |= digraph.DirectedPaths
+				case "tree":
+					shape |= digraph.Trees
+				case "dag":
+					shape |= digraph.DAGs
+				default:
+					fmt.Fprintf(os.Stderr, "unknown shape for --shape %v\n", name)
+					fmt.Fprintln(os.Stderr, "valid shapes: path, directed-path, tree, dag")
+					Usage(ErrorCodes["opts"])
+				}
+			}
+		case "--max-in-degree":
+			maxInDeg = ParseInt(oa.Arg())
+		case "--max-out-degree":
+			maxOutDeg = ParseInt(oa.Arg())
+		case "--weight":
+			weightAttr = oa.Arg()
+		case "--temporal":
+			temporal = true
+		case "--time-window":
+			timeWindow = ParseInt(oa.Arg())
+		case "--label-sets":
+			labelSets = true
 		case "--min-edges":
 			minE = ParseInt(oa.Arg())
 		case "--max-edges":
@@ -820,10 +1445,20 @@ func digraphType(argv []string, conf *config.Config) (lattice.Loader, func(latti
 		mode |= digraph.FIS
 	case "GIS":
 		mode |= digraph.GIS
+	case "MIS":
+		mode |= digraph.MIS
+	case "WSUM":
+		mode |= digraph.WeightedSum
+	case "WMIN":
+		mode |= digraph.WeightedMin
+	case "WMNI":
+		mode |= digraph.WeightedMNI
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown support mode '%v'\n", modeStr)
 		fmt.Fprintf(os.Stderr, "support modes: MNI (min-image support), FIS (fully independent subgraphs)\n")
 		fmt.Fprintf(os.Stderr, "               GIS (greedy independent subgraphs)\n")
+		fmt.Fprintf(os.Stderr, "               MIS (maximum independent set of overlapping embeddings)\n")
+		fmt.Fprintf(os.Stderr, "               WSUM, WMIN, WMNI (weighted support, requires --weight)\n")
 		Usage(ErrorCodes["opts"])
 	}
 	if overlapPruning {
@@ -838,6 +1473,73 @@ func digraphType(argv []string, conf *config.Config) (lattice.Loader, func(latti
 	if caching {
 		mode |= digraph.Caching
 	}
+	if transactions {
+		if mode&(digraph.FIS|digraph.MIS|digraph.Weighted) != 0 {
+			fmt.Fprintf(os.Stderr, "Cannot use --transactions with the FIS, MIS or weighted support modes\n")
+			Usage(ErrorCodes["opts"])
+		} else if approximate {
+			fmt.Fprintf(os.Stderr, "Cannot use --transactions with --approximate\n")
+			Usage(ErrorCodes["opts"])
+		}
+		mode |= digraph.Transactions
+	}
+	if induced {
+		if extendFromEdges {
+			fmt.Fprintf(os.Stderr, "Cannot use --induced with --extend-from-freq-edges\n")
+			Usage(ErrorCodes["opts"])
+		} else if approximate || temporal {
+			fmt.Fprintf(os.Stderr, "Cannot use --induced with --approximate or --temporal\n")
+			Usage(ErrorCodes["opts"])
+		}
+		mode |= digraph.Induced
+	}
+	if mode&digraph.Weighted != 0 && weightAttr == "" {
+		fmt.Fprintf(os.Stderr, "The weighted support modes need a --weight attribute\n")
+		Usage(ErrorCodes["opts"])
+	}
+	if mode&(digraph.WeightedSum|digraph.WeightedMin) != 0 && extensionPruning {
+		fmt.Fprintf(os.Stderr, "Cannot use --extension-pruning with WSUM or WMIN (they are not anti-monotone)\n")
+		Usage(ErrorCodes["opts"])
+	}
+	if homomorphism {
+		if induced {
+			fmt.Fprintf(os.Stderr, "Cannot use --homomorphism with --induced\n")
+			Usage(ErrorCodes["opts"])
+		}
+		mode |= digraph.Homomorphism
+	}
+	if approximate {
+		if mode&digraph.MNI == 0 {
+			fmt.Fprintf(os.Stderr, "--approximate only works with the MNI support mode\n")
+			Usage(ErrorCodes["opts"])
+		}
+		if approxSamples <= 0 {
+			fmt.Fprintf(os.Stderr, "--approx-samples must be positive\n")
+			Usage(ErrorCodes["opts"])
+		}
+		if approxConfidence <= 0 || approxConfidence >= 1 {
+			fmt.Fprintf(os.Stderr, "--approx-confidence must be between 0 and 1\n")
+			Usage(ErrorCodes["opts"])
+		}
+		mode |= digraph.Approximate
+	}
+	if temporal {
+		if extensionPruning {
+			fmt.Fprintf(os.Stderr, "Cannot use --temporal with --extension-pruning\n")
+			Usage(ErrorCodes["opts"])
+		}
+		mode |= digraph.Temporal
+	}
+	if labelSets {
+		mode |= digraph.LabelSets
+	}
+	if timeWindow != 0 && !temporal {
+		fmt.Fprintf(os.Stderr, "--time-window needs --temporal\n")
+		Usage(ErrorCodes["opts"])
+	} else if timeWindow < 0 {
+		fmt.Fprintf(os.Stderr, "--time-window must not be negative\n")
+		Usage(ErrorCodes["opts"])
+	}
 
 	var include *regexp.Regexp = nil
 	var exclude *regexp.Regexp = nil
@@ -859,6 +1561,14 @@ func digraphType(argv []string, conf *config.Config) (lattice.Loader, func(latti
 		Include:             include,
 		Exclude:             exclude,
 		EmbSearchStartPoint: embSearchStartingPoint,
+		ApproxSamples:       approxSamples,
+		ApproxConfidence:    approxConfidence,
+		MaxEmbeddings:       maxEmbs,
+		Shape:               shape,
+		MaxInDegree:         maxInDeg,
+		MaxOutDegree:        maxOutDeg,
+		WeightAttr:          weightAttr,
+		TimeWindow:          int64(timeWindow),
 	}
 
 	var loader lattice.Loader
@@ -1013,6 +1723,52 @@ func dirReporter(rptrs map[string]Reporter, argv []string, fmtr lattice.Formatte
 	return fr, args
 }
 
+func htmlReporter(rptrs map[string]Reporter, argv []string, fmtr lattice.Formatter, conf *config.Config) (miners.Reporter, []string) {
+	args, optargs, err := getopt.GetOpt(
+		argv,
+		"hd:",
+		[]string{
+			"help",
+			"dir-name=",
+			"show-pr",
+			"page-size=",
+		},
+	)
+	if err != nil {
+		errors.Logf("ERROR", "%v", err)
+		Usage(ErrorCodes["opts"])
+	}
+	dir := "html"
+	showPr := false
+	pageSize := 50
+	for _, oa := range optargs {
+		switch oa.Opt() {
+		case "-h", "--help":
+			Usage(0)
+		case "-d", "--dir-name":
+			dir = oa.Arg()
+		case "--show-pr":
+			showPr = true
+		case "--page-size":
+			pageSize = ParseInt(oa.Arg())
+			if pageSize <= 0 {
+				fmt.Fprintf(os.Stderr, "--page-size must be > 0\n")
+				Usage(ErrorCodes["opts"])
+			}
+		default:
+			errors.Logf("ERROR", "Unknown flag '%v'\n", oa.Opt())
+			Usage(ErrorCodes["opts"])
+		}
+	}
+	fr, err := reporters.NewHTML(conf, fmtr, showPr, dir, pageSize)
+	if err != nil {
+		errors.Logf("ERROR", "There was error creating output files\n")
+		errors.Logf("ERROR", "%v", err)
+		os.Exit(1)
+	}
+	return fr, args
+}
+
 func countReporter(rptrs map[string]Reporter, argv []string, fmtr lattice.Formatter, conf *config.Config) (miners.Reporter, []string) {
 	args, optargs, err := getopt.GetOpt(
 		argv,
@@ -1094,90 +1850,308 @@ func chainReporter(reports map[string]Reporter, argv []string, fmtr lattice.Form
 	return &reporters.Chain{rptrs}, args
 }
 
-func uniqueReporter(reports map[string]Reporter, argv []string, fmtr lattice.Formatter, conf *config.Config) (miners.Reporter, []string) {
+func rulesReporter(rptrs map[string]Reporter, argv []string, fmtr lattice.Formatter, conf *config.Config) (miners.Reporter, []string) {
 	args, optargs, err := getopt.GetOpt(
 		argv,
-		"h",
+		"hf:",
 		[]string{
 			"help",
-			"histogram=",
+			"filename=",
+			"format=",
+			"min-confidence=",
+			"min-lift=",
 		},
 	)
 	if err != nil {
 		errors.Logf("ERROR", "%v", err)
 		Usage(ErrorCodes["opts"])
 	}
-	histogram := ""
+	if _, is := fmtr.(*itemset.Formatter); !is {
+		errors.Logf("ERROR", "The rules reporter only works with the itemset type")
+		Usage(ErrorCodes["opts"])
+	}
+	filename := "rules"
+	format := "csv"
+	minConf := 0.0
+	minLift := 0.0
 	for _, oa := range optargs {
 		switch oa.Opt() {
 		case "-h", "--help":
 			Usage(0)
-		case "--histogram":
-			histogram = oa.Arg()
+		case "-f", "--filename":
+			filename = oa.Arg()
+		case "--format":
+			format = oa.Arg()
+		case "--min-confidence":
+			minConf = ParseFloat(oa.Arg())
+		case "--min-lift":
+			minLift = ParseFloat(oa.Arg())
 		default:
-			errors.Logf("ERROR", "Unknown flag '%v'", oa.Opt())
+			errors.Logf("ERROR", "Unknown flag '%v'\n", oa.Opt())
 			Usage(ErrorCodes["opts"])
 		}
 	}
-	var rptr miners.Reporter
-	if len(args) == 0 {
-		errors.Logf("ERROR", "You must supply an inner reporter to unique")
-		fmt.Fprintln(os.Stderr, "try: unique file")
-		Usage(ErrorCodes["opts"])
-	} else if _, has := reports[args[0]]; !has {
-		errors.Logf("ERROR", "Unknown reporter '%v'", args[0])
-		fmt.Fprintln(os.Stderr, "Reporters:")
-		for k := range reports {
-			fmt.Fprintln(os.Stderr, "  ", k)
-		}
-		Usage(ErrorCodes["opts"])
-	} else {
-		rptr, args = reports[args[0]](reports, args[1:], fmtr, conf)
-	}
-	uniq, err := reporters.NewUnique(conf, fmtr, rptr, histogram)
+	r, err := reporters.NewRules(conf, filename, format, minConf, minLift)
 	if err != nil {
-		errors.Logf("ERROR", "Error creating unique reporter '%v'\n", err)
-		Usage(ErrorCodes["opts"])
+		errors.Logf("ERROR", "There was error creating output files\n")
+		errors.Logf("ERROR", "%v", err)
+		os.Exit(1)
 	}
-	return uniq, args
+	return r, args
 }
 
-func maxReporter(reports map[string]Reporter, argv []string, fmtr lattice.Formatter, conf *config.Config) (miners.Reporter, []string) {
+func latticeReporter(rptrs map[string]Reporter, argv []string, fmtr lattice.Formatter, conf *config.Config) (miners.Reporter, []string) {
 	args, optargs, err := getopt.GetOpt(
 		argv,
-		"h",
+		"hf:",
 		[]string{
 			"help",
+			"filename=",
+			"format=",
 		},
 	)
 	if err != nil {
 		errors.Logf("ERROR", "%v", err)
 		Usage(ErrorCodes["opts"])
 	}
+	filename := "lattice"
+	format := "dot"
 	for _, oa := range optargs {
 		switch oa.Opt() {
 		case "-h", "--help":
 			Usage(0)
+		case "-f", "--filename":
+			filename = oa.Arg()
+		case "--format":
+			format = oa.Arg()
 		default:
-			fmt.Fprintf(os.Stderr, "Unknown flag '%v'\n", oa.Opt())
+			errors.Logf("ERROR", "Unknown flag '%v'\n", oa.Opt())
 			Usage(ErrorCodes["opts"])
 		}
 	}
-	var rptr miners.Reporter
-	if len(args) == 0 {
-		fmt.Fprintln(os.Stderr, "You must supply an inner reporter to max")
-		fmt.Fprintln(os.Stderr, "try: unique file")
-		Usage(ErrorCodes["opts"])
-	} else if _, has := reports[args[0]]; !has {
-		fmt.Fprintf(os.Stderr, "Unknown reporter '%v'\n", args[0])
-		fmt.Fprintln(os.Stderr, "Reporters:")
-		for k := range reports {
-			fmt.Fprintln(os.Stderr, "  ", k)
-		}
-		Usage(ErrorCodes["opts"])
-	} else {
-		rptr, args = reports[args[0]](reports, args[1:], fmtr, conf)
-	}
+	r, err := reporters.NewLatticeExport(conf, fmtr, filename, format)
+	if err != nil {
+		errors.Logf("ERROR", "There was error creating output files\n")
+		errors.Logf("ERROR", "%v", err)
+		os.Exit(1)
+	}
+	return r, args
+}
+
+func coverageReporter(rptrs map[string]Reporter, argv []string, fmtr lattice.Formatter, conf *config.Config) (miners.Reporter, []string) {
+	args, optargs, err := getopt.GetOpt(
+		argv,
+		"hf:",
+		[]string{
+			"help",
+			"filename=",
+		},
+	)
+	if err != nil {
+		errors.Logf("ERROR", "%v", err)
+		Usage(ErrorCodes["opts"])
+	}
+	if _, is := fmtr.(*digraph.Formatter); !is {
+		errors.Logf("ERROR", "The coverage reporter only works with the digraph type")
+		Usage(ErrorCodes["opts"])
+	}
+	filename := "coverage"
+	for _, oa := range optargs {
+		switch oa.Opt() {
+		case "-h", "--help":
+			Usage(0)
+		case "-f", "--filename":
+			filename = oa.Arg()
+		default:
+			errors.Logf("ERROR", "Unknown flag '%v'\n", oa.Opt())
+			Usage(ErrorCodes["opts"])
+		}
+	}
+	r, err := reporters.NewCoverage(conf, filename)
+	if err != nil {
+		errors.Logf("ERROR", "There was error creating output files\n")
+		errors.Logf("ERROR", "%v", err)
+		os.Exit(1)
+	}
+	return r, args
+}
+
+func compressReporter(rptrs map[string]Reporter, argv []string, fmtr lattice.Formatter, conf *config.Config) (miners.Reporter, []string) {
+	args, optargs, err := getopt.GetOpt(
+		argv,
+		"hf:",
+		[]string{
+			"help",
+			"filename=",
+			"max-patterns=",
+		},
+	)
+	if err != nil {
+		errors.Logf("ERROR", "%v", err)
+		Usage(ErrorCodes["opts"])
+	}
+	if _, is := fmtr.(*digraph.Formatter); !is {
+		errors.Logf("ERROR", "The compress reporter only works with the digraph type")
+		Usage(ErrorCodes["opts"])
+	}
+	filename := "compress"
+	max := 0
+	for _, oa := range optargs {
+		switch oa.Opt() {
+		case "-h", "--help":
+			Usage(0)
+		case "-f", "--filename":
+			filename = oa.Arg()
+		case "--max-patterns":
+			max = ParseInt(oa.Arg())
+		default:
+			errors.Logf("ERROR", "Unknown flag '%v'\n", oa.Opt())
+			Usage(ErrorCodes["opts"])
+		}
+	}
+	r, err := reporters.NewCompress(conf, fmtr, filename, max)
+	if err != nil {
+		errors.Logf("ERROR", "There was error creating output files\n")
+		errors.Logf("ERROR", "%v", err)
+		os.Exit(1)
+	}
+	return r, args
+}
+
+func occurrencesReporter(rptrs map[string]Reporter, argv []string, fmtr lattice.Formatter, conf *config.Config) (miners.Reporter, []string) {
+	args, optargs, err := getopt.GetOpt(
+		argv,
+		"hf:a:",
+		[]string{
+			"help",
+			"filename=",
+			"format=",
+			"attrs=",
+		},
+	)
+	if err != nil {
+		errors.Logf("ERROR", "%v", err)
+		Usage(ErrorCodes["opts"])
+	}
+	if _, is := fmtr.(*digraph.Formatter); !is {
+		errors.Logf("ERROR", "The occurrences reporter only works with the digraph type")
+		Usage(ErrorCodes["opts"])
+	}
+	filename := "occurrences"
+	format := "csv"
+	attrs := make([]string, 0, 5)
+	for _, oa := range optargs {
+		switch oa.Opt() {
+		case "-h", "--help":
+			Usage(0)
+		case "-f", "--filename":
+			filename = oa.Arg()
+		case "--format":
+			format = oa.Arg()
+		case "-a", "--attrs":
+			for _, a := range strings.Split(oa.Arg(), ",") {
+				if a = strings.TrimSpace(a); a != "" {
+					attrs = append(attrs, a)
+				}
+			}
+		default:
+			errors.Logf("ERROR", "Unknown flag '%v'\n", oa.Opt())
+			Usage(ErrorCodes["opts"])
+		}
+	}
+	r, err := reporters.NewOccurrences(conf, fmtr, filename, format, attrs)
+	if err != nil {
+		errors.Logf("ERROR", "There was error creating output files\n")
+		errors.Logf("ERROR", "%v", err)
+		os.Exit(1)
+	}
+	return r, args
+}
+
+func uniqueReporter(reports map[string]Reporter, argv []string, fmtr lattice.Formatter, conf *config.Config) (miners.Reporter, []string) {
+	args, optargs, err := getopt.GetOpt(
+		argv,
+		"h",
+		[]string{
+			"help",
+			"histogram=",
+		},
+	)
+	if err != nil {
+		errors.Logf("ERROR", "%v", err)
+		Usage(ErrorCodes["opts"])
+	}
+	histogram := ""
+	for _, oa := range optargs {
+		switch oa.Opt() {
+		case "-h", "--help":
+			Usage(0)
+		case "--histogram":
+			histogram = oa.Arg()
+		default:
+			errors.Logf("ERROR", "Unknown flag '%v'", oa.Opt())
+			Usage(ErrorCodes["opts"])
+		}
+	}
+	var rptr miners.Reporter
+	if len(args) == 0 {
+		errors.Logf("ERROR", "You must supply an inner reporter to unique")
+		fmt.Fprintln(os.Stderr, "try: unique file")
+		Usage(ErrorCodes["opts"])
+	} else if _, has := reports[args[0]]; !has {
+		errors.Logf("ERROR", "Unknown reporter '%v'", args[0])
+		fmt.Fprintln(os.Stderr, "Reporters:")
+		for k := range reports {
+			fmt.Fprintln(os.Stderr, "  ", k)
+		}
+		Usage(ErrorCodes["opts"])
+	} else {
+		rptr, args = reports[args[0]](reports, args[1:], fmtr, conf)
+	}
+	uniq, err := reporters.NewUnique(conf, fmtr, rptr, histogram)
+	if err != nil {
+		errors.Logf("ERROR", "Error creating unique reporter '%v'\n", err)
+		Usage(ErrorCodes["opts"])
+	}
+	return uniq, args
+}
+
+func maxReporter(reports map[string]Reporter, argv []string, fmtr lattice.Formatter, conf *config.Config) (miners.Reporter, []string) {
+	args, optargs, err := getopt.GetOpt(
+		argv,
+		"h",
+		[]string{
+			"help",
+		},
+	)
+	if err != nil {
+		errors.Logf("ERROR", "%v", err)
+		Usage(ErrorCodes["opts"])
+	}
+	for _, oa := range optargs {
+		switch oa.Opt() {
+		case "-h", "--help":
+			Usage(0)
+		default:
+			fmt.Fprintf(os.Stderr, "Unknown flag '%v'\n", oa.Opt())
+			Usage(ErrorCodes["opts"])
+		}
+	}
+	var rptr miners.Reporter
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "You must supply an inner reporter to max")
+		fmt.Fprintln(os.Stderr, "try: unique file")
+		Usage(ErrorCodes["opts"])
+	} else if _, has := reports[args[0]]; !has {
+		fmt.Fprintf(os.Stderr, "Unknown reporter '%v'\n", args[0])
+		fmt.Fprintln(os.Stderr, "Reporters:")
+		for k := range reports {
+			fmt.Fprintln(os.Stderr, "  ", k)
+		}
+		Usage(ErrorCodes["opts"])
+	} else {
+		rptr, args = reports[args[0]](reports, args[1:], fmtr, conf)
+	}
 	m, err := reporters.NewMax(rptr)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating max reporter '%v'\n", err)
@@ -1274,6 +2248,118 @@ func skipReporter(reports map[string]Reporter, argv []string, fmtr lattice.Forma
 	return r, args
 }
 
+func filterReporter(reports map[string]Reporter, argv []string, fmtr lattice.Formatter, conf *config.Config) (miners.Reporter, []string) {
+	args, optargs, err := getopt.GetOpt(
+		argv,
+		"he:",
+		[]string{
+			"help",
+			"expr=",
+		},
+	)
+	if err != nil {
+		errors.Logf("ERROR", "%v", err)
+		Usage(ErrorCodes["opts"])
+	}
+	expr := ""
+	for _, oa := range optargs {
+		switch oa.Opt() {
+		case "-h", "--help":
+			Usage(0)
+		case "-e", "--expr":
+			expr = oa.Arg()
+		default:
+			fmt.Fprintf(os.Stderr, "Unknown flag '%v'\n", oa.Opt())
+			Usage(ErrorCodes["opts"])
+		}
+	}
+	if expr == "" {
+		fmt.Fprintln(os.Stderr, "You must supply a filter expression (-e)")
+		Usage(ErrorCodes["opts"])
+	}
+	var rptr miners.Reporter
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "You must supply an inner reporter to filter")
+		fmt.Fprintln(os.Stderr, "try: filter -e 'support >= 10' log")
+		Usage(ErrorCodes["opts"])
+	} else if _, has := reports[args[0]]; !has {
+		fmt.Fprintf(os.Stderr, "Unknown reporter '%v'\n", args[0])
+		fmt.Fprintln(os.Stderr, "Reporters:")
+		for k := range reports {
+			fmt.Fprintln(os.Stderr, "  ", k)
+		}
+		Usage(ErrorCodes["opts"])
+	} else {
+		rptr, args = reports[args[0]](reports, args[1:], fmtr, conf)
+	}
+	r, err := reporters.NewFilter(expr, rptr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Bad filter expression: %v\n", err)
+		Usage(ErrorCodes["opts"])
+	}
+	return r, args
+}
+
+func topReporter(reports map[string]Reporter, argv []string, fmtr lattice.Formatter, conf *config.Config) (miners.Reporter, []string) {
+	args, optargs, err := getopt.GetOpt(
+		argv,
+		"hn:",
+		[]string{
+			"help",
+			"count=",
+			"by=",
+			"in-memory=",
+		},
+	)
+	if err != nil {
+		errors.Logf("ERROR", "%v", err)
+		Usage(ErrorCodes["opts"])
+	}
+	count := 10
+	by := "support"
+	inMemory := 1000
+	for _, oa := range optargs {
+		switch oa.Opt() {
+		case "-h", "--help":
+			Usage(0)
+		case "-n", "--count":
+			count = ParseInt(oa.Arg())
+			if count <= 0 {
+				fmt.Fprintf(os.Stderr, "--count must be > 0\n")
+				Usage(ErrorCodes["opts"])
+			}
+		case "--by":
+			by = oa.Arg()
+		case "--in-memory":
+			inMemory = ParseInt(oa.Arg())
+		default:
+			fmt.Fprintf(os.Stderr, "Unknown flag '%v'\n", oa.Opt())
+			Usage(ErrorCodes["opts"])
+		}
+	}
+	var rptr miners.Reporter
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "You must supply an inner reporter to top")
+		fmt.Fprintln(os.Stderr, "try: top -n 10 --by vertices log")
+		Usage(ErrorCodes["opts"])
+	} else if _, has := reports[args[0]]; !has {
+		fmt.Fprintf(os.Stderr, "Unknown reporter '%v'\n", args[0])
+		fmt.Fprintln(os.Stderr, "Reporters:")
+		for k := range reports {
+			fmt.Fprintln(os.Stderr, "  ", k)
+		}
+		Usage(ErrorCodes["opts"])
+	} else {
+		rptr, args = reports[args[0]](reports, args[1:], fmtr, conf)
+	}
+	r, err := reporters.NewTop(conf, count, by, inMemory, rptr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Bad top options: %v\n", err)
+		Usage(ErrorCodes["opts"])
+	}
+	return r, args
+}
+
 func dbscanReporter(rptrs map[string]Reporter, argv []string, fmtr lattice.Formatter, conf *config.Config) (miners.Reporter, []string) {
 	args, optargs, err := getopt.GetOpt(
 		argv,
@@ -1327,6 +2413,128 @@ func dbscanReporter(rptrs map[string]Reporter, argv []string, fmtr lattice.Forma
 	return r, args
 }
 
+func hierarchicalReporter(rptrs map[string]Reporter, argv []string, fmtr lattice.Formatter, conf *config.Config) (miners.Reporter, []string) {
+	args, optargs, err := getopt.GetOpt(
+		argv,
+		"hc:m:d:a:k:",
+		[]string{
+			"help",
+			"clusters-name=",
+			"metrics-name=",
+			"dendrogram-name=",
+			"metric=",
+			"attr=",
+			"linkage=",
+			"clusters=",
+			"max-distance=",
+		},
+	)
+	if err != nil {
+		errors.Logf("ERROR", "%v", err)
+		Usage(ErrorCodes["opts"])
+	}
+	if _, is := fmtr.(*digraph.Formatter); !is {
+		errors.Logf("ERROR", "The hierarchical reporter only works with the digraph type")
+		Usage(ErrorCodes["opts"])
+	}
+	clusters := "clusters"
+	metrics := "metrics"
+	dendrogram := "dendrogram"
+	metric := "structure"
+	attr := ""
+	linkage := "average"
+	k := 0
+	maxDistance := 0.5
+	for _, oa := range optargs {
+		switch oa.Opt() {
+		case "-h", "--help":
+			Usage(0)
+		case "-c", "--clusters-name":
+			clusters = oa.Arg()
+		case "-m", "--metrics-name":
+			metrics = oa.Arg()
+		case "-d", "--dendrogram-name":
+			dendrogram = oa.Arg()
+		case "--metric":
+			metric = oa.Arg()
+		case "-a", "--attr":
+			attr = oa.Arg()
+		case "--linkage":
+			linkage = oa.Arg()
+		case "-k", "--clusters":
+			k = ParseInt(oa.Arg())
+		case "--max-distance":
+			maxDistance = ParseFloat(oa.Arg())
+		default:
+			errors.Logf("ERROR", "Unknown flag '%v'\n", oa.Opt())
+			Usage(ErrorCodes["opts"])
+		}
+	}
+	r, err := reporters.NewHierarchical(conf, fmtr, clusters, metrics, dendrogram, attr, metric, linkage, k, maxDistance)
+	if err != nil {
+		errors.Logf("ERROR", "%v", err)
+		Usage(ErrorCodes["opts"])
+	}
+	return r, args
+}
+
+func kmedoidsReporter(rptrs map[string]Reporter, argv []string, fmtr lattice.Formatter, conf *config.Config) (miners.Reporter, []string) {
+	args, optargs, err := getopt.GetOpt(
+		argv,
+		"hc:m:a:k:",
+		[]string{
+			"help",
+			"clusters-name=",
+			"metrics-name=",
+			"metric=",
+			"attr=",
+			"clusters=",
+			"max-iterations=",
+		},
+	)
+	if err != nil {
+		errors.Logf("ERROR", "%v", err)
+		Usage(ErrorCodes["opts"])
+	}
+	if _, is := fmtr.(*digraph.Formatter); !is {
+		errors.Logf("ERROR", "The k-medoids reporter only works with the digraph type")
+		Usage(ErrorCodes["opts"])
+	}
+	clusters := "clusters"
+	metrics := "metrics"
+	metric := "structure"
+	attr := ""
+	k := 5
+	maxIterations := 100
+	for _, oa := range optargs {
+		switch oa.Opt() {
+		case "-h", "--help":
+			Usage(0)
+		case "-c", "--clusters-name":
+			clusters = oa.Arg()
+		case "-m", "--metrics-name":
+			metrics = oa.Arg()
+		case "--metric":
+			metric = oa.Arg()
+		case "-a", "--attr":
+			attr = oa.Arg()
+		case "-k", "--clusters":
+			k = ParseInt(oa.Arg())
+		case "--max-iterations":
+			maxIterations = ParseInt(oa.Arg())
+		default:
+			errors.Logf("ERROR", "Unknown flag '%v'\n", oa.Opt())
+			Usage(ErrorCodes["opts"])
+		}
+	}
+	r, err := reporters.NewKMedoids(conf, fmtr, clusters, metrics, attr, metric, k, maxIterations)
+	if err != nil {
+		errors.Logf("ERROR", "%v", err)
+		Usage(ErrorCodes["opts"])
+	}
+	return r, args
+}
+
 func heapProfileReporter(rptrs map[string]Reporter, argv []string, fmtr lattice.Formatter, conf *config.Config) (miners.Reporter, []string) {
 	args, optargs, err := getopt.GetOpt(
 		argv,
@@ -1374,22 +2582,34 @@ func heapProfileReporter(rptrs map[string]Reporter, argv []string, fmtr lattice.
 }
 
 var Types map[string]Type = map[string]Type{
-	"itemset": itemsetType,
-	"digraph": digraphType,
+	"itemset":  itemsetType,
+	"sequence": sequenceType,
+	"tree":     treeType,
+	"digraph":  digraphType,
 }
 
 var Reporters map[string]Reporter = map[string]Reporter{
 	"log":          logReporter,
 	"file":         fileReporter,
 	"dir":          dirReporter,
+	"html":         htmlReporter,
 	"count":        countReporter,
 	"chain":        chainReporter,
 	"unique":       uniqueReporter,
 	"max":          maxReporter,
 	"canon-max":    canonMaxReporter,
 	"skip":         skipReporter,
+	"filter":       filterReporter,
+	"top":          topReporter,
 	"dbscan":       dbscanReporter,
+	"hierarchical": hierarchicalReporter,
+	"k-medoids":    kmedoidsReporter,
 	"heap-profile": heapProfileReporter,
+	"rules":        rulesReporter,
+	"lattice":      latticeReporter,
+	"coverage":     coverageReporter,
+	"occurrences":  occurrencesReporter,
+	"compress":     compressReporter,
 }
 
 type Mode func(argv []string, conf *config.Config) (miners.Miner, []string)
@@ -1419,7 +2639,18 @@ func ParseType(args []string, conf *config.Config) (func(lattice.PrFormatter) (l
 	}
 	f := func(prfmtr lattice.PrFormatter) (lattice.DataType, lattice.Formatter) {
 		errors.Logf("INFO", "Got configuration about to load dataset")
-		dt, err := loader.Load(getInput)
+		var dt lattice.DataType
+		dir, err := isDir(inputPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Could not read the input path '%v'\n", inputPath)
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		if dl, ok := loader.(lattice.DirLoader); ok && dir {
+			dt, err = dl.LoadDir(InputFiles(inputPath))
+		} else {
+			dt, err = loader.Load(getInput)
+		}
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "There was error during the loading process\n")
 			fmt.Fprintf(os.Stderr, "%v\n", err)
@@ -1499,5 +2730,43 @@ func Main(args []string, conf *config.Config, modes map[string]Mode) int {
 		Usage(ErrorCodes["opts"])
 	}
 
-	return Run(dt, fmtr, mode, rptr)
+	if conf.Serve != "" {
+		srv, err := reporters.NewServer(conf.Serve, fmtr, rptr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Could not serve at %v: %v\n", conf.Serve, err)
+			Usage(ErrorCodes["opts"])
+		}
+		rptr = srv
+	}
+
+	code := Run(dt, fmtr, mode, rptr)
+	code += WriteMetrics(conf)
+	return code
+}
+
+// WriteMetrics logs the metrics summary and writes the prometheus metrics
+// file if --metrics was given (otherwise it does nothing).
+func WriteMetrics(conf *config.Config) int {
+	if conf.Metrics == "" {
+		return 0
+	}
+	fmt.Fprintln(os.Stderr, "metrics:")
+	if err := metrics.WriteSummary(os.Stderr); err != nil {
+		errors.Logf("ERROR", "error writing the metrics summary %v", err)
+		return 1
+	}
+	f, err := os.Create(conf.OutputFile(conf.Metrics))
+	if err != nil {
+		errors.Logf("ERROR", "error creating the metrics file %v", err)
+		return 1
+	}
+	err = metrics.WritePrometheus(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		errors.Logf("ERROR", "error writing the metrics file %v", err)
+		return 1
+	}
+	return 0
 }
@@ -49,6 +49,8 @@ func init() {
 	cmd.UsageMessage = "find-embeddings --help"
 	cmd.ExtendedMessage = `
 find-embeddings -p <pattern> <graph>
+
+pass --induced to the digraph type to only match induced embeddings
 `
 }
 
@@ -235,7 +237,7 @@ func run() int {
 				fmt.Fprintf(os.Stderr, "%v\n", err)
 				return 1
 			}
-			match, csg, err := sg.EstimateMatch(graph.Indices)
+			match, csg, err := sg.EstimateMatch(graph.Indices, graph.Mode&digraph.Induced == digraph.Induced)
 			match = match * float64(len(sg.E))
 			if err != nil {
 				errors.Logf("ERROR", "%v", err)
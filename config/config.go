@@ -32,6 +32,8 @@ type Config struct {
 	Support, Samples int
 	Unique           bool
 	Parallelism      int
+	Serve            string // address of the progress http server ("" is off)
+	Metrics          string // name of the prometheus metrics file ("" is off and the stores are not timed)
 	AsyncTasks       sync.WaitGroup
 }
 
@@ -42,6 +44,8 @@ func (c *Config) Copy() *Config {
 		Support: c.Support,
 		Samples: c.Samples,
 		Unique:  c.Unique,
+		Serve:   c.Serve,
+		Metrics: c.Metrics,
 	}
 }
 
@@ -72,11 +76,17 @@ func (c *Config) OutputFile(name string) string {
 }
 
 func (c *Config) MultiMap(name string) (bytes_bytes.MultiMap, error) {
+	var m bytes_bytes.MultiMap
+	var err error
 	if c.Cache == "" {
-		return bytes_bytes.AnonBpTree()
+		m, err = bytes_bytes.AnonBpTree()
 	} else {
-		return bytes_bytes.NewBpTree(c.CacheFile(name + "-" + c.Randstr() + ".bptree"))
+		m, err = bytes_bytes.NewBpTree(c.CacheFile(name + "-" + c.Randstr() + ".bptree"))
 	}
+	if err != nil || c.Metrics == "" {
+		return m, err
+	}
+	return bytes_bytes.NewTimed(m), nil
 }
 
 func (c *Config) SubgraphList(
@@ -94,81 +104,141 @@ func (c *Config) BytesSubgraphMultiMap(
 	name string,
 	deserializeValue func([]byte) *goiso.SubGraph,
 ) (bytes_subgraph.MultiMap, error) {
+	var m bytes_subgraph.MultiMap
+	var err error
 	if c.Cache == "" {
-		return bytes_subgraph.AnonBpTree(bytes_subgraph.Identity, bytes_subgraph.SerializeSubGraph, bytes_subgraph.Identity, deserializeValue)
+		m, err = bytes_subgraph.AnonBpTree(bytes_subgraph.Identity, bytes_subgraph.SerializeSubGraph, bytes_subgraph.Identity, deserializeValue)
 	} else {
-		return bytes_subgraph.NewBpTree(c.CacheFile(name+"-"+c.Randstr()+".bptree"), bytes_subgraph.Identity, bytes_subgraph.SerializeSubGraph, bytes_subgraph.Identity, deserializeValue)
+		m, err = bytes_subgraph.NewBpTree(c.CacheFile(name+"-"+c.Randstr()+".bptree"), bytes_subgraph.Identity, bytes_subgraph.SerializeSubGraph, bytes_subgraph.Identity, deserializeValue)
+	}
+	if err != nil || c.Metrics == "" {
+		return m, err
 	}
+	return bytes_subgraph.NewTimed(m), nil
 }
 
 func (c *Config) BytesExtensionMultiMap(name string) (bytes_extension.MultiMap, error) {
+	var m bytes_extension.MultiMap
+	var err error
 	if c.Cache == "" {
-		return bytes_extension.AnonBpTree()
+		m, err = bytes_extension.AnonBpTree()
 	} else {
-		return bytes_extension.NewBpTree(c.CacheFile(name + "-" + c.Randstr() + ".bptree"))
+		m, err = bytes_extension.NewBpTree(c.CacheFile(name + "-" + c.Randstr() + ".bptree"))
+	}
+	if err != nil || c.Metrics == "" {
+		return m, err
 	}
+	return bytes_extension.NewTimed(m), nil
 }
 
 func (c *Config) BytesFloatMultiMap(name string) (bytes_float.MultiMap, error) {
+	var m bytes_float.MultiMap
+	var err error
 	if c.Cache == "" {
-		return bytes_float.AnonBpTree()
+		m, err = bytes_float.AnonBpTree()
 	} else {
-		return bytes_float.NewBpTree(c.CacheFile(name + "-" + c.Randstr() + ".bptree"))
+		m, err = bytes_float.NewBpTree(c.CacheFile(name + "-" + c.Randstr() + ".bptree"))
 	}
+	if err != nil || c.Metrics == "" {
+		return m, err
+	}
+	return bytes_float.NewTimed(m), nil
 }
 
 func (c *Config) BytesIntMultiMap(name string) (bytes_int.MultiMap, error) {
+	var m bytes_int.MultiMap
+	var err error
 	if c.Cache == "" {
-		return bytes_int.AnonBpTree()
+		m, err = bytes_int.AnonBpTree()
 	} else {
-		return bytes_int.NewBpTree(c.CacheFile(name + "-" + c.Randstr() + ".bptree"))
+		m, err = bytes_int.NewBpTree(c.CacheFile(name + "-" + c.Randstr() + ".bptree"))
+	}
+	if err != nil || c.Metrics == "" {
+		return m, err
 	}
+	return bytes_int.NewTimed(m), nil
 }
 
 func (c *Config) IntIntMultiMap(name string) (int_int.MultiMap, error) {
+	var m int_int.MultiMap
+	var err error
 	if c.Cache == "" {
-		return int_int.AnonBpTree()
+		m, err = int_int.AnonBpTree()
 	} else {
-		return int_int.NewBpTree(c.CacheFile(name + "-" + c.Randstr() + ".bptree"))
+		m, err = int_int.NewBpTree(c.CacheFile(name + "-" + c.Randstr() + ".bptree"))
+	}
+	if err != nil || c.Metrics == "" {
+		return m, err
 	}
+	return int_int.NewTimed(m), nil
 }
 
 func (c *Config) IntJsonMultiMap(name string) (int_json.MultiMap, error) {
+	var m int_json.MultiMap
+	var err error
 	if c.Cache == "" {
-		return int_json.AnonBpTree()
+		m, err = int_json.AnonBpTree()
 	} else {
-		return int_json.NewBpTree(c.CacheFile(name + "-" + c.Randstr() + ".bptree"))
+		m, err = int_json.NewBpTree(c.CacheFile(name + "-" + c.Randstr() + ".bptree"))
 	}
+	if err != nil || c.Metrics == "" {
+		return m, err
+	}
+	return int_json.NewTimed(m), nil
 }
 
 func (c *Config) IntsIntMultiMap(name string) (ints_int.MultiMap, error) {
+	var m ints_int.MultiMap
+	var err error
 	if c.Cache == "" {
-		return ints_int.AnonBpTree()
+		m, err = ints_int.AnonBpTree()
 	} else {
-		return ints_int.NewBpTree(c.CacheFile(name + "-" + c.Randstr() + ".bptree"))
+		m, err = ints_int.NewBpTree(c.CacheFile(name + "-" + c.Randstr() + ".bptree"))
+	}
+	if err != nil || c.Metrics == "" {
+		return m, err
 	}
+	return ints_int.NewTimed(m), nil
 }
 
 func (c *Config) IntsIntsMultiMap(name string) (ints_ints.MultiMap, error) {
+	var m ints_ints.MultiMap
+	var err error
 	if c.Cache == "" {
-		return ints_ints.AnonBpTree()
+		m, err = ints_ints.AnonBpTree()
 	} else {
-		return ints_ints.NewBpTree(c.CacheFile(name + "-" + c.Randstr() + ".bptree"))
+		m, err = ints_ints.NewBpTree(c.CacheFile(name + "-" + c.Randstr() + ".bptree"))
+	}
+	if err != nil || c.Metrics == "" {
+		return m, err
 	}
+	return ints_ints.NewTimed(m), nil
 }
 
 func (c *Config) SubgraphEmbeddingMultiMap(name string) (subgraph_embedding.MultiMap, error) {
+	var m subgraph_embedding.MultiMap
+	var err error
 	if c.Cache == "" {
-		return subgraph_embedding.AnonBpTree()
+		m, err = subgraph_embedding.AnonBpTree()
 	} else {
-		return subgraph_embedding.NewBpTree(c.CacheFile(name + "-" + c.Randstr() + ".bptree"))
+		m, err = subgraph_embedding.NewBpTree(c.CacheFile(name + "-" + c.Randstr() + ".bptree"))
 	}
+	if err != nil || c.Metrics == "" {
+		return m, err
+	}
+	return subgraph_embedding.NewTimed(m), nil
 }
 
 func (c *Config) SubgraphOverlapMultiMap(name string) (subgraph_overlap.MultiMap, error) {
+	var m subgraph_overlap.MultiMap
+	var err error
 	if c.Cache == "" {
-		return subgraph_overlap.AnonBpTree()
+		m, err = subgraph_overlap.AnonBpTree()
 	} else {
-		return subgraph_overlap.NewBpTree(c.CacheFile(name + "-" + c.Randstr() + ".bptree"))
+		m, err = subgraph_overlap.NewBpTree(c.CacheFile(name + "-" + c.Randstr() + ".bptree"))
+	}
+	if err != nil || c.Metrics == "" {
+		return m, err
 	}
+	return subgraph_overlap.NewTimed(m), nil
 }
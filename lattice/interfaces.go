@@ -16,10 +16,22 @@ type Lattice struct {
 
 type Input func() (reader io.Reader, closer func())
 
+type NamedInput struct {
+	Name  string
+	Input Input
+}
+
 type Loader interface {
 	Load(input Input) (DataType, error)
 }
 
+// A DirLoader can load a directory of inputs while keeping track of which
+// input each item came from (rather than concatenating them together).
+type DirLoader interface {
+	Loader
+	LoadDir(inputs []NamedInput) (DataType, error)
+}
+
 type DataType interface {
 	Root() Node
 	LargestLevel() int
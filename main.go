@@ -52,9 +52,18 @@ mine - find all frequent patterns
                                   0 to turn off parallelism.
         --support=<int>           minimum support of patterns (required)
         --skip-log=<level>        don't output the given log level.
+        --serve=<addr>            serve the progress of the run and the patterns
+                                  found so far over http, eg.
+                                  --serve=127.0.0.1:8080 (see Serving)
 
     Developer Options
         --cpu-profile=<path>      write a cpu-profile to this location
+        --metrics=<name>          write the timing metrics (eg. of the embedding
+                                  search, canonicalization and the stores) to
+                                  this file in the output directory in the
+                                  prometheus text format and log a summary
+                                  table at exit. The stores are only timed
+                                  with this option.
 
         heap-profile Reporter
 
@@ -103,9 +112,18 @@ sample - sample frequent patterns
         --non-unique              by default, regrax collects only unique samples. This
                                   option allows non-unique samples.
         --skip-log=<level>        don't output the given log level.
+        --serve=<addr>            serve the progress of the run and the patterns
+                                  found so far over http, eg.
+                                  --serve=127.0.0.1:8080 (see Serving)
 
     Developer Options
         --cpu-profile=<path>      write a cpu-profile to this location
+        --metrics=<name>          write the timing metrics (eg. of the embedding
+                                  search, canonicalization and the stores) to
+                                  this file in the output directory in the
+                                  prometheus text format and log a summary
+                                  table at exit. The stores are only timed
+                                  with this option.
 
         heap-profile Reporter
 
@@ -0,0 +1,288 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+import (
+	"github.com/timtadh/regrax/progress"
+)
+
+// DefaultBuckets are the upper bounds (in seconds) of the histogram buckets:
+// 1us, 4us, 16us, ... 4.2s. Larger observations go in the +Inf bucket.
+var DefaultBuckets = ExponentialBuckets(1e-6, 4, 12)
+
+func ExponentialBuckets(start, factor float64, count int) []float64 {
+	buckets := make([]float64, count)
+	for i := range buckets {
+		buckets[i] = start
+		start *= factor
+	}
+	return buckets
+}
+
+// Counter is a progress.Counter with the name (and labels) it is exported
+// under.
+type Counter struct {
+	progress.Counter
+	name   string
+	labels string
+	help   string
+}
+
+// Histogram counts observations (durations in seconds) in buckets.
+type Histogram struct {
+	name    string
+	labels  string
+	help    string
+	buckets []float64
+	lock    sync.Mutex
+	counts  []int64 // counts[len(buckets)] is the +Inf bucket
+	count   int64
+	sum     float64
+	max     float64
+}
+
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.buckets, v)
+	h.lock.Lock()
+	h.counts[i]++
+	h.count++
+	h.sum += v
+	if v > h.max {
+		h.max = v
+	}
+	h.lock.Unlock()
+}
+
+// Since observes the time elapsed since start.
+func (h *Histogram) Since(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+// Time starts a timer which is observed when the returned function is
+// called:
+//
+//	defer metrics.Canonicalize.Time()()
+func (h *Histogram) Time() func() {
+	start := time.Now()
+	return func() {
+		h.Since(start)
+	}
+}
+
+// Count is the number of observations.
+func (h *Histogram) Count() int64 {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.count
+}
+
+// Quantile estimates the q quantile as the upper bound of the bucket it
+// falls in (or the max if that is the +Inf bucket).
+func (h *Histogram) Quantile(q float64) float64 {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.quantile(q)
+}
+
+func (h *Histogram) quantile(q float64) float64 {
+	if h.count == 0 {
+		return 0
+	}
+	rank := int64(math.Ceil(q * float64(h.count)))
+	if rank < 1 {
+		rank = 1
+	}
+	var seen int64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= rank {
+			if i < len(h.buckets) && h.buckets[i] < h.max {
+				return h.buckets[i]
+			}
+			return h.max
+		}
+	}
+	return h.max
+}
+
+var (
+	lock       sync.Mutex
+	counters   []*Counter
+	histograms []*Histogram
+)
+
+// NewCounter registers a counter. The labels are in the prometheus format
+// (eg. `store="int_int"`) and may be empty.
+func NewCounter(name, labels, help string) *Counter {
+	c := &Counter{name: name, labels: labels, help: help}
+	lock.Lock()
+	counters = append(counters, c)
+	lock.Unlock()
+	return c
+}
+
+// NewHistogram registers a histogram with the given bucket upper bounds
+// (which must be sorted).
+func NewHistogram(name, labels, help string, buckets []float64) *Histogram {
+	h := &Histogram{
+		name:    name,
+		labels:  labels,
+		help:    help,
+		buckets: buckets,
+		counts:  make([]int64, len(buckets)+1),
+	}
+	lock.Lock()
+	histograms = append(histograms, h)
+	lock.Unlock()
+	return h
+}
+
+// StoreReads and StoreWrites time the operations on the named store.
+func StoreReads(store string) *Histogram {
+	return NewHistogram("store_read_seconds", fmt.Sprintf("store=%q", store), "time spent finding keys in the stores", DefaultBuckets)
+}
+
+func StoreWrites(store string) *Histogram {
+	return NewHistogram("store_write_seconds", fmt.Sprintf("store=%q", store), "time spent adding and removing keys in the stores", DefaultBuckets)
+}
+
+// The metrics of the digraph type (the stores register their own).
+var (
+	IterEmbeddings = NewHistogram("iter_embeddings_seconds", "",
+		"time spent searching for the embeddings of a pattern (per pattern)", DefaultBuckets)
+	Embeddings = NewCounter("embeddings_total", "",
+		"embeddings found by the embedding search")
+	ExtsFromEmbs = NewHistogram("extensions_from_embeddings_seconds", "",
+		"time spent computing the extensions of a pattern from its embeddings (including the search)", DefaultBuckets)
+	Canonicalize = NewHistogram("canonicalize_seconds", "",
+		"time spent computing canonical permutations with bliss", DefaultBuckets)
+	ExtsAndEmbsCached = NewHistogram("exts_and_embs_seconds", `source="cache"`,
+		"time spent getting the extensions and embeddings of a pattern", DefaultBuckets)
+	ExtsAndEmbsComputed = NewHistogram("exts_and_embs_seconds", `source="computed"`,
+		"time spent getting the extensions and embeddings of a pattern", DefaultBuckets)
+)
+
+func fullName(name, labels string) string {
+	if labels == "" {
+		return name
+	}
+	return name + "{" + labels + "}"
+}
+
+type byName []*Histogram
+
+func (s byName) Len() int { return len(s) }
+func (s byName) Less(i, j int) bool {
+	return fullName(s[i].name, s[i].labels) < fullName(s[j].name, s[j].labels)
+}
+func (s byName) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+func registered() ([]*Counter, []*Histogram) {
+	lock.Lock()
+	defer lock.Unlock()
+	cs := make([]*Counter, len(counters))
+	copy(cs, counters)
+	hs := make([]*Histogram, len(histograms))
+	copy(hs, histograms)
+	sort.Sort(byName(hs))
+	return cs, hs
+}
+
+// WriteSummary writes a table of the counters and of the histograms which
+// have observations (count, total, mean, median, 99th percentile and max).
+func WriteSummary(w io.Writer) error {
+	cs, hs := registered()
+	lines := make([]string, 0, len(cs)+len(hs)+2)
+	lines = append(lines, fmt.Sprintf("%-52v %10v %12v %12v %12v %12v %12v",
+		"metric", "count", "total", "mean", "p50", "p99", "max"))
+	for _, h := range hs {
+		h.lock.Lock()
+		if h.count > 0 {
+			lines = append(lines, fmt.Sprintf("%-52v %10d %12v %12v %12v %12v %12v",
+				fullName(h.name, h.labels),
+				h.count,
+				seconds(h.sum),
+				seconds(h.sum/float64(h.count)),
+				seconds(h.quantile(.5)),
+				seconds(h.quantile(.99)),
+				seconds(h.max)))
+		}
+		h.lock.Unlock()
+	}
+	for _, c := range cs {
+		if n := c.Get(); n > 0 {
+			lines = append(lines, fmt.Sprintf("%-52v %10d", fullName(c.name, c.labels), n))
+		}
+	}
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// WritePrometheus writes every metric (and the progress counters) in the
+// prometheus text exposition format. Every name is prefixed with regrax_.
+func WritePrometheus(w io.Writer) error {
+	cs, hs := registered()
+	out := make([]string, 0, len(cs)*3+len(hs)*20)
+	described := make(map[string]bool)
+	describe := func(name, help, kind string) {
+		if described[name] {
+			return
+		}
+		described[name] = true
+		out = append(out, fmt.Sprintf("# HELP regrax_%v %v", name, help))
+		out = append(out, fmt.Sprintf("# TYPE regrax_%v %v", name, kind))
+	}
+	sample := func(name, labels string, v interface{}) {
+		out = append(out, fmt.Sprintf("regrax_%v %v", fullName(name, labels), v))
+	}
+	join := func(labels, label string) string {
+		if labels == "" {
+			return label
+		}
+		return labels + "," + label
+	}
+	for _, c := range cs {
+		describe(c.name, c.help, "counter")
+		sample(c.name, c.labels, c.Get())
+	}
+	snapshot := progress.Snapshot()
+	names := make([]string, 0, len(snapshot))
+	for name := range snapshot {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		describe("progress_"+name, "progress of the run (see --serve)", "counter")
+		sample("progress_"+name, "", snapshot[name])
+	}
+	for _, h := range hs {
+		describe(h.name, h.help, "histogram")
+		h.lock.Lock()
+		var cumulative int64
+		for i, c := range h.counts {
+			cumulative += c
+			le := "+Inf"
+			if i < len(h.buckets) {
+				le = fmt.Sprint(h.buckets[i])
+			}
+			sample(h.name+"_bucket", join(h.labels, fmt.Sprintf("le=%q", le)), cumulative)
+		}
+		sample(h.name+"_sum", h.labels, h.sum)
+		sample(h.name+"_count", h.labels, h.count)
+		h.lock.Unlock()
+	}
+	_, err := fmt.Fprintln(w, strings.Join(out, "\n"))
+	return err
+}
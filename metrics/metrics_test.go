@@ -0,0 +1,51 @@
+package metrics
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+import (
+	"bytes"
+	"strings"
+)
+
+func TestHistogramQuantile(t *testing.T) {
+	x := assert.New(t)
+	h := NewHistogram("test_quantile_seconds", "", "test", []float64{1, 2, 4})
+	x.Equal(0.0, h.Quantile(.5))
+	for _, v := range []float64{.5, .5, 1.5, 3, 10} {
+		h.Observe(v)
+	}
+	x.Equal(int64(5), h.Count())
+	x.Equal(1.0, h.Quantile(.2))
+	x.Equal(1.0, h.Quantile(.4))
+	x.Equal(2.0, h.Quantile(.6))
+	x.Equal(4.0, h.Quantile(.8))
+	x.Equal(10.0, h.Quantile(1))
+}
+
+func TestWritePrometheus(t *testing.T) {
+	x := assert.New(t)
+	h := NewHistogram("test_prom_seconds", `kind="a"`, "test", []float64{1, 2})
+	h.Observe(.5)
+	h.Observe(3)
+	c := NewCounter("test_prom_total", "", "test")
+	c.Add(7)
+	var buf bytes.Buffer
+	x.Nil(WritePrometheus(&buf))
+	out := buf.String()
+	for _, line := range []string{
+		"# TYPE regrax_test_prom_seconds histogram",
+		`regrax_test_prom_seconds_bucket{kind="a",le="1"} 1`,
+		`regrax_test_prom_seconds_bucket{kind="a",le="2"} 1`,
+		`regrax_test_prom_seconds_bucket{kind="a",le="+Inf"} 2`,
+		`regrax_test_prom_seconds_sum{kind="a"} 3.5`,
+		`regrax_test_prom_seconds_count{kind="a"} 2`,
+		"# TYPE regrax_test_prom_total counter",
+		"regrax_test_prom_total 7",
+	} {
+		x.Contains(strings.Split(out, "\n"), line)
+	}
+	buf.Reset()
+	x.Nil(WriteSummary(&buf))
+	x.Contains(buf.String(), `test_prom_seconds{kind="a"}`)
+}
@@ -177,6 +177,8 @@ func Run(argv []string) int {
 			"skip-log=",
 			"cpu-profile=",
 			"parallelism=",
+			"serve=",
+			"metrics=",
 		},
 	)
 	if err != nil {
@@ -191,6 +193,8 @@ func Run(argv []string) int {
 	support := 0
 	cpuProfile := ""
 	parallelism := -1
+	serve := ""
+	metricsName := ""
 	for _, oa := range optargs {
 		switch oa.Opt() {
 		case "-h", "--help":
@@ -227,6 +231,10 @@ func Run(argv []string) int {
 			errors.SkipLogging[level] = true
 		case "--cpu-profile":
 			cpuProfile = cmd.AssertFile(oa.Arg())
+		case "--serve":
+			serve = oa.Arg()
+		case "--metrics":
+			metricsName = oa.Arg()
 		default:
 			fmt.Fprintf(os.Stderr, "Unknown flag '%v'\n", oa.Opt())
 			cmd.Usage(cmd.ErrorCodes["opts"])
@@ -252,6 +260,8 @@ func Run(argv []string) int {
 		Output:      output,
 		Support:     support,
 		Parallelism: parallelism,
+		Serve:       serve,
+		Metrics:     metricsName,
 	}
 
 	return cmd.Main(args, conf, modes)
@@ -0,0 +1,58 @@
+package progress
+
+import (
+	"sync/atomic"
+)
+
+// Counter is a count which may be incremented concurrently.
+type Counter struct {
+	n int64
+}
+
+func (c *Counter) Inc() {
+	atomic.AddInt64(&c.n, 1)
+}
+
+func (c *Counter) Add(d int64) {
+	atomic.AddInt64(&c.n, d)
+}
+
+func (c *Counter) Get() int64 {
+	return atomic.LoadInt64(&c.n)
+}
+
+// The progress of the current run. They are only ever incremented so they
+// can be read at any time (eg. by the --serve http server).
+var (
+	Expanded    Counter // lattice nodes whose children were computed
+	Reported    Counter // nodes passed to the reporters
+	Accepted    Counter // samples accepted by the rejecting walk
+	Rejected    Counter // samples rejected as not acceptable
+	Duplicates  Counter // samples rejected as already sampled
+	CacheHits   Counter // embeddings or adjacent nodes loaded from the cache
+	CacheMisses Counter // embeddings or adjacent nodes which were computed
+)
+
+// Snapshot reads every counter.
+func Snapshot() map[string]int64 {
+	return map[string]int64{
+		"expanded":     Expanded.Get(),
+		"reported":     Reported.Get(),
+		"accepted":     Accepted.Get(),
+		"rejected":     Rejected.Get(),
+		"duplicates":   Duplicates.Get(),
+		"cache_hits":   CacheHits.Get(),
+		"cache_misses": CacheMisses.Get(),
+	}
+}
+
+// CacheHitRate is the fraction of cache lookups which were hits (0 if there
+// were none).
+func CacheHitRate() float64 {
+	hits := CacheHits.Get()
+	total := hits + CacheMisses.Get()
+	if total == 0 {
+		return 0
+	}
+	return float64(hits) / float64(total)
+}
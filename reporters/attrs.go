@@ -0,0 +1,32 @@
+package reporters
+
+import (
+	"github.com/timtadh/regrax/types/digraph"
+)
+
+// vertexAttrs loads the attributes of the vertex of G from the NodeAttrs
+// store (an empty map if there are none).
+func vertexAttrs(dt *digraph.Digraph, id int) (map[string]interface{}, error) {
+	attrs := make(map[string]interface{})
+	if dt.NodeAttrs == nil {
+		return attrs, nil
+	}
+	err := dt.NodeAttrs.DoFind(
+		int32(id),
+		func(_ int32, a map[string]interface{}) error {
+			attrs = a
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return attrs, nil
+}
+
+// vertexOid is the original id of a vertex (or its index if it has none).
+func vertexOid(attrs map[string]interface{}, id int) interface{} {
+	if oid, has := attrs["oid"]; has {
+		return oid
+	}
+	return id
+}
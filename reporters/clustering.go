@@ -0,0 +1,181 @@
+package reporters
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+)
+
+// clusterMetric looks up a distance between cluster nodes by name:
+// structure (Pattern.Distance), label or attr (jaccard distances).
+func clusterMetric(name string) (func(a, b *clusterNode) float64, error) {
+	switch name {
+	case "structure":
+		return structureSimilarity, nil
+	case "label":
+		return labelSimilarity, nil
+	case "attr":
+		return attrSimilarity, nil
+	}
+	return nil, errors.Errorf("unknown metric '%v' (expected structure, label or attr)", name)
+}
+
+// distanceMatrix computes the metric once for every pair of nodes.
+func distanceMatrix(nodes []*clusterNode, metric func(a, b *clusterNode) float64) [][]float64 {
+	d := make([][]float64, len(nodes))
+	for i := range d {
+		d[i] = make([]float64, len(nodes))
+	}
+	for i := range nodes {
+		for j := i + 1; j < len(nodes); j++ {
+			d[i][j] = metric(nodes[i], nodes[j])
+			d[j][i] = d[i][j]
+		}
+	}
+	return d
+}
+
+// copyMatrix copies a distance matrix (eg. for agglomerate which overwrites
+// it).
+func copyMatrix(d [][]float64) [][]float64 {
+	c := make([][]float64, len(d))
+	for i := range d {
+		c[i] = append([]float64(nil), d[i]...)
+	}
+	return c
+}
+
+// memoized answers the metric for the nodes from their distance matrix.
+func memoized(nodes []*clusterNode, d [][]float64) func(a, b *clusterNode) float64 {
+	idx := make(map[*clusterNode]int, len(nodes))
+	for i, n := range nodes {
+		idx[n] = i
+	}
+	return func(a, b *clusterNode) float64 {
+		return d[idx[a]][idx[b]]
+	}
+}
+
+// silhouette is the mean silhouette of the nodes: (b - a)/max(a, b) where a
+// is the mean distance to the rest of its cluster and b the mean distance to
+// the nearest other cluster. Nodes in singleton clusters score 0. It is NaN
+// with fewer than two clusters.
+func silhouette(clusters []cluster, metric func(a, b *clusterNode) float64) float64 {
+	if len(clusters) < 2 {
+		return math.NaN()
+	}
+	var total float64
+	var count int
+	for x, X := range clusters {
+		for i := range X {
+			count++
+			if len(X) <= 1 {
+				continue
+			}
+			var a float64
+			for j := range X {
+				if i != j {
+					a += metric(X[i], X[j])
+				}
+			}
+			a /= float64(len(X) - 1)
+			b := math.Inf(1)
+			for y, Y := range clusters {
+				if y == x || len(Y) == 0 {
+					continue
+				}
+				var to float64
+				for j := range Y {
+					to += metric(X[i], Y[j])
+				}
+				b = math.Min(b, to/float64(len(Y)))
+			}
+			if m := math.Max(a, b); m > 0 {
+				total += (b - a) / m
+			}
+		}
+	}
+	if count == 0 {
+		return math.NaN()
+	}
+	return total / float64(count)
+}
+
+// clusterQuality is the count, correlation, intra-distance and silhouette of
+// the clusters under each of the metrics.
+func clusterQuality(items int, clusters []cluster, metrics map[string]func(a, b *clusterNode) float64) map[string]interface{} {
+	x := map[string]interface{}{
+		"items": items,
+		"count": len(clusters),
+	}
+	for name, metric := range metrics {
+		x[name+"-correlation"] = noNan(correlation(clusters, metric))
+		x[name+"-intra-distance"] = noNan(intradist(clusters, metric))
+		x[name+"-silhouette"] = noNan(silhouette(clusters, metric))
+	}
+	return x
+}
+
+// qualityMetrics are the metrics the clusters are scored with: the one they
+// were clustered by (memoized) plus label (and attr when there is one).
+func qualityMetrics(name string, metric func(a, b *clusterNode) float64, attr string) map[string]func(a, b *clusterNode) float64 {
+	metrics := map[string]func(a, b *clusterNode) float64{
+		"label": labelSimilarity,
+	}
+	if attr != "" {
+		metrics["attr"] = attrSimilarity
+	}
+	metrics[name] = metric
+	return metrics
+}
+
+// writeClusters writes a json line for each node of each cluster. extra
+// may add fields to the line of a node.
+func writeClusters(c *config.Config, name string, clusters []cluster, attr string, extra func(i int, cn *clusterNode, x map[string]interface{})) error {
+	f, err := os.Create(c.OutputFile(name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	enc.SetEscapeHTML(false)
+	for i, cl := range clusters {
+		for _, cn := range cl {
+			x := map[string]interface{}{
+				"cluster": i,
+				"name":    cn.name,
+			}
+			if attr != "" {
+				x["items"] = fmt.Sprintf("%v", cn.items)
+			}
+			if extra != nil {
+				extra(i, cn, x)
+			}
+			err := enc.Encode(x)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeJSON(c *config.Config, name string, v interface{}) error {
+	f, err := os.Create(c.OutputFile(name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
@@ -0,0 +1,124 @@
+package reporters
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+import (
+	"github.com/timtadh/data-structures/set"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+)
+
+// two groups of points on a line: 0, 1, 2 and 10, 11
+func linePoints() ([]*clusterNode, [][]float64) {
+	xs := []float64{0, 1, 2, 10, 11}
+	nodes := make([]*clusterNode, len(xs))
+	for i := range xs {
+		nodes[i] = &clusterNode{name: string(rune('a' + i))}
+	}
+	d := make([][]float64, len(xs))
+	for i := range xs {
+		d[i] = make([]float64, len(xs))
+		for j := range xs {
+			d[i][j] = math.Abs(xs[i] - xs[j])
+		}
+	}
+	return nodes, d
+}
+
+func names(clusters []cluster) [][]string {
+	out := make([][]string, 0, len(clusters))
+	for _, c := range clusters {
+		ns := make([]string, 0, len(c))
+		for _, cn := range c {
+			ns = append(ns, cn.name)
+		}
+		out = append(out, ns)
+	}
+	return out
+}
+
+func TestAgglomerate(t *testing.T) {
+	x := assert.New(t)
+	nodes, d := linePoints()
+	for _, linkage := range []string{"single", "complete", "average"} {
+		root := agglomerate(nodes, copyMatrix(d), linkage)
+		x.Equal(5, root.Size, linkage)
+		x.Equal(2, len(root.Children), linkage)
+		x.Equal([][]string{{"a", "b", "c"}, {"d", "e"}}, names(cutClusters(root, 2)), linkage)
+		x.Equal([][]string{{"a", "b", "c"}, {"d", "e"}}, names(cutDistance(root, 5)), linkage)
+		x.Equal(5, len(cutClusters(root, 10)), linkage)
+		x.Equal(1, len(cutDistance(root, 100)), linkage)
+	}
+	single := agglomerate(nodes, copyMatrix(d), "single")
+	complete := agglomerate(nodes, copyMatrix(d), "complete")
+	x.Equal(8.0, single.Distance)
+	x.Equal(11.0, complete.Distance)
+	x.Nil(agglomerate(nil, nil, "single"))
+}
+
+func TestKMedoids(t *testing.T) {
+	x := assert.New(t)
+	_, d := linePoints()
+	medoids, assignment, _ := kmedoids(d, 2, 100)
+	x.Equal([]int{1, 3}, medoids)
+	x.Equal([]int{0, 0, 0, 1, 1}, assignment)
+	x.Equal(3.0, medoidCost(d, medoids, assignment))
+	medoids, assignment, _ = kmedoids(d, 10, 100)
+	x.Equal(5, len(medoids))
+	x.Equal(0.0, medoidCost(d, medoids, assignment))
+}
+
+func TestSilhouette(t *testing.T) {
+	x := assert.New(t)
+	nodes, d := linePoints()
+	metric := memoized(nodes, d)
+	good := []cluster{{nodes[0], nodes[1], nodes[2]}, {nodes[3], nodes[4]}}
+	bad := []cluster{{nodes[0], nodes[3]}, {nodes[1], nodes[2], nodes[4]}}
+	x.True(silhouette(good, metric) > .8)
+	x.True(silhouette(bad, metric) < 0)
+	x.True(math.IsNaN(silhouette(good[:1], metric)))
+}
+
+// The silhouette of the two clusters of the line points is the mean of
+// 9/10.5, 8.5/9.5, 7/8.5, 8/9 and 9/10 whichever linkage finds them.
+func TestHierarchicalSilhouette(t *testing.T) {
+	x := assert.New(t)
+	dir, err := ioutil.TempDir("", "hierarchical")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	expected := (9/10.5 + 8.5/9.5 + 7/8.5 + 8/9.0 + 9/10.0) / 5
+	for _, linkage := range []string{"single", "complete", "average"} {
+		r, err := NewHierarchical(&config.Config{Output: dir}, nil, "clusters", "metrics", "dendrogram", "", "structure", linkage, 2, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes, d := linePoints()
+		for _, n := range nodes {
+			n.labels = set.NewSortedSet(0)
+		}
+		r.nodes = nodes
+		r.metric = memoized(nodes, d)
+		x.Nil(r.Close())
+		out, err := ioutil.ReadFile(filepath.Join(dir, "metrics"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var quality map[string]interface{}
+		x.Nil(json.Unmarshal(out, &quality))
+		x.Equal(2.0, quality["count"], linkage)
+		x.InDelta(expected, quality["structure-silhouette"], 1e-9, linkage)
+	}
+}
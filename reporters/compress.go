@@ -0,0 +1,354 @@
+package reporters
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"strings"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/lattice"
+	"github.com/timtadh/regrax/types/digraph"
+	dg "github.com/timtadh/regrax/types/digraph/digraph"
+	"github.com/timtadh/regrax/types/digraph/subgraph"
+)
+
+// Compress greedily chooses, from the reported patterns, the pattern set
+// which best compresses the graph (in the style of SUBDUE and Krimp). The
+// description length of the graph given a pattern set is the length of the
+// patterns plus the length of the graph once the disjoint embeddings of the
+// patterns are each replaced by a single vertex labeled with the pattern.
+// Each step adds the pattern with the largest gain (in bits) until no
+// pattern shortens the description. On close it writes (as json) the chosen
+// patterns with their gains. Every embedding the miner counts (see
+// Digraph.DoEmbeddings) is a candidate for replacement so they are searched
+// for again: a node only keeps those supporting it (eg. of one vertex under
+// MNI) and may truncate them.
+type Compress struct {
+	config     *config.Config
+	fmtr       lattice.Formatter
+	filename   string
+	max        int
+	dt         *digraph.Digraph
+	candidates []*compressCandidate
+	reported   int
+}
+
+// compressCandidate is a reported pattern and the embeddings it may replace.
+type compressCandidate struct {
+	name    string
+	pattern string
+	support int
+	sg      *subgraph.SubGraph
+	embs    [][]int
+}
+
+type compressChoice struct {
+	Name      string  `json:"name"`
+	Pattern   string  `json:"pattern"`
+	Vertices  int     `json:"vertices"`
+	Edges     int     `json:"edges"`
+	Support   int     `json:"support"`
+	Instances int     `json:"instances"`
+	Gain      float64 `json:"gain_bits"`
+	Length    float64 `json:"length_bits"`
+}
+
+type compressReport struct {
+	Patterns      int              `json:"patterns"`
+	InitialLength float64          `json:"initial_length_bits"`
+	FinalLength   float64          `json:"final_length_bits"`
+	Ratio         float64          `json:"compression_ratio"`
+	Chosen        []compressChoice `json:"chosen"`
+}
+
+func NewCompress(c *config.Config, fmtr lattice.Formatter, filename string, max int) (*Compress, error) {
+	if !strings.HasSuffix(filename, ".json") {
+		filename = filename + ".json"
+	}
+	r := &Compress{
+		config:   c,
+		fmtr:     fmtr,
+		filename: filename,
+		max:      max,
+	}
+	return r, nil
+}
+
+func (r *Compress) Report(node lattice.Node) error {
+	n, ok := node.(*digraph.EmbListNode)
+	if !ok {
+		return errors.Errorf("the compress reporter does not support %T", node)
+	}
+	if r.dt == nil {
+		r.dt = n.Dt
+	}
+	r.reported++
+	if len(n.Pat.E) == 0 {
+		// replacing a vertex with a vertex compresses nothing
+		return nil
+	}
+	pat, err := r.fmtr.Pattern(n)
+	if err != nil {
+		return err
+	}
+	c := &compressCandidate{
+		name:    r.fmtr.PatternName(n),
+		pattern: pat,
+		support: n.Support(),
+		sg:      n.Pat,
+		embs:    make([][]int, 0, n.Support()),
+	}
+	err = r.dt.DoEmbeddings(n.Pat, func(emb *subgraph.Embedding) error {
+		ids := make([]int, len(emb.Ids))
+		copy(ids, emb.Ids)
+		c.embs = append(c.embs, ids)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	r.candidates = append(r.candidates, c)
+	return nil
+}
+
+func (r *Compress) Close() error {
+	report := &compressReport{
+		Patterns: r.reported,
+		Chosen:   make([]compressChoice, 0),
+	}
+	if r.dt != nil {
+		s := newMdlState(r.dt.G)
+		report.InitialLength = s.length()
+		report.Chosen = s.choose(r.candidates, r.max)
+		report.FinalLength = s.length()
+		if report.InitialLength > 0 {
+			report.Ratio = report.FinalLength / report.InitialLength
+		}
+	}
+	errors.Logf("INFO", "compress: chose %v of %v patterns, %.0f -> %.0f bits",
+		len(report.Chosen), len(r.candidates), report.InitialLength, report.FinalLength)
+	bytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(r.config.OutputFile(r.filename))
+	if err != nil {
+		return err
+	}
+	_, werr := f.Write(append(bytes, '\n'))
+	err = f.Close()
+	if werr != nil {
+		return werr
+	}
+	return err
+}
+
+// mdlState is the graph as compressed by the patterns chosen so far. A
+// vertex costs the bits of its label (the labels of G and one per chosen
+// pattern), an edge the bits of its endpoints and its label, and an edge
+// which touches a replaced embedding also the bits of which pattern vertex
+// it attaches to (a port).
+type mdlState struct {
+	G         *dg.Digraph
+	vlabels   int
+	elabels   int
+	collapsed []bool // vertices replaced by an embedding of a chosen pattern
+	covered   []bool // edges replaced by an embedding of a chosen pattern
+	nv, ne    int
+	ports     float64
+	model     float64 // the length of the chosen patterns
+	chosen    int
+}
+
+// mdlStep is the result of replacing the free embeddings of a pattern.
+type mdlStep struct {
+	instances [][]int
+	edges     []int
+	nv, ne    int
+	ports     float64
+}
+
+func newMdlState(G *dg.Digraph) *mdlState {
+	vcolors := make(map[int]bool)
+	ecolors := make(map[int]bool)
+	for i := range G.V {
+		vcolors[G.V[i].Color] = true
+	}
+	for i := range G.E {
+		ecolors[G.E[i].Color] = true
+	}
+	return &mdlState{
+		G:         G,
+		vlabels:   len(vcolors),
+		elabels:   len(ecolors),
+		collapsed: make([]bool, len(G.V)),
+		covered:   make([]bool, len(G.E)),
+		nv:        len(G.V),
+		ne:        len(G.E),
+	}
+}
+
+// lg is the bits needed to choose one of n things.
+func lg(n int) float64 {
+	if n <= 1 {
+		return 0
+	}
+	return math.Log2(float64(n))
+}
+
+func (s *mdlState) graphLength(nv, ne int, ports float64, chosen int) float64 {
+	return float64(nv)*lg(s.vlabels+chosen) + float64(ne)*(2*lg(nv)+lg(s.elabels)) + ports
+}
+
+func (s *mdlState) patternLength(sg *subgraph.SubGraph) float64 {
+	return float64(len(sg.V))*lg(s.vlabels) + float64(len(sg.E))*(2*lg(len(sg.V))+lg(s.elabels))
+}
+
+// length is the description length (in bits) of the chosen patterns and of
+// the graph given them.
+func (s *mdlState) length() float64 {
+	return s.model + s.graphLength(s.nv, s.ne, s.ports, s.chosen)
+}
+
+// gain is how much shorter the description gets with the step's pattern.
+func (s *mdlState) gain(c *compressCandidate, step *mdlStep) float64 {
+	after := s.model + s.patternLength(c.sg) + s.graphLength(step.nv, step.ne, step.ports, s.chosen+1)
+	return s.length() - after
+}
+
+// try replaces (without changing the state) the embeddings of the pattern
+// which are disjoint from the replaced vertices and from each other. It
+// returns nil if there are none.
+func (s *mdlState) try(c *compressCandidate) *mdlStep {
+	step := &mdlStep{nv: s.nv, ne: s.ne, ports: s.ports}
+	taken := make(map[int]bool)
+	port := lg(len(c.sg.V))
+	for _, ids := range c.embs {
+		free := true
+		for _, id := range ids {
+			if s.collapsed[id] || taken[id] {
+				free = false
+				break
+			}
+		}
+		if !free {
+			continue
+		}
+		edges := s.embEdges(c.sg, ids)
+		inside := make(map[int]bool, len(edges))
+		for _, e := range edges {
+			inside[e] = true
+		}
+		for _, id := range ids {
+			taken[id] = true
+			for _, e := range s.G.Kids[id] {
+				if !inside[e] {
+					step.ports += port
+				}
+			}
+			for _, e := range s.G.Parents[id] {
+				if !inside[e] {
+					step.ports += port
+				}
+			}
+		}
+		step.nv -= len(ids) - 1
+		step.ne -= len(edges)
+		step.instances = append(step.instances, ids)
+		step.edges = append(step.edges, edges...)
+	}
+	if len(step.instances) == 0 {
+		return nil
+	}
+	return step
+}
+
+// embEdges are the edges of G matched by the edges of the pattern.
+func (s *mdlState) embEdges(sg *subgraph.SubGraph, ids []int) []int {
+	edges := make([]int, 0, len(sg.E))
+	for i := range sg.E {
+		e := &sg.E[i]
+		src, targ := ids[e.Src], ids[e.Targ]
+	find:
+		for _, eidx := range s.G.Kids[src] {
+			if s.G.E[eidx].Targ != targ || s.G.E[eidx].Color != e.Color {
+				continue
+			}
+			for _, used := range edges {
+				if used == eidx {
+					continue find
+				}
+			}
+			edges = append(edges, eidx)
+			break
+		}
+	}
+	return edges
+}
+
+func (s *mdlState) commit(c *compressCandidate, step *mdlStep) {
+	for _, ids := range step.instances {
+		for _, id := range ids {
+			s.collapsed[id] = true
+		}
+	}
+	for _, e := range step.edges {
+		s.covered[e] = true
+	}
+	s.nv = step.nv
+	s.ne = step.ne
+	s.ports = step.ports
+	s.model += s.patternLength(c.sg)
+	s.chosen++
+}
+
+// choose greedily adds the pattern with the largest gain until none shortens
+// the description (or max patterns are chosen when max > 0). Ties go to the
+// pattern reported first.
+func (s *mdlState) choose(candidates []*compressCandidate, max int) []compressChoice {
+	chosen := make([]compressChoice, 0, 10)
+	done := make([]bool, len(candidates))
+	for max <= 0 || len(chosen) < max {
+		best := -1
+		var bestGain float64
+		var bestStep *mdlStep
+		for i, c := range candidates {
+			if done[i] {
+				continue
+			}
+			step := s.try(c)
+			if step == nil {
+				// its embeddings are all replaced and will remain so
+				done[i] = true
+				continue
+			}
+			if gain := s.gain(c, step); gain > bestGain {
+				best, bestGain, bestStep = i, gain, step
+			}
+		}
+		if best < 0 {
+			break
+		}
+		c := candidates[best]
+		done[best] = true
+		s.commit(c, bestStep)
+		chosen = append(chosen, compressChoice{
+			Name:      c.name,
+			Pattern:   c.pattern,
+			Vertices:  len(c.sg.V),
+			Edges:     len(c.sg.E),
+			Support:   c.support,
+			Instances: len(bestStep.instances),
+			Gain:      bestGain,
+			Length:    s.length(),
+		})
+	}
+	return chosen
+}
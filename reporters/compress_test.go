@@ -0,0 +1,163 @@
+package reporters
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/types/digraph"
+	dg "github.com/timtadh/regrax/types/digraph/digraph"
+	"github.com/timtadh/regrax/types/digraph/subgraph"
+)
+
+func testGraph(colors []int, edges []dg.Edge) *dg.Digraph {
+	G := &dg.Digraph{
+		V:       make(dg.Vertices, len(colors)),
+		E:       make(dg.Edges, len(edges)),
+		Adj:     make([][]int, len(colors)),
+		Kids:    make([][]int, len(colors)),
+		Parents: make([][]int, len(colors)),
+	}
+	for i, c := range colors {
+		G.V[i] = dg.Vertex{Idx: i, Color: c}
+	}
+	for i, e := range edges {
+		G.E[i] = e
+		G.Adj[e.Src] = append(G.Adj[e.Src], i)
+		G.Kids[e.Src] = append(G.Kids[e.Src], i)
+		if e.Targ != e.Src {
+			G.Adj[e.Targ] = append(G.Adj[e.Targ], i)
+		}
+		G.Parents[e.Targ] = append(G.Parents[e.Targ], i)
+	}
+	return G
+}
+
+func testPath(colors []int, color int) *subgraph.SubGraph {
+	sg := &subgraph.SubGraph{}
+	for i, c := range colors {
+		sg.V = append(sg.V, subgraph.Vertex{Idx: i, Color: c})
+		if i > 0 {
+			sg.E = append(sg.E, subgraph.Edge{Src: i - 1, Targ: i, Color: color})
+		}
+	}
+	return sg
+}
+
+// four copies of a -> b -> c chained together by c -> a edges of another
+// color.
+func compressGraph() *dg.Digraph {
+	colors := make([]int, 0, 12)
+	edges := make([]dg.Edge, 0, 11)
+	for i := 0; i < 4; i++ {
+		a := len(colors)
+		colors = append(colors, 1, 2, 3)
+		edges = append(edges, dg.Edge{Src: a, Targ: a + 1, Color: 10}, dg.Edge{Src: a + 1, Targ: a + 2, Color: 10})
+		if i > 0 {
+			edges = append(edges, dg.Edge{Src: a - 1, Targ: a, Color: 11})
+		}
+	}
+	return testGraph(colors, edges)
+}
+
+func TestCompressChoosesBestPattern(t *testing.T) {
+	x := assert.New(t)
+	ab := &compressCandidate{name: "ab", sg: testPath([]int{1, 2}, 10)}
+	abc := &compressCandidate{name: "abc", sg: testPath([]int{1, 2, 3}, 10)}
+	for i := 0; i < 4; i++ {
+		ab.embs = append(ab.embs, []int{3 * i, 3*i + 1})
+		abc.embs = append(abc.embs, []int{3 * i, 3*i + 1, 3*i + 2})
+	}
+	s := newMdlState(compressGraph())
+	initial := s.length()
+	chosen := s.choose([]*compressCandidate{ab, abc}, 0)
+	x.Equal(1, len(chosen))
+	x.Equal("abc", chosen[0].Name)
+	x.Equal(4, chosen[0].Instances)
+	x.True(chosen[0].Gain > 0)
+	x.InDelta(initial-chosen[0].Gain, s.length(), 1e-9)
+	x.Equal(4, s.nv)
+	x.Equal(3, s.ne)
+}
+
+func TestCompressSkipsOverlappingEmbeddings(t *testing.T) {
+	x := assert.New(t)
+	// two chained copies, the embeddings overlap so only two are disjoint
+	abcabc := &compressCandidate{name: "abcabc", sg: testPath([]int{1, 2, 3, 1, 2, 3}, 10)}
+	abcabc.sg.E[2].Color = 11
+	abc := &compressCandidate{name: "abc", sg: testPath([]int{1, 2, 3}, 10)}
+	for i := 0; i < 4; i++ {
+		abc.embs = append(abc.embs, []int{3 * i, 3*i + 1, 3*i + 2})
+		if i < 3 {
+			abcabc.embs = append(abcabc.embs, []int{3 * i, 3*i + 1, 3*i + 2, 3*i + 3, 3*i + 4, 3*i + 5})
+		}
+	}
+	s := newMdlState(compressGraph())
+	step := s.try(abcabc)
+	x.Equal(2, len(step.instances))
+	x.Equal(10, len(step.edges))
+	x.Equal(2, step.nv)
+	x.Equal(1, step.ne)
+	// the larger pattern has a longer description than it saves
+	chosen := s.choose([]*compressCandidate{abcabc, abc}, 0)
+	x.Equal(1, len(chosen))
+	x.Equal("abc", chosen[0].Name)
+}
+
+func TestCompressMax(t *testing.T) {
+	x := assert.New(t)
+	ab := &compressCandidate{name: "ab", sg: testPath([]int{1, 2}, 10)}
+	bc := &compressCandidate{name: "bc", sg: testPath([]int{2, 3}, 10)}
+	for i := 0; i < 4; i++ {
+		ab.embs = append(ab.embs, []int{3 * i, 3*i + 1})
+		bc.embs = append(bc.embs, []int{3*i + 1, 3*i + 2})
+	}
+	s := newMdlState(compressGraph())
+	x.Equal(1, len(s.choose([]*compressCandidate{ab, bc}, 1)))
+}
+
+// pairsDigraph has four disjoint a -> b edges. With a support of 1 the
+// embedding search stops at the first one so its node keeps only that one.
+func pairsDigraph(t testing.TB, conf *config.Config) *digraph.Digraph {
+	labels := dg.NewLabels()
+	b := dg.Build(8, 4)
+	for i := 0; i < 4; i++ {
+		b.AddEdge(b.AddVertex(labels.Color("a")), b.AddVertex(labels.Color("b")), labels.Color("e"))
+	}
+	dt, err := digraph.NewDigraph(conf, &digraph.Config{
+		MaxEdges:            1,
+		Mode:                digraph.MNI | digraph.ExtFromEmb | digraph.Caching,
+		EmbSearchStartPoint: subgraph.RandomStart,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = dt.Init(b, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dt
+}
+
+func TestCompressEveryEmbedding(t *testing.T) {
+	x := assert.New(t)
+	conf := &config.Config{Support: 1}
+	dt := pairsDigraph(t, conf)
+	defer dt.Close()
+	n := edgeNode(t, dt)
+	embs, err := n.Embeddings()
+	x.Nil(err)
+	x.Equal(1, len(embs))
+	r, err := NewCompress(conf, &fixedFormatter{}, "compress", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x.Nil(r.Report(n))
+	if !x.Equal(1, len(r.candidates)) {
+		return
+	}
+	c := r.candidates[0]
+	x.Equal(4, len(c.embs))
+	step := newMdlState(dt.G).try(c)
+	x.Equal(4, len(step.instances))
+}
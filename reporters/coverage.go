@@ -0,0 +1,218 @@
+package reporters
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/lattice"
+	"github.com/timtadh/regrax/types/digraph"
+	"github.com/timtadh/regrax/types/digraph/subgraph"
+)
+
+// Coverage tracks the vertices and edges of the graph covered by the
+// embeddings of the reported patterns. On close it writes (as json) the
+// overall and per-label coverage and the uncovered vertices and edges. Every
+// embedding the miner counts (see Digraph.DoEmbeddings) so they are searched
+// for again: a node only keeps those supporting it (eg. of one vertex under
+// MNI) and may truncate them.
+type Coverage struct {
+	config   *config.Config
+	filename string
+	dt       *digraph.Digraph
+	vertices []bool
+	edges    []bool
+	reported int
+}
+
+type coverageCount struct {
+	Label    string  `json:"label,omitempty"`
+	Covered  int     `json:"covered"`
+	Total    int     `json:"total"`
+	Fraction float64 `json:"fraction"`
+}
+
+type uncoveredVertex struct {
+	Id    interface{} `json:"id"`
+	Label string      `json:"label"`
+}
+
+type uncoveredEdge struct {
+	Src   interface{} `json:"src"`
+	Targ  interface{} `json:"targ"`
+	Label string      `json:"label"`
+}
+
+type coverageReport struct {
+	Patterns          int               `json:"patterns"`
+	Vertices          coverageCount     `json:"vertices"`
+	Edges             coverageCount     `json:"edges"`
+	VertexLabels      []coverageCount   `json:"vertex_labels"`
+	EdgeLabels        []coverageCount   `json:"edge_labels"`
+	UncoveredVertices []uncoveredVertex `json:"uncovered_vertices"`
+	UncoveredEdges    []uncoveredEdge   `json:"uncovered_edges"`
+}
+
+func NewCoverage(c *config.Config, filename string) (*Coverage, error) {
+	if !strings.HasSuffix(filename, ".json") {
+		filename = filename + ".json"
+	}
+	r := &Coverage{
+		config:   c,
+		filename: filename,
+	}
+	return r, nil
+}
+
+func (r *Coverage) Report(node lattice.Node) error {
+	n, ok := node.(*digraph.EmbListNode)
+	if !ok {
+		return errors.Errorf("the coverage reporter does not support %T", node)
+	}
+	if r.dt == nil {
+		r.dt = n.Dt
+		r.vertices = make([]bool, len(r.dt.G.V))
+		r.edges = make([]bool, len(r.dt.G.E))
+	}
+	r.reported++
+	G := r.dt.G
+	return r.dt.DoEmbeddings(n.Pat, func(emb *subgraph.Embedding) error {
+		for _, id := range emb.Ids {
+			r.vertices[id] = true
+		}
+		for i := range emb.SG.E {
+			e := &emb.SG.E[i]
+			src, targ := emb.Ids[e.Src], emb.Ids[e.Targ]
+			// every parallel edge of the color is matched by the pattern edge
+			for _, eidx := range G.Kids[src] {
+				if G.E[eidx].Targ == targ && G.E[eidx].Color == e.Color {
+					r.edges[eidx] = true
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// oid is the original id of the vertex (or its index if it has none).
+func (r *Coverage) oid(id int) (interface{}, error) {
+	attrs, err := vertexAttrs(r.dt, id)
+	if err != nil {
+		return nil, err
+	}
+	return vertexOid(attrs, id), nil
+}
+
+func (r *Coverage) Close() error {
+	report := &coverageReport{
+		Patterns:          r.reported,
+		VertexLabels:      make([]coverageCount, 0),
+		EdgeLabels:        make([]coverageCount, 0),
+		UncoveredVertices: make([]uncoveredVertex, 0),
+		UncoveredEdges:    make([]uncoveredEdge, 0),
+	}
+	if r.dt != nil {
+		G := r.dt.G
+		vlabels := make(map[int]*coverageCount)
+		elabels := make(map[int]*coverageCount)
+		count := func(counts map[int]*coverageCount, color int, covered bool) {
+			c, has := counts[color]
+			if !has {
+				c = &coverageCount{Label: r.dt.Labels.Label(color)}
+				counts[color] = c
+			}
+			c.Total++
+			if covered {
+				c.Covered++
+			}
+		}
+		for id := range G.V {
+			count(vlabels, G.V[id].Color, r.vertices[id])
+			report.Vertices.Total++
+			if r.vertices[id] {
+				report.Vertices.Covered++
+				continue
+			}
+			oid, err := r.oid(id)
+			if err != nil {
+				return err
+			}
+			report.UncoveredVertices = append(report.UncoveredVertices, uncoveredVertex{
+				Id:    oid,
+				Label: r.dt.Labels.Label(G.V[id].Color),
+			})
+		}
+		for eidx := range G.E {
+			e := &G.E[eidx]
+			count(elabels, e.Color, r.edges[eidx])
+			report.Edges.Total++
+			if r.edges[eidx] {
+				report.Edges.Covered++
+				continue
+			}
+			src, err := r.oid(e.Src)
+			if err != nil {
+				return err
+			}
+			targ, err := r.oid(e.Targ)
+			if err != nil {
+				return err
+			}
+			report.UncoveredEdges = append(report.UncoveredEdges, uncoveredEdge{
+				Src:   src,
+				Targ:  targ,
+				Label: r.dt.Labels.Label(e.Color),
+			})
+		}
+		for _, c := range vlabels {
+			report.VertexLabels = append(report.VertexLabels, *c)
+		}
+		for _, c := range elabels {
+			report.EdgeLabels = append(report.EdgeLabels, *c)
+		}
+	}
+	fraction(&report.Vertices)
+	fraction(&report.Edges)
+	for _, counts := range [][]coverageCount{report.VertexLabels, report.EdgeLabels} {
+		for i := range counts {
+			fraction(&counts[i])
+		}
+		sort.Sort(byLabel(counts))
+	}
+	errors.Logf("INFO", "coverage: %v/%v vertices %v/%v edges",
+		report.Vertices.Covered, report.Vertices.Total, report.Edges.Covered, report.Edges.Total)
+	bytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(r.config.OutputFile(r.filename))
+	if err != nil {
+		return err
+	}
+	_, werr := f.Write(append(bytes, '\n'))
+	err = f.Close()
+	if werr != nil {
+		return werr
+	}
+	return err
+}
+
+type byLabel []coverageCount
+
+func (s byLabel) Len() int           { return len(s) }
+func (s byLabel) Less(i, j int) bool { return s[i].Label < s[j].Label }
+func (s byLabel) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+func fraction(c *coverageCount) {
+	if c.Total > 0 {
+		c.Fraction = float64(c.Covered) / float64(c.Total)
+	}
+}
@@ -0,0 +1,97 @@
+package reporters
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/types/digraph"
+	dg "github.com/timtadh/regrax/types/digraph/digraph"
+	"github.com/timtadh/regrax/types/digraph/subgraph"
+)
+
+// starDigraph has the edges a -> b and a -> b' and an unconnected c vertex.
+// Under MNI the a -> b pattern is supported by its single a image so its node
+// keeps only one of its two embeddings.
+func starDigraph(t testing.TB, conf *config.Config) *digraph.Digraph {
+	labels := dg.NewLabels()
+	b := dg.Build(4, 2)
+	a := b.AddVertex(labels.Color("a"))
+	b.AddEdge(a, b.AddVertex(labels.Color("b")), labels.Color("e"))
+	b.AddEdge(a, b.AddVertex(labels.Color("b")), labels.Color("e"))
+	b.AddVertex(labels.Color("c"))
+	dt, err := digraph.NewDigraph(conf, &digraph.Config{
+		MaxEdges:            1,
+		Mode:                digraph.MNI | digraph.ExtFromEmb | digraph.Caching,
+		EmbSearchStartPoint: subgraph.RandomStart,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = dt.Init(b, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dt
+}
+
+// edgeNode is the single edge pattern of the digraph.
+func edgeNode(t testing.TB, dt *digraph.Digraph) *digraph.EmbListNode {
+	queue := []*digraph.EmbListNode{digraph.RootEmbListNode(dt)}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if len(n.Pat.E) == 1 {
+			return n
+		}
+		kids, err := n.Children()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, k := range kids {
+			queue = append(queue, k.(*digraph.EmbListNode))
+		}
+	}
+	t.Fatal("no edge pattern")
+	return nil
+}
+
+func TestCoverageCountsEveryEmbedding(t *testing.T) {
+	x := assert.New(t)
+	dir, err := ioutil.TempDir("", "coverage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	conf := &config.Config{Support: 1, Output: dir}
+	dt := starDigraph(t, conf)
+	defer dt.Close()
+	n := edgeNode(t, dt)
+	embs, err := n.Embeddings()
+	x.Nil(err)
+	x.Equal(1, len(embs))
+	r, err := NewCoverage(conf, "coverage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	x.Nil(r.Report(n))
+	x.Nil(r.Close())
+	out, err := ioutil.ReadFile(filepath.Join(dir, "coverage.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report coverageReport
+	x.Nil(json.Unmarshal(out, &report))
+	x.Equal(1, report.Patterns)
+	x.Equal(coverageCount{Covered: 3, Total: 4, Fraction: .75}, report.Vertices)
+	x.Equal(coverageCount{Covered: 2, Total: 2, Fraction: 1}, report.Edges)
+	x.Equal([]uncoveredVertex{{Id: 3.0, Label: "c"}}, report.UncoveredVertices)
+	x.Equal(0, len(report.UncoveredEdges))
+}
@@ -30,9 +30,13 @@ type clusterNode struct {
 }
 
 func newClusterNode(fmtr lattice.Formatter, n lattice.Node, attr string) (*clusterNode, error) {
-	items, err := itemset(n, attr)
-	if err != nil {
-		return nil, err
+	var items types.Set = set.NewSortedSet(0)
+	if attr != "" {
+		var err error
+		items, err = nodeItemset(n, attr)
+		if err != nil {
+			return nil, err
+		}
 	}
 	labels, err := labelset(n)
 	if err != nil {
@@ -263,6 +267,8 @@ func (r *DbScan) metrics(random []cluster) error {
 			"count": len(r.clusters),
 			"attr-correlation": noNan(correlation(r.clusters, attrSimilarity)),
 			"label-correlation": noNan(correlation(r.clusters, labelSimilarity)),
+			"attr-silhouette": noNan(silhouette(r.clusters, attrSimilarity)),
+			"label-silhouette": noNan(silhouette(r.clusters, labelSimilarity)),
 			"label-intra-distance": noNan(intraLabel),
 			"label-inter-distance": noNan(interLabel),
 			"label-distance-ratio": noNan(intraLabel/interLabel),
@@ -274,6 +280,8 @@ func (r *DbScan) metrics(random []cluster) error {
 			"count": len(random),
 			"attr-correlation": noNan(correlation(random, attrSimilarity)),
 			"label-correlation": noNan(correlation(random, labelSimilarity)),
+			"attr-silhouette": noNan(silhouette(random, attrSimilarity)),
+			"label-silhouette": noNan(silhouette(random, labelSimilarity)),
 			"label-intra-distance": noNan(intraLabelRand),
 			"label-inter-distance": noNan(interLabelRand),
 			"label-distance-ratio": noNan(intraLabelRand/interLabelRand),
@@ -285,6 +293,8 @@ func (r *DbScan) metrics(random []cluster) error {
 			"count": noNan(stderr(float64(len(r.clusters)), float64(len(random)))),
 			"attr-correlation": noNan(stderr(correlation(r.clusters, attrSimilarity), correlation(random, attrSimilarity))),
 			"label-correlation": noNan(stderr(correlation(r.clusters, labelSimilarity), correlation(random, labelSimilarity))),
+			"attr-silhouette": noNan(stderr(silhouette(r.clusters, attrSimilarity), silhouette(random, attrSimilarity))),
+			"label-silhouette": noNan(stderr(silhouette(r.clusters, labelSimilarity), silhouette(random, labelSimilarity))),
 			"label-intra-distance": noNan(stderr(intraLabel, intraLabelRand)),
 			"label-inter-distance": noNan(stderr(interLabel, interLabelRand)),
 			"label-distance-ratio": noNan(stderr(intraLabel/interLabel, intraLabelRand/interLabelRand)),
@@ -356,7 +366,7 @@ func remove(list []cluster, i int) []cluster {
 	return list[:len(list)-1]
 }
 
-func itemset(node lattice.Node, attr string) (types.Set, error) {
+func nodeItemset(node lattice.Node, attr string) (types.Set, error) {
 	switch n := node.(type) {
 	case *digraph.EmbListNode:
 		return digraphItemset(n, attr)
@@ -15,6 +15,12 @@ import (
 	"github.com/timtadh/regrax/lattice"
 )
 
+// truncatable nodes may keep only some of their embeddings.
+type truncatable interface {
+	Truncated() bool
+	EmbeddingCount() int
+}
+
 type Dir struct {
 	config *config.Config
 	fmtr    lattice.Formatter
@@ -74,6 +80,14 @@ func (r *Dir) Report(n lattice.Node) error {
 	}
 	defer count.Close()
 	fmt.Fprintf(count, "%d\n", len(embs))
+	if t, ok := n.(truncatable); ok && t.Truncated() {
+		truncated, err := os.Create(filepath.Join(dir, "truncated"))
+		if err != nil {
+			return err
+		}
+		defer truncated.Close()
+		fmt.Fprintf(truncated, "%d of %d embeddings kept\n", len(embs), t.EmbeddingCount())
+	}
 	for i, emb := range embs {
 		edir := filepath.Join(dir, fmt.Sprintf("%d", i))
 		err := os.MkdirAll(edir, 0775)
@@ -0,0 +1,568 @@
+package reporters
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+)
+
+import (
+	"github.com/timtadh/regrax/lattice"
+	"github.com/timtadh/regrax/sample/miners"
+	"github.com/timtadh/regrax/types/digraph"
+	"github.com/timtadh/regrax/types/itemset"
+)
+
+// Filter only passes the nodes which satisfy a predicate to the inner
+// reporter. The predicate is an expression over the node, eg.
+//
+//	support >= 50 && vertices >= 4 && has_label("^java/io")
+//
+// See FilterUsage for the variables and functions.
+type Filter struct {
+	Reporter miners.Reporter
+	expr     string
+	pred     filterExpr
+	passed   int
+	dropped  int
+}
+
+const FilterUsage = `        variables
+            support     the support of the node
+            embeddings  the number of embeddings (transactions for itemsets)
+            coverage    the number of distinct vertices covered by the
+                        embeddings (transactions for itemsets)
+            size        the level of the pattern in the lattice
+            vertices    the number of vertices (digraph)
+            edges       the number of edges (digraph)
+            items       the number of items (itemset)
+
+        functions
+            has_label(<regex>)        a vertex or edge label (or item name)
+                                      matches the regex
+            has_vertex_label(<regex>) a vertex label matches (digraph)
+            has_edge_label(<regex>)   an edge label matches (digraph)
+
+        operators (loosest to tightest)
+            ||   &&   !   == != < <= > >=   + -   * /   unary -
+
+        Numbers, "strings", true, false and parentheses may be used.`
+
+func NewFilter(expr string, rptr miners.Reporter) (*Filter, error) {
+	pred, err := parseFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	r := &Filter{
+		Reporter: rptr,
+		expr:     expr,
+		pred:     pred,
+	}
+	return r, nil
+}
+
+func (r *Filter) Report(n lattice.Node) error {
+	ok, err := r.Matches(n)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		r.dropped++
+		return nil
+	}
+	r.passed++
+	return r.Reporter.Report(n)
+}
+
+// Matches evaluates the predicate against the node.
+func (r *Filter) Matches(n lattice.Node) (bool, error) {
+	v, err := r.pred(&filterEnv{node: n})
+	if err != nil {
+		return false, errors.Errorf("filter %q: %v", r.expr, err)
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, errors.Errorf("filter %q: evaluated to %v not a boolean", r.expr, v)
+	}
+	return b, nil
+}
+
+func (r *Filter) Close() error {
+	errors.Logf("INFO", "filter %q passed %v dropped %v", r.expr, r.passed, r.dropped)
+	return r.Reporter.Close()
+}
+
+var filterVariables = map[string]bool{
+	"support":    true,
+	"embeddings": true,
+	"coverage":   true,
+	"size":       true,
+	"vertices":   true,
+	"edges":      true,
+	"items":      true,
+}
+
+// filterEnv is the node a predicate is evaluated against.
+type filterEnv struct {
+	node lattice.Node
+}
+
+func (env *filterEnv) variable(name string) (interface{}, error) {
+	switch name {
+	case "support":
+		if s, ok := env.node.(supported); ok {
+			return float64(s.Support()), nil
+		}
+		return env.variable("embeddings")
+	case "embeddings":
+		switch n := env.node.(type) {
+		case *digraph.EmbListNode:
+			embs, err := n.Embeddings()
+			if err != nil {
+				return nil, err
+			}
+			return float64(len(embs)), nil
+		case *itemset.Node:
+			return float64(n.Support()), nil
+		}
+	case "coverage":
+		switch n := env.node.(type) {
+		case *digraph.EmbListNode:
+			embs, err := n.Embeddings()
+			if err != nil {
+				return nil, err
+			}
+			covered := make(map[int]bool)
+			for _, emb := range embs {
+				for _, id := range emb.Ids {
+					covered[id] = true
+				}
+			}
+			return float64(len(covered)), nil
+		case *itemset.Node:
+			return float64(n.Support()), nil
+		}
+	case "size":
+		return float64(env.node.Pattern().Level()), nil
+	case "vertices", "edges":
+		if _, ok := env.node.(*digraph.EmbListNode); ok {
+			V, E := digraph.VE(env.node)
+			if name == "vertices" {
+				return float64(V), nil
+			}
+			return float64(E), nil
+		}
+	case "items":
+		if n, ok := env.node.(*itemset.Node); ok {
+			return float64(len(n.ItemNames())), nil
+		}
+	default:
+		return nil, errors.Errorf("unknown variable %v", name)
+	}
+	return nil, errors.Errorf("%v is not defined for %T", name, env.node)
+}
+
+// labels returns the vertex and/or edge labels of the node (item names for
+// itemsets).
+func (env *filterEnv) labels(vertices, edges bool) ([]string, error) {
+	switch n := env.node.(type) {
+	case *digraph.EmbListNode:
+		labels := make([]string, 0, len(n.Pat.V)+len(n.Pat.E))
+		if vertices {
+			for i := range n.Pat.V {
+				labels = append(labels, n.Dt.Labels.Label(n.Pat.V[i].Color))
+			}
+		}
+		if edges {
+			for i := range n.Pat.E {
+				labels = append(labels, n.Dt.Labels.Label(n.Pat.E[i].Color))
+			}
+		}
+		return labels, nil
+	case *itemset.Node:
+		if vertices && edges {
+			return n.ItemNames(), nil
+		}
+	}
+	return nil, errors.Errorf("vertex and edge labels are not defined for %T", env.node)
+}
+
+type filterExpr func(env *filterEnv) (interface{}, error)
+
+type filterToken struct {
+	kind string // num, str, ident, op, eof
+	text string
+	pos  int
+}
+
+func lexFilter(expr string) ([]filterToken, error) {
+	toks := make([]filterToken, 0, 10)
+	ops := []string{"||", "&&", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "(", ")"}
+	i := 0
+outer:
+	for i < len(expr) {
+		c := rune(expr[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c) || c == '.':
+			j := i
+			for j < len(expr) && (unicode.IsDigit(rune(expr[j])) || expr[j] == '.') {
+				j++
+			}
+			toks = append(toks, filterToken{"num", expr[i:j], i})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i
+			for j < len(expr) && (unicode.IsLetter(rune(expr[j])) || unicode.IsDigit(rune(expr[j])) || expr[j] == '_') {
+				j++
+			}
+			toks = append(toks, filterToken{"ident", expr[i:j], i})
+			i = j
+		case c == '"':
+			j := i + 1
+			for j < len(expr) && expr[j] != '"' {
+				if expr[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(expr) {
+				return nil, errors.Errorf("unterminated string at %v", i)
+			}
+			s, err := strconv.Unquote(expr[i : j+1])
+			if err != nil {
+				return nil, errors.Errorf("bad string at %v: %v", i, err)
+			}
+			toks = append(toks, filterToken{"str", s, i})
+			i = j + 1
+		default:
+			for _, op := range ops {
+				if strings.HasPrefix(expr[i:], op) {
+					toks = append(toks, filterToken{"op", op, i})
+					i += len(op)
+					continue outer
+				}
+			}
+			return nil, errors.Errorf("unexpected %q at %v", c, i)
+		}
+	}
+	toks = append(toks, filterToken{"eof", "", len(expr)})
+	return toks, nil
+}
+
+type filterParser struct {
+	toks []filterToken
+	i    int
+}
+
+func parseFilter(expr string) (filterExpr, error) {
+	toks, err := lexFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{toks: toks}
+	e, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != "eof" {
+		return nil, errors.Errorf("unexpected %q at %v", t.text, t.pos)
+	}
+	return e, nil
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.toks[p.i]
+}
+
+func (p *filterParser) accept(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != "op" {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			p.i++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *filterParser) expect(op string) error {
+	if _, ok := p.accept(op); !ok {
+		t := p.peek()
+		return errors.Errorf("expected %q at %v got %q", op, t.pos, t.text)
+	}
+	return nil
+}
+
+// binary parses a left associative chain of the operators over next.
+func (p *filterParser) binary(next func() (filterExpr, error), ops ...string) (filterExpr, error) {
+	left, err := next()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept(ops...)
+		if !ok {
+			return left, nil
+		}
+		right, err := next()
+		if err != nil {
+			return nil, err
+		}
+		left = filterBinary(op, left, right)
+	}
+}
+
+func (p *filterParser) or() (filterExpr, error) {
+	return p.binary(p.and, "||")
+}
+
+func (p *filterParser) and() (filterExpr, error) {
+	return p.binary(p.not, "&&")
+}
+
+func (p *filterParser) not() (filterExpr, error) {
+	if _, ok := p.accept("!"); ok {
+		e, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return func(env *filterEnv) (interface{}, error) {
+			b, err := filterBool(e, env)
+			if err != nil {
+				return nil, err
+			}
+			return !b, nil
+		}, nil
+	}
+	return p.cmp()
+}
+
+func (p *filterParser) cmp() (filterExpr, error) {
+	left, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.accept("==", "!=", "<=", ">=", "<", ">")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	return filterBinary(op, left, right), nil
+}
+
+func (p *filterParser) sum() (filterExpr, error) {
+	return p.binary(p.product, "+", "-")
+}
+
+func (p *filterParser) product() (filterExpr, error) {
+	return p.binary(p.unary, "*", "/")
+}
+
+func (p *filterParser) unary() (filterExpr, error) {
+	if _, ok := p.accept("-"); ok {
+		e, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(env *filterEnv) (interface{}, error) {
+			f, err := filterNum(e, env)
+			if err != nil {
+				return nil, err
+			}
+			return -f, nil
+		}, nil
+	}
+	return p.primary()
+}
+
+func (p *filterParser) primary() (filterExpr, error) {
+	t := p.peek()
+	p.i++
+	switch t.kind {
+	case "num":
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, errors.Errorf("bad number %q at %v", t.text, t.pos)
+		}
+		return filterConst(f), nil
+	case "str":
+		return filterConst(t.text), nil
+	case "ident":
+		switch t.text {
+		case "true":
+			return filterConst(true), nil
+		case "false":
+			return filterConst(false), nil
+		}
+		if _, ok := p.accept("("); ok {
+			return p.call(t)
+		}
+		name := t.text
+		if !filterVariables[name] {
+			return nil, errors.Errorf("unknown variable %v at %v", name, t.pos)
+		}
+		return func(env *filterEnv) (interface{}, error) {
+			return env.variable(name)
+		}, nil
+	case "op":
+		if t.text == "(" {
+			e, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			return e, p.expect(")")
+		}
+	}
+	if t.kind == "eof" {
+		return nil, errors.Errorf("unexpected end of expression")
+	}
+	return nil, errors.Errorf("unexpected %q at %v", t.text, t.pos)
+}
+
+// call parses the arguments of a function call (the open paren has been
+// consumed). The label functions take a single regex string literal which
+// is compiled once here.
+func (p *filterParser) call(fn filterToken) (filterExpr, error) {
+	var vertices, edges bool
+	switch fn.text {
+	case "has_label":
+		vertices, edges = true, true
+	case "has_vertex_label":
+		vertices = true
+	case "has_edge_label":
+		edges = true
+	default:
+		return nil, errors.Errorf("unknown function %v at %v", fn.text, fn.pos)
+	}
+	arg := p.peek()
+	if arg.kind != "str" {
+		return nil, errors.Errorf("%v expects a regex string at %v", fn.text, arg.pos)
+	}
+	p.i++
+	re, err := regexp.Compile(arg.text)
+	if err != nil {
+		return nil, errors.Errorf("bad regex for %v at %v: %v", fn.text, arg.pos, err)
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return func(env *filterEnv) (interface{}, error) {
+		labels, err := env.labels(vertices, edges)
+		if err != nil {
+			return nil, err
+		}
+		for _, label := range labels {
+			if re.MatchString(label) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}, nil
+}
+
+func filterConst(v interface{}) filterExpr {
+	return func(*filterEnv) (interface{}, error) {
+		return v, nil
+	}
+}
+
+func filterBool(e filterExpr, env *filterEnv) (bool, error) {
+	v, err := e(env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, errors.Errorf("expected a boolean got %v", v)
+	}
+	return b, nil
+}
+
+func filterNum(e filterExpr, env *filterEnv) (float64, error) {
+	v, err := e(env)
+	if err != nil {
+		return 0, err
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return 0, errors.Errorf("expected a number got %v", v)
+	}
+	return f, nil
+}
+
+func filterBinary(op string, left, right filterExpr) filterExpr {
+	switch op {
+	case "||", "&&":
+		return func(env *filterEnv) (interface{}, error) {
+			a, err := filterBool(left, env)
+			if err != nil {
+				return nil, err
+			}
+			if (op == "||") == a {
+				return a, nil
+			}
+			return filterBool(right, env)
+		}
+	case "==", "!=":
+		return func(env *filterEnv) (interface{}, error) {
+			a, err := left(env)
+			if err != nil {
+				return nil, err
+			}
+			b, err := right(env)
+			if err != nil {
+				return nil, err
+			}
+			if fmt.Sprintf("%T", a) != fmt.Sprintf("%T", b) {
+				return nil, errors.Errorf("cannot compare %v %v %v", a, op, b)
+			}
+			return (a == b) == (op == "=="), nil
+		}
+	}
+	return func(env *filterEnv) (interface{}, error) {
+		a, err := filterNum(left, env)
+		if err != nil {
+			return nil, err
+		}
+		b, err := filterNum(right, env)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "<":
+			return a < b, nil
+		case "<=":
+			return a <= b, nil
+		case ">":
+			return a > b, nil
+		case ">=":
+			return a >= b, nil
+		case "+":
+			return a + b, nil
+		case "-":
+			return a - b, nil
+		case "*":
+			return a * b, nil
+		case "/":
+			if b == 0 {
+				return nil, errors.Errorf("division by zero")
+			}
+			return a / b, nil
+		}
+		return nil, errors.Errorf("unknown operator %v", op)
+	}
+}
@@ -0,0 +1,203 @@
+package reporters
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+import (
+	"io"
+	"sort"
+	"strings"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/lattice"
+	"github.com/timtadh/regrax/types/digraph"
+	"github.com/timtadh/regrax/types/itemset"
+)
+
+type stubPattern struct {
+	lattice.Pattern
+	level int
+}
+
+func (p *stubPattern) Level() int {
+	return p.level
+}
+
+type stubNode struct {
+	lattice.Node
+	support int
+	level   int
+}
+
+func (n *stubNode) Support() int {
+	return n.support
+}
+
+func (n *stubNode) Pattern() lattice.Pattern {
+	return &stubPattern{level: n.level}
+}
+
+func TestFilterExpressions(t *testing.T) {
+	x := assert.New(t)
+	n := &stubNode{support: 50, level: 3}
+	cases := map[string]bool{
+		"support >= 50":                    true,
+		"support > 50":                     false,
+		"support >= 50 && size < 3":        false,
+		"support >= 50 || size < 3":        true,
+		"!(size == 3)":                     false,
+		"support / 2 - 5 == 20":            true,
+		"-size + 1 * 2 < 0":                true,
+		"support != 10 && (false || true)": true,
+		"\"a\" == \"a\" && \"a\" != \"b\"": true,
+	}
+	for expr, expected := range cases {
+		f, err := NewFilter(expr, nil)
+		if err != nil {
+			t.Fatal(expr, err)
+		}
+		ok, err := f.Matches(n)
+		if err != nil {
+			t.Fatal(expr, err)
+		}
+		x.Equal(expected, ok, expr)
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	x := assert.New(t)
+	for _, expr := range []string{
+		"support >=",
+		"(support > 1",
+		"nope > 1",
+		"has_label(1)",
+		"has_label(\"(\")",
+		"unknown(\"a\")",
+		"support > 1 2",
+		"\"open",
+	} {
+		_, err := NewFilter(expr, nil)
+		x.Error(err, expr)
+	}
+	n := &stubNode{support: 50, level: 3}
+	for _, expr := range []string{
+		"support",
+		"support > true",
+		"vertices > 1",
+		"has_label(\"a\")",
+		"support / 0 > 1",
+	} {
+		f, err := NewFilter(expr, nil)
+		if err != nil {
+			t.Fatal(expr, err)
+		}
+		_, err = f.Matches(n)
+		x.Error(err, expr)
+	}
+}
+
+// filtered reports the nodes through the filter and gives the names of the
+// ones which passed.
+func filtered(t *testing.T, expr string, nodes []lattice.Node, name func(lattice.Node) string) []string {
+	c := &Collector{}
+	f, err := NewFilter(expr, c)
+	if err != nil {
+		t.Fatal(expr, err)
+	}
+	for _, n := range nodes {
+		err := f.Report(n)
+		if err != nil {
+			t.Fatal(expr, err)
+		}
+	}
+	names := make([]string, 0, len(c.Nodes))
+	for _, n := range c.Nodes {
+		names = append(names, name(n))
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestFilterDigraph(t *testing.T) {
+	x := assert.New(t)
+	dt := starDigraph(t, &config.Config{Support: 1})
+	defer dt.Close()
+	nodes, err := digraph.RootEmbListNode(dt).Children()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes = append(nodes, edgeNode(t, dt))
+	x.Equal(4, len(nodes))
+	name := func(n lattice.Node) string {
+		return n.(*digraph.EmbListNode).Pat.Pretty(dt.Labels)
+	}
+	edge := "{1:2}(a)(b)[0->1:e]"
+	cases := map[string][]string{
+		"vertices == 2 && edges == 1":        {edge},
+		"size == 2":                          {edge},
+		"has_label(\"^c$\")":                 {"{0:1}(c)"},
+		"has_label(\"^e\")":                  {edge},
+		"has_vertex_label(\"^b$\")":          {"{0:1}(b)", edge},
+		"has_edge_label(\"e\")":              {edge},
+		"!has_vertex_label(\"^[ab]$\")":      {"{0:1}(c)"},
+		"embeddings == 1 && coverage == 2":   {edge},
+		"support >= 1 && has_label(\"^a$\")": {"{0:1}(a)", edge},
+	}
+	for expr, expected := range cases {
+		x.Equal(expected, filtered(t, expr, nodes, name), expr)
+	}
+	f, err := NewFilter("items > 0", &Collector{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.Matches(nodes[3])
+	x.Error(err)
+}
+
+func TestFilterItemset(t *testing.T) {
+	x := assert.New(t)
+	l, err := itemset.NewLabeledLoader(&config.Config{Support: 2}, 0, 10, "", -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := l.Load(func() (io.Reader, func()) {
+		return strings.NewReader("bread milk\nbread milk\nbread\nmilk eggs\n"), func() {}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer data.Close()
+	dt := data.(*itemset.ItemSets)
+	nodes := append([]lattice.Node{}, dt.FrequentItems...)
+	kids, err := nodes[0].Children()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes = append(nodes, kids...)
+	x.Equal(3, len(nodes))
+	name := func(n lattice.Node) string {
+		return strings.Join(n.(*itemset.Node).ItemNames(), " ")
+	}
+	cases := map[string][]string{
+		"items == 2":                         {"bread milk"},
+		"has_label(\"^bre\")":                {"bread", "bread milk"},
+		"embeddings == 3 && coverage == 3":   {"bread", "milk"},
+		"support == 2 && has_label(\"ilk\")": {"bread milk"},
+	}
+	for expr, expected := range cases {
+		x.Equal(expected, filtered(t, expr, nodes, name), expr)
+	}
+	for _, expr := range []string{
+		"has_vertex_label(\"bread\")",
+		"has_edge_label(\"bread\")",
+		"vertices > 0",
+	} {
+		f, err := NewFilter(expr, &Collector{})
+		if err != nil {
+			t.Fatal(expr, err)
+		}
+		x.Error(f.Report(nodes[0]), expr)
+	}
+}
@@ -0,0 +1,252 @@
+package reporters
+
+import (
+	"math"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/lattice"
+)
+
+// dendrogram is a node of the merge tree. Leaves are the reported patterns.
+type dendrogram struct {
+	Name     string        `json:"name,omitempty"`
+	Distance float64       `json:"distance"`
+	Size     int           `json:"size"`
+	Children []*dendrogram `json:"children,omitempty"`
+	node     *clusterNode
+}
+
+func (d *dendrogram) leaves(c cluster) cluster {
+	if d.node != nil {
+		return append(c, d.node)
+	}
+	for _, kid := range d.Children {
+		c = kid.leaves(c)
+	}
+	return c
+}
+
+// Hierarchical clusters the reported patterns agglomeratively (single,
+// complete or average linkage) under one of the dbscan metrics. It writes
+// the dendrogram, the clusters found by cutting it (into k clusters or at a
+// maximum distance) and their quality metrics.
+type Hierarchical struct {
+	config                                    *config.Config
+	fmtr                                      lattice.Formatter
+	nodes                                     []*clusterNode
+	clustersName, metricsName, dendrogramName string
+	attr                                      string
+	metricName                                string
+	metric                                    func(a, b *clusterNode) float64
+	linkage                                   string
+	k                                         int
+	maxDistance                               float64
+}
+
+func NewHierarchical(c *config.Config, fmtr lattice.Formatter, clusters, metrics, dendrogram, attr, metric, linkage string, k int, maxDistance float64) (*Hierarchical, error) {
+	m, err := clusterMetric(metric)
+	if err != nil {
+		return nil, err
+	}
+	if metric == "attr" && attr == "" {
+		return nil, errors.Errorf("the attr metric needs an attr")
+	}
+	switch linkage {
+	case "single", "complete", "average":
+	default:
+		return nil, errors.Errorf("unknown linkage '%v' (expected single, complete or average)", linkage)
+	}
+	r := &Hierarchical{
+		config:         c,
+		fmtr:           fmtr,
+		clustersName:   clusters,
+		metricsName:    metrics,
+		dendrogramName: dendrogram,
+		attr:           attr,
+		metricName:     metric,
+		metric:         m,
+		linkage:        linkage,
+		k:              k,
+		maxDistance:    maxDistance,
+	}
+	return r, nil
+}
+
+func (r *Hierarchical) Report(n lattice.Node) error {
+	cn, err := newClusterNode(r.fmtr, n, r.attr)
+	if err != nil {
+		return err
+	}
+	r.nodes = append(r.nodes, cn)
+	return nil
+}
+
+func (r *Hierarchical) Close() error {
+	d := distanceMatrix(r.nodes, r.metric)
+	// the quality metrics need the distances between the patterns so
+	// agglomerate gets a copy to overwrite
+	root := agglomerate(r.nodes, copyMatrix(d), r.linkage)
+	var clusters []cluster
+	if root != nil {
+		if r.k > 0 {
+			clusters = cutClusters(root, r.k)
+		} else {
+			clusters = cutDistance(root, r.maxDistance)
+		}
+	}
+	errors.Logf("INFO", "hierarchical: %v patterns in %v clusters", len(r.nodes), len(clusters))
+	if err := writeJSON(r.config, r.dendrogramName, root); err != nil {
+		return err
+	}
+	if err := writeClusters(r.config, r.clustersName, clusters, r.attr, nil); err != nil {
+		return err
+	}
+	metrics := qualityMetrics(r.metricName, memoized(r.nodes, d), r.attr)
+	x := clusterQuality(len(r.nodes), clusters, metrics)
+	x["linkage"] = r.linkage
+	return writeJSON(r.config, r.metricsName, x)
+}
+
+// agglomerate builds the dendrogram with the nearest neighbor chain
+// algorithm (all three linkages are reducible so it finds the same merges as
+// the naive algorithm in O(n^2)). d is overwritten with the distances
+// between the clusters.
+func agglomerate(nodes []*clusterNode, d [][]float64, linkage string) *dendrogram {
+	if len(nodes) == 0 {
+		return nil
+	}
+	trees := make([]*dendrogram, len(nodes))
+	active := make([]bool, len(nodes))
+	for i, n := range nodes {
+		trees[i] = &dendrogram{Name: n.name, Size: 1, node: n}
+		active[i] = true
+	}
+	chain := make([]int, 0, len(nodes))
+	for remaining := len(nodes); remaining > 1; {
+		if len(chain) == 0 {
+			for i := range active {
+				if active[i] {
+					chain = append(chain, i)
+					break
+				}
+			}
+		}
+		a := chain[len(chain)-1]
+		prev := -1
+		if len(chain) >= 2 {
+			prev = chain[len(chain)-2]
+		}
+		// the previous link wins ties so the chain cannot cycle
+		b := prev
+		min := math.Inf(1)
+		if prev >= 0 {
+			min = d[a][prev]
+		}
+		for i := range active {
+			if active[i] && i != a && d[a][i] < min {
+				b, min = i, d[a][i]
+			}
+		}
+		if b < 0 {
+			// every distance is NaN or infinite, merge with any cluster
+			for i := range active {
+				if active[i] && i != a {
+					b, min = i, d[a][i]
+					break
+				}
+			}
+		}
+		if b != prev {
+			chain = append(chain, b)
+			continue
+		}
+		chain = chain[:len(chain)-2]
+		// the merged cluster takes the lower slot so the leaves stay in the
+		// order the patterns were reported
+		lo, hi := a, b
+		if hi < lo {
+			lo, hi = hi, lo
+		}
+		na, nb := float64(trees[a].Size), float64(trees[b].Size)
+		for k := range active {
+			if !active[k] || k == a || k == b {
+				continue
+			}
+			var dist float64
+			switch linkage {
+			case "single":
+				dist = math.Min(d[a][k], d[b][k])
+			case "complete":
+				dist = math.Max(d[a][k], d[b][k])
+			default:
+				dist = (na*d[a][k] + nb*d[b][k]) / (na + nb)
+			}
+			d[lo][k] = dist
+			d[k][lo] = dist
+		}
+		trees[lo] = &dendrogram{
+			Distance: min,
+			Size:     trees[a].Size + trees[b].Size,
+			Children: []*dendrogram{trees[lo], trees[hi]},
+		}
+		trees[hi] = nil
+		active[hi] = false
+		remaining--
+	}
+	for i := range active {
+		if active[i] {
+			return trees[i]
+		}
+	}
+	return nil
+}
+
+// cutClusters splits the dendrogram at its highest merges into k clusters.
+func cutClusters(root *dendrogram, k int) []cluster {
+	frontier := []*dendrogram{root}
+	for len(frontier) < k {
+		split := -1
+		for i, t := range frontier {
+			if t.node == nil && (split < 0 || t.Distance > frontier[split].Distance) {
+				split = i
+			}
+		}
+		if split < 0 {
+			break
+		}
+		t := frontier[split]
+		frontier = append(frontier[:split], append(t.Children, frontier[split+1:]...)...)
+	}
+	return frontierClusters(frontier)
+}
+
+// cutDistance splits the dendrogram at the merges above the distance.
+func cutDistance(root *dendrogram, maxDistance float64) []cluster {
+	frontier := make([]*dendrogram, 0, 10)
+	var cut func(t *dendrogram)
+	cut = func(t *dendrogram) {
+		if t.node != nil || t.Distance <= maxDistance {
+			frontier = append(frontier, t)
+			return
+		}
+		for _, kid := range t.Children {
+			cut(kid)
+		}
+	}
+	cut(root)
+	return frontierClusters(frontier)
+}
+
+func frontierClusters(frontier []*dendrogram) []cluster {
+	clusters := make([]cluster, 0, len(frontier))
+	for _, t := range frontier {
+		clusters = append(clusters, t.leaves(make(cluster, 0, t.Size)))
+	}
+	return clusters
+}
@@ -0,0 +1,427 @@
+package reporters
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/lattice"
+	"github.com/timtadh/regrax/types/digraph"
+	dg "github.com/timtadh/regrax/types/digraph/digraph"
+	"github.com/timtadh/regrax/types/digraph/subgraph"
+)
+
+// supported nodes know their support (otherwise it is their embedding count).
+type supported interface {
+	Support() int
+}
+
+// htmlEntry is a row of the index page.
+type htmlEntry struct {
+	name    string
+	size    string
+	support int
+	pr      string
+}
+
+// HTML writes a static site: an index of the patterns and a page (or pages
+// when there are many embeddings) per pattern. Digraph patterns are drawn as
+// inline SVG and their embeddings are listed with the attributes of their
+// vertices. Nothing but the files in the directory is needed to browse it.
+type HTML struct {
+	config   *config.Config
+	fmtr     lattice.Formatter
+	prfmtr   lattice.PrFormatter
+	dir      string
+	pageSize int
+	entries  []htmlEntry
+}
+
+func NewHTML(c *config.Config, fmtr lattice.Formatter, showPr bool, dirname string, pageSize int) (*HTML, error) {
+	if pageSize <= 0 {
+		return nil, errors.Errorf("page size must be > 0 (got %v)", pageSize)
+	}
+	site := c.OutputFile(dirname)
+	err := os.MkdirAll(site, 0775)
+	if err != nil {
+		return nil, err
+	}
+	var prfmtr lattice.PrFormatter
+	if showPr {
+		prfmtr = fmtr.PrFormatter()
+	}
+	r := &HTML{
+		config:   c,
+		fmtr:     fmtr,
+		prfmtr:   prfmtr,
+		dir:      site,
+		pageSize: pageSize,
+	}
+	return r, nil
+}
+
+func (r *HTML) Report(n lattice.Node) error {
+	i := len(r.entries)
+	name := r.fmtr.PatternName(n)
+	var embs []string
+	var dembs []*subgraph.Embedding
+	dn, isDigraph := n.(*digraph.EmbListNode)
+	if isDigraph {
+		var err error
+		dembs, err = dn.Embeddings()
+		if err != nil {
+			return err
+		}
+	} else {
+		var err error
+		embs, err = r.fmtr.Embeddings(n)
+		if err != nil {
+			return err
+		}
+	}
+	count := len(embs) + len(dembs)
+	support := count
+	if s, ok := n.(supported); ok {
+		support = s.Support()
+	}
+	size := fmt.Sprintf("%d", n.Pattern().Level())
+	if isDigraph {
+		size = fmt.Sprintf("%d vertices, %d edges", len(dn.Pat.V), len(dn.Pat.E))
+	}
+	r.entries = append(r.entries, htmlEntry{
+		name:    name,
+		size:    size,
+		support: support,
+		pr:      r.selectionPr(n),
+	})
+	notes := make([]string, 0, 1)
+	if t, ok := n.(truncatable); ok && t.Truncated() {
+		notes = append(notes, fmt.Sprintf("%d of %d embeddings kept", count, t.EmbeddingCount()))
+	}
+	pages := (count + r.pageSize - 1) / r.pageSize
+	if pages == 0 {
+		pages = 1
+	}
+	for p := 0; p < pages; p++ {
+		err := r.writePage(i, p, pages, func(w io.Writer) error {
+			fmt.Fprintf(w, "<h1>%s</h1>\n", html.EscapeString(name))
+			fmt.Fprintf(w, "<p>size: %s, support: %d, selection probability: %s</p>\n",
+				html.EscapeString(size), support, html.EscapeString(r.entries[i].pr))
+			for _, note := range notes {
+				fmt.Fprintf(w, "<p><em>%s</em></p>\n", html.EscapeString(note))
+			}
+			s := p * r.pageSize
+			e := s + r.pageSize
+			if isDigraph {
+				fmt.Fprintf(w, "%s\n", svgPattern(dn.Pat, dn.Dt.Labels))
+				if e > len(dembs) {
+					e = len(dembs)
+				}
+				return htmlEmbeddings(w, dn.Dt, dembs[s:e], s)
+			}
+			if pat, err := r.fmtr.Pattern(n); err == nil {
+				fmt.Fprintf(w, "<pre>%s</pre>\n", html.EscapeString(pat))
+			} else {
+				return err
+			}
+			if e > len(embs) {
+				e = len(embs)
+			}
+			for j, emb := range embs[s:e] {
+				fmt.Fprintf(w, "<h3>embedding %d</h3>\n<pre>%s</pre>\n", s+j, html.EscapeString(emb))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectionPr computes the selection probability of n (as the dir reporter
+// does) when it was asked for and is computable.
+func (r *HTML) selectionPr(n lattice.Node) string {
+	if r.prfmtr == nil {
+		return "-"
+	}
+	matrices, err := r.prfmtr.Matrices(n)
+	if err != nil {
+		errors.Logf("ERROR", "Pr Matrices Computation Error: %v", err)
+		return "-"
+	}
+	if !r.prfmtr.CanComputeSelPr(n, matrices) {
+		return "-"
+	}
+	pr, err := r.prfmtr.SelectionProbability(n, matrices)
+	if err != nil {
+		errors.Logf("ERROR", "PrComputation Error: %v", err)
+		return "-"
+	}
+	return fmt.Sprintf("%g", pr)
+}
+
+func htmlPageName(pattern, page int) string {
+	if page == 0 {
+		return fmt.Sprintf("pattern-%d.html", pattern)
+	}
+	return fmt.Sprintf("pattern-%d-%d.html", pattern, page+1)
+}
+
+// writePage writes page p of pattern i with its navigation links around the
+// body.
+func (r *HTML) writePage(i, p, pages int, body func(io.Writer) error) error {
+	f, err := os.Create(filepath.Join(r.dir, htmlPageName(i, p)))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	htmlHeader(f, fmt.Sprintf("pattern %d", i))
+	nav := func() {
+		fmt.Fprintf(f, "<p class=\"nav\"><a href=\"index.html\">index</a>")
+		if pages > 1 {
+			fmt.Fprintf(f, " | embeddings page:")
+			for q := 0; q < pages; q++ {
+				if q == p {
+					fmt.Fprintf(f, " <strong>%d</strong>", q+1)
+				} else {
+					fmt.Fprintf(f, " <a href=\"%s\">%d</a>", htmlPageName(i, q), q+1)
+				}
+			}
+		}
+		fmt.Fprintf(f, "</p>\n")
+	}
+	nav()
+	err = body(f)
+	if err != nil {
+		return err
+	}
+	nav()
+	htmlFooter(f)
+	return nil
+}
+
+func (r *HTML) Close() error {
+	f, err := os.Create(filepath.Join(r.dir, "index.html"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	htmlHeader(f, "patterns")
+	fmt.Fprintf(f, "<h1>%d patterns</h1>\n", len(r.entries))
+	fmt.Fprintf(f, "<table>\n<tr><th>#</th><th>name</th><th>size</th><th>support</th><th>selection probability</th></tr>\n")
+	for i, e := range r.entries {
+		fmt.Fprintf(f, "<tr><td>%d</td><td><a href=\"%s\">%s</a></td><td>%s</td><td>%d</td><td>%s</td></tr>\n",
+			i, htmlPageName(i, 0), html.EscapeString(e.name), html.EscapeString(e.size), e.support, html.EscapeString(e.pr))
+	}
+	fmt.Fprintf(f, "</table>\n")
+	htmlFooter(f)
+	return nil
+}
+
+func htmlHeader(w io.Writer, title string) {
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #ccc; padding: .25em .5em; text-align: left; vertical-align: top; }
+td.attrs { font-size: small; }
+svg text { font-size: 12px; }
+</style>
+</head>
+<body>
+`, html.EscapeString(title))
+}
+
+func htmlFooter(w io.Writer) {
+	fmt.Fprintf(w, "</body>\n</html>\n")
+}
+
+// htmlEmbeddings writes a table with a row per embedding and a column per
+// vertex of the pattern showing the original id (oid) and the attributes of
+// the vertex of the graph it maps to.
+func htmlEmbeddings(w io.Writer, dt *digraph.Digraph, embs []*subgraph.Embedding, offset int) error {
+	if len(embs) == 0 {
+		fmt.Fprintf(w, "<p>no embeddings</p>\n")
+		return nil
+	}
+	fmt.Fprintf(w, "<table>\n<tr><th>#</th>")
+	for idx := range embs[0].SG.V {
+		fmt.Fprintf(w, "<th>v%d</th>", idx)
+	}
+	fmt.Fprintf(w, "</tr>\n")
+	for j, emb := range embs {
+		fmt.Fprintf(w, "<tr><td>%d</td>", offset+j)
+		for _, id := range emb.Ids {
+			attrs, err := vertexAttrs(dt, id)
+			if err != nil {
+				return err
+			}
+			oid := vertexOid(attrs, id)
+			names := make([]string, 0, len(attrs))
+			for name := range attrs {
+				if name != "oid" && name != "id" {
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
+			fmt.Fprintf(w, "<td class=\"attrs\"><strong>%s</strong>", html.EscapeString(fmt.Sprint(oid)))
+			for _, name := range names {
+				fmt.Fprintf(w, "<br>%s: %s", html.EscapeString(name), html.EscapeString(fmt.Sprint(attrs[name])))
+			}
+			fmt.Fprintf(w, "</td>")
+		}
+		fmt.Fprintf(w, "</tr>\n")
+	}
+	fmt.Fprintf(w, "</table>\n")
+	return nil
+}
+
+// svgPattern draws the pattern in layers by breadth first distance (ignoring
+// direction) from vertex 0. Parallel edges are drawn as arcs bending away
+// from each other and self-loops as loops above their vertex.
+func svgPattern(sg *subgraph.SubGraph, labels *dg.Labels) string {
+	const (
+		radius = 20.0
+		xgap   = 140.0
+		ygap   = 110.0
+		margin = 60.0
+	)
+	if len(sg.V) == 0 {
+		return "<svg width=\"0\" height=\"0\"></svg>"
+	}
+	layer := make([]int, len(sg.V))
+	for i := range layer {
+		layer[i] = -1
+	}
+	layers := make([][]int, 0, len(sg.V))
+	for start := range sg.V {
+		if layer[start] >= 0 {
+			continue
+		}
+		layer[start] = 0
+		queue := []int{start}
+		for len(queue) > 0 {
+			u := queue[0]
+			queue = queue[1:]
+			for len(layers) <= layer[u] {
+				layers = append(layers, nil)
+			}
+			layers[layer[u]] = append(layers[layer[u]], u)
+			for _, eidx := range sg.Adj[u] {
+				e := &sg.E[eidx]
+				v := e.Targ
+				if v == u {
+					v = e.Src
+				}
+				if layer[v] < 0 {
+					layer[v] = layer[u] + 1
+					queue = append(queue, v)
+				}
+			}
+		}
+	}
+	widest := 0
+	for _, l := range layers {
+		if len(l) > widest {
+			widest = len(l)
+		}
+	}
+	width := margin*2 + xgap*float64(widest-1)
+	height := margin*2 + ygap*float64(len(layers)-1)
+	X := make([]float64, len(sg.V))
+	Y := make([]float64, len(sg.V))
+	for l, vs := range layers {
+		off := (width - xgap*float64(len(vs)-1)) / 2
+		for k, v := range vs {
+			X[v] = off + xgap*float64(k)
+			Y[v] = margin + ygap*float64(l)
+		}
+	}
+
+	type pair struct{ a, b int }
+	between := make(map[pair]int)
+	for eidx := range sg.E {
+		e := &sg.E[eidx]
+		key := pair{e.Src, e.Targ}
+		if key.a > key.b {
+			key = pair{key.b, key.a}
+		}
+		between[key]++
+	}
+
+	var out []string
+	out = append(out, fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f">`, width, height))
+	out = append(out, `<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto"><path d="M0,0 L10,5 L0,10 z"/></marker></defs>`)
+	edgeLabel := func(e *subgraph.Edge) string {
+		label := labels.Label(e.Color)
+		if e.Time > 0 {
+			label = fmt.Sprintf("%v@%v", label, e.Time)
+		}
+		return html.EscapeString(label)
+	}
+	seen := make(map[pair]int)
+	for eidx := range sg.E {
+		e := &sg.E[eidx]
+		key := pair{e.Src, e.Targ}
+		if key.a > key.b {
+			key = pair{key.b, key.a}
+		}
+		k := seen[key]
+		seen[key]++
+		if key.a == key.b {
+			x, y := X[e.Src], Y[e.Src]
+			h := radius*2 + float64(k)*15
+			out = append(out, fmt.Sprintf(
+				`<path d="M%.1f,%.1f C%.1f,%.1f %.1f,%.1f %.1f,%.1f" fill="none" stroke="black" marker-end="url(#arrow)"/>`,
+				x-radius*0.6, y-radius*0.8, x-radius, y-h-radius, x+radius, y-h-radius, x+radius*0.6, y-radius*0.8))
+			out = append(out, fmt.Sprintf(`<text x="%.1f" y="%.1f" text-anchor="middle">%s</text>`, x, y-h-radius*0.3, edgeLabel(e)))
+			continue
+		}
+		x1, y1, x2, y2 := X[e.Src], Y[e.Src], X[e.Targ], Y[e.Targ]
+		dx, dy := x2-x1, y2-y1
+		d := math.Hypot(dx, dy)
+		ux, uy := dx/d, dy/d
+		// the bend is measured from the lower indexed vertex so edges in
+		// opposite directions between the same pair do not overlap.
+		bend := (float64(k) - float64(between[key]-1)/2) * 40
+		if e.Src != key.a {
+			bend = -bend
+		}
+		mx, my := (x1+x2)/2-uy*bend, (y1+y2)/2+ux*bend
+		sx, sy := x1+ux*radius, y1+uy*radius
+		tx, ty := x2-ux*radius, y2-uy*radius
+		out = append(out, fmt.Sprintf(
+			`<path d="M%.1f,%.1f Q%.1f,%.1f %.1f,%.1f" fill="none" stroke="black" marker-end="url(#arrow)"/>`,
+			sx, sy, mx, my, tx, ty))
+		lx, ly := (x1+x2)/4+mx/2, (y1+y2)/4+my/2
+		out = append(out, fmt.Sprintf(`<text x="%.1f" y="%.1f" text-anchor="middle">%s</text>`, lx, ly-4, edgeLabel(e)))
+	}
+	for idx := range sg.V {
+		out = append(out, fmt.Sprintf(
+			`<circle cx="%.1f" cy="%.1f" r="%.0f" fill="white" stroke="black"/>`, X[idx], Y[idx], radius))
+		out = append(out, fmt.Sprintf(
+			`<text x="%.1f" y="%.1f" text-anchor="middle" dominant-baseline="middle">%s</text>`,
+			X[idx], Y[idx], html.EscapeString(labels.Label(sg.V[idx].Color))))
+		out = append(out, fmt.Sprintf(
+			`<text x="%.1f" y="%.1f" text-anchor="middle" fill="gray">v%d</text>`, X[idx], Y[idx]+radius+14, idx))
+	}
+	out = append(out, "</svg>")
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,99 @@
+package reporters
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+import (
+	"github.com/timtadh/regrax/types/digraph"
+	dg "github.com/timtadh/regrax/types/digraph/digraph"
+	"github.com/timtadh/regrax/types/digraph/subgraph"
+)
+
+var svgPaths = regexp.MustCompile(`<path d="(M[^"]*)" fill="none"`)
+
+func TestSvgPattern(t *testing.T) {
+	x := assert.New(t)
+	labels := dg.NewLabels()
+	// a -> b twice (parallel), b -> a, a -> a
+	b := subgraph.Build(2, 4)
+	u := b.AddVertex(labels.Color("a"))
+	v := b.AddVertex(labels.Color("b"))
+	b.AddEdge(u, v, labels.Color("call"))
+	b.AddEdge(u, v, labels.Color("jump"))
+	b.AddEdge(v, u, labels.Color("ret"))
+	b.AddEdge(u, u, labels.Color("loop"))
+	svg := svgPattern(b.Build(), labels)
+	x.True(strings.HasPrefix(svg, "<svg "))
+	x.True(strings.HasSuffix(svg, "</svg>"))
+	x.Equal(2, strings.Count(svg, "<circle "))
+	for _, label := range []string{"a", "b", "call", "jump", "ret", "loop", "v0", "v1"} {
+		x.Contains(svg, ">"+label+"</text>")
+	}
+	// every edge is drawn and no two of them overlap
+	paths := svgPaths.FindAllStringSubmatch(svg, -1)
+	x.Equal(4, len(paths))
+	drawn := make(map[string]bool)
+	for _, p := range paths {
+		x.False(drawn[p[1]], "%v drawn twice", p[1])
+		drawn[p[1]] = true
+	}
+	// the self-loop is a cubic curve, the others quadratic
+	x.Equal(1, strings.Count(svg, " C"))
+	x.Equal(3, strings.Count(svg, " Q"))
+}
+
+func TestSvgPatternEmpty(t *testing.T) {
+	x := assert.New(t)
+	x.Equal(`<svg width="0" height="0"></svg>`, svgPattern(subgraph.EmptySubGraph(), dg.NewLabels()))
+}
+
+func TestSvgPatternTime(t *testing.T) {
+	x := assert.New(t)
+	labels := dg.NewLabels()
+	b := subgraph.Build(2, 1)
+	b.AddEdge(b.AddVertex(labels.Color("a")), b.AddVertex(labels.Color("b")), labels.Color("e"))
+	b.E[0].Time = 2
+	x.Contains(svgPattern(b.Build(), labels), ">e@2</text>")
+}
+
+func TestHTMLEscapesLabels(t *testing.T) {
+	x := assert.New(t)
+	labels := dg.NewLabels()
+	b := subgraph.Build(2, 1)
+	b.AddEdge(b.AddVertex(labels.Color(`<script>alert("a")</script>`)), b.AddVertex(labels.Color("b & c")), labels.Color("x<y"))
+	svg := svgPattern(b.Build(), labels)
+	x.NotContains(svg, "<script>")
+	x.Contains(svg, "&lt;script&gt;alert(&#34;a&#34;)&lt;/script&gt;")
+	x.Contains(svg, ">b &amp; c</text>")
+	x.Contains(svg, ">x&lt;y</text>")
+	var buf bytes.Buffer
+	htmlHeader(&buf, "<b>")
+	x.Contains(buf.String(), "<title>&lt;b&gt;</title>")
+}
+
+func TestHTMLEmbeddings(t *testing.T) {
+	x := assert.New(t)
+	b := subgraph.Build(2, 1)
+	b.AddEdge(b.AddVertex(0), b.AddVertex(1), 2)
+	sg := b.Build()
+	embs := []*subgraph.Embedding{
+		{SG: sg, Ids: []int{4, 5}},
+		{SG: sg, Ids: []int{6, 7}},
+	}
+	// without attributes the vertices are shown by their ids
+	var buf bytes.Buffer
+	x.Nil(htmlEmbeddings(&buf, &digraph.Digraph{}, embs, 10))
+	out := buf.String()
+	x.Contains(out, "<th>v0</th><th>v1</th>")
+	x.Contains(out, "<tr><td>10</td><td class=\"attrs\"><strong>4</strong></td><td class=\"attrs\"><strong>5</strong></td></tr>")
+	x.Contains(out, "<tr><td>11</td>")
+	buf.Reset()
+	x.Nil(htmlEmbeddings(&buf, &digraph.Digraph{}, nil, 0))
+	x.Equal("<p>no embeddings</p>\n", buf.String())
+}
@@ -0,0 +1,198 @@
+package reporters
+
+import (
+	"math"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/lattice"
+)
+
+// KMedoids partitions the reported patterns into k clusters around k of the
+// patterns (the medoids) under one of the dbscan metrics. The medoids are
+// initialized greedily (as in the BUILD step of PAM) and then improved by
+// alternately assigning each pattern to its nearest medoid and moving each
+// medoid to the member of its cluster nearest the rest. It writes the
+// clusters (marking the medoids) and their quality metrics.
+type KMedoids struct {
+	config                    *config.Config
+	fmtr                      lattice.Formatter
+	nodes                     []*clusterNode
+	clustersName, metricsName string
+	attr                      string
+	metricName                string
+	metric                    func(a, b *clusterNode) float64
+	k                         int
+	maxIterations             int
+}
+
+func NewKMedoids(c *config.Config, fmtr lattice.Formatter, clusters, metrics, attr, metric string, k, maxIterations int) (*KMedoids, error) {
+	m, err := clusterMetric(metric)
+	if err != nil {
+		return nil, err
+	}
+	if metric == "attr" && attr == "" {
+		return nil, errors.Errorf("the attr metric needs an attr")
+	}
+	if k <= 0 {
+		return nil, errors.Errorf("k-medoids needs k > 0 (got %v)", k)
+	}
+	r := &KMedoids{
+		config:        c,
+		fmtr:          fmtr,
+		clustersName:  clusters,
+		metricsName:   metrics,
+		attr:          attr,
+		metricName:    metric,
+		metric:        m,
+		k:             k,
+		maxIterations: maxIterations,
+	}
+	return r, nil
+}
+
+func (r *KMedoids) Report(n lattice.Node) error {
+	cn, err := newClusterNode(r.fmtr, n, r.attr)
+	if err != nil {
+		return err
+	}
+	r.nodes = append(r.nodes, cn)
+	return nil
+}
+
+func (r *KMedoids) Close() error {
+	d := distanceMatrix(r.nodes, r.metric)
+	medoids, assignment, iterations := kmedoids(d, r.k, r.maxIterations)
+	clusters := make([]cluster, len(medoids))
+	isMedoid := make(map[*clusterNode]bool, len(medoids))
+	for i, m := range medoids {
+		isMedoid[r.nodes[m]] = true
+		clusters[i] = cluster{r.nodes[m]}
+	}
+	for i, c := range assignment {
+		if medoids[c] != i {
+			clusters[c] = append(clusters[c], r.nodes[i])
+		}
+	}
+	errors.Logf("INFO", "k-medoids: %v patterns in %v clusters after %v iterations", len(r.nodes), len(clusters), iterations)
+	err := writeClusters(r.config, r.clustersName, clusters, r.attr, func(i int, cn *clusterNode, x map[string]interface{}) {
+		x["medoid"] = isMedoid[cn]
+	})
+	if err != nil {
+		return err
+	}
+	metrics := qualityMetrics(r.metricName, memoized(r.nodes, d), r.attr)
+	x := clusterQuality(len(r.nodes), clusters, metrics)
+	x["iterations"] = iterations
+	x["cost"] = noNan(medoidCost(d, medoids, assignment))
+	return writeJSON(r.config, r.metricsName, x)
+}
+
+// kmedoids chooses min(k, n) medoids of the points with the distances d and
+// assigns every point to the index of its nearest medoid.
+func kmedoids(d [][]float64, k, maxIterations int) (medoids, assignment []int, iterations int) {
+	if k > len(d) {
+		k = len(d)
+	}
+	medoids = buildMedoids(d, k)
+	assignment = make([]int, len(d))
+	for iterations < maxIterations || maxIterations <= 0 {
+		iterations++
+		assign(d, medoids, assignment)
+		changed := false
+		for c := range medoids {
+			best := medoids[c]
+			bestCost := math.Inf(1)
+			for i, ci := range assignment {
+				if ci != c {
+					continue
+				}
+				var cost float64
+				for j, cj := range assignment {
+					if cj == c {
+						cost += d[i][j]
+					}
+				}
+				if cost < bestCost || (cost == bestCost && i == medoids[c]) {
+					best, bestCost = i, cost
+				}
+			}
+			if best != medoids[c] {
+				medoids[c] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	assign(d, medoids, assignment)
+	return medoids, assignment, iterations
+}
+
+// buildMedoids starts from the point nearest all the others and then adds
+// the point which most reduces the distances to the nearest medoid.
+func buildMedoids(d [][]float64, k int) []int {
+	medoids := make([]int, 0, k)
+	chosen := make([]bool, len(d))
+	nearest := make([]float64, len(d))
+	for i := range nearest {
+		nearest[i] = math.Inf(1)
+	}
+	for len(medoids) < k {
+		best := -1
+		bestGain := math.Inf(-1)
+		for i := range d {
+			if chosen[i] {
+				continue
+			}
+			var gain float64
+			for j := range d {
+				if len(medoids) == 0 {
+					gain -= d[i][j]
+				} else if d[i][j] < nearest[j] {
+					gain += nearest[j] - d[i][j]
+				}
+			}
+			if best < 0 || gain > bestGain {
+				best, bestGain = i, gain
+			}
+		}
+		medoids = append(medoids, best)
+		chosen[best] = true
+		for j := range d {
+			nearest[j] = math.Min(nearest[j], d[best][j])
+		}
+	}
+	return medoids
+}
+
+func assign(d [][]float64, medoids, assignment []int) {
+	for i := range assignment {
+		best := 0
+		for c, m := range medoids {
+			if m == i {
+				best = c
+				break
+			}
+			if d[i][m] < d[i][medoids[best]] {
+				best = c
+			}
+		}
+		assignment[i] = best
+	}
+}
+
+// medoidCost is the sum of the distances to the assigned medoids.
+func medoidCost(d [][]float64, medoids, assignment []int) float64 {
+	var cost float64
+	for i, c := range assignment {
+		cost += d[i][medoids[c]]
+	}
+	return cost
+}
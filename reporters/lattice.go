@@ -0,0 +1,185 @@
+package reporters
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/lattice"
+)
+
+// latticeNode is a reported node of the explored lattice.
+type latticeNode struct {
+	name     string
+	level    int
+	support  int
+	hasSupp  bool
+	reported int
+}
+
+// LatticeExport records the reported nodes and the parent/child edges among
+// them and writes the explored part of the lattice (as dot or GraphML) when
+// it is closed. Only the parents of the reported nodes are looked at (the
+// children of a node can be far more expensive to compute): an edge is
+// added when the second of its two nodes is reported. Nodes which are
+// reported more than once (eg. with --non-unique) are counted.
+type LatticeExport struct {
+	config   *config.Config
+	fmtr     lattice.Formatter
+	filename string
+	format   string
+	nodes    []*latticeNode
+	idx      map[string]int
+	edges    []lattice.Edge
+	hasEdge  map[lattice.Edge]bool
+	waiting  map[string][]int // the reported kids of unreported parents
+}
+
+func NewLatticeExport(c *config.Config, fmtr lattice.Formatter, filename, format string) (*LatticeExport, error) {
+	if format != "dot" && format != "graphml" {
+		return nil, errors.Errorf("unknown lattice format '%v' (expected dot or graphml)", format)
+	}
+	if !strings.HasSuffix(filename, "."+format) {
+		filename = filename + "." + format
+	}
+	r := &LatticeExport{
+		config:   c,
+		fmtr:     fmtr,
+		filename: filename,
+		format:   format,
+		idx:      make(map[string]int),
+		hasEdge:  make(map[lattice.Edge]bool),
+		waiting:  make(map[string][]int),
+	}
+	return r, nil
+}
+
+func (r *LatticeExport) Report(n lattice.Node) error {
+	label := string(n.Pattern().Label())
+	if i, has := r.idx[label]; has {
+		r.nodes[i].reported++
+		return nil
+	}
+	i := len(r.nodes)
+	ln := &latticeNode{
+		name:     r.fmtr.PatternName(n),
+		level:    n.Pattern().Level(),
+		reported: 1,
+	}
+	if s, ok := n.(supported); ok {
+		ln.support = s.Support()
+		ln.hasSupp = true
+	}
+	r.nodes = append(r.nodes, ln)
+	r.idx[label] = i
+	for _, kid := range r.waiting[label] {
+		r.addEdge(i, kid)
+	}
+	delete(r.waiting, label)
+	parents, err := n.Parents()
+	if err != nil {
+		return err
+	}
+	for _, p := range parents {
+		pl := string(p.Pattern().Label())
+		if j, has := r.idx[pl]; has {
+			r.addEdge(j, i)
+		} else {
+			r.waiting[pl] = append(r.waiting[pl], i)
+		}
+	}
+	return nil
+}
+
+func (r *LatticeExport) addEdge(parent, kid int) {
+	e := lattice.Edge{Src: parent, Targ: kid}
+	if r.hasEdge[e] {
+		return
+	}
+	r.hasEdge[e] = true
+	r.edges = append(r.edges, e)
+}
+
+func (r *LatticeExport) Close() error {
+	f, err := os.Create(r.config.OutputFile(r.filename))
+	if err != nil {
+		return err
+	}
+	var werr error
+	switch r.format {
+	case "dot":
+		werr = r.writeDot(f)
+	case "graphml":
+		werr = r.writeGraphML(f)
+	}
+	err = f.Close()
+	if werr != nil {
+		return werr
+	}
+	if err != nil {
+		return err
+	}
+	errors.Logf("INFO", "lattice: %v nodes, %v edges", len(r.nodes), len(r.edges))
+	return nil
+}
+
+func (r *LatticeExport) writeDot(w io.Writer) error {
+	fmt.Fprintf(w, "digraph lattice {\n")
+	for i, n := range r.nodes {
+		label := fmt.Sprintf("%v\nlevel: %v", n.name, n.level)
+		if n.hasSupp {
+			label += fmt.Sprintf("\nsupport: %v", n.support)
+		}
+		if n.reported > 1 {
+			label += fmt.Sprintf("\nreported: %v", n.reported)
+		}
+		fmt.Fprintf(w, "    %d [shape=rect,label=%s];\n", i, strconv.Quote(label))
+	}
+	for _, e := range r.edges {
+		fmt.Fprintf(w, "    %d -> %d;\n", e.Src, e.Targ)
+	}
+	_, err := fmt.Fprintf(w, "}\n")
+	return err
+}
+
+func (r *LatticeExport) writeGraphML(w io.Writer) error {
+	escape := func(s string) string {
+		var buf bytes.Buffer
+		xml.EscapeText(&buf, []byte(s))
+		return buf.String()
+	}
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="name" for="node" attr.name="name" attr.type="string"/>
+  <key id="level" for="node" attr.name="level" attr.type="int"/>
+  <key id="support" for="node" attr.name="support" attr.type="int"/>
+  <key id="reported" for="node" attr.name="reported" attr.type="int"/>
+  <graph id="lattice" edgedefault="directed">
+`)
+	for i, n := range r.nodes {
+		fmt.Fprintf(w, "    <node id=\"n%d\">\n", i)
+		fmt.Fprintf(w, "      <data key=\"name\">%s</data>\n", escape(n.name))
+		fmt.Fprintf(w, "      <data key=\"level\">%d</data>\n", n.level)
+		if n.hasSupp {
+			fmt.Fprintf(w, "      <data key=\"support\">%d</data>\n", n.support)
+		}
+		fmt.Fprintf(w, "      <data key=\"reported\">%d</data>\n", n.reported)
+		fmt.Fprintf(w, "    </node>\n")
+	}
+	for i, e := range r.edges {
+		fmt.Fprintf(w, "    <edge id=\"e%d\" source=\"n%d\" target=\"n%d\"/>\n", i, e.Src, e.Targ)
+	}
+	_, err := fmt.Fprintf(w, "  </graph>\n</graphml>\n")
+	return err
+}
@@ -0,0 +1,152 @@
+package reporters
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/lattice"
+)
+
+type latticePattern struct {
+	lattice.Pattern
+	label string
+	level int
+}
+
+func (p *latticePattern) Label() []byte {
+	return []byte(p.label)
+}
+
+func (p *latticePattern) Level() int {
+	return p.level
+}
+
+// latticeStub is a node which knows its parents. Asking for its children is
+// an error.
+type latticeStub struct {
+	lattice.Node
+	label   string
+	level   int
+	parents []*latticeStub
+}
+
+func (n *latticeStub) Pattern() lattice.Pattern {
+	return &latticePattern{label: n.label, level: n.level}
+}
+
+func (n *latticeStub) Parents() ([]lattice.Node, error) {
+	parents := make([]lattice.Node, 0, len(n.parents))
+	for _, p := range n.parents {
+		parents = append(parents, p)
+	}
+	return parents, nil
+}
+
+func (n *latticeStub) Children() ([]lattice.Node, error) {
+	return nil, errors.Errorf("the children of %v were computed", n.label)
+}
+
+type nameFormatter struct {
+	lattice.Formatter
+}
+
+func (f *nameFormatter) PatternName(n lattice.Node) string {
+	return string(n.Pattern().Label())
+}
+
+// the diamond a -> ab, a -> ac, ab -> abc, ac -> abc
+func diamond() (a, ab, ac, abc *latticeStub) {
+	a = &latticeStub{label: "a", level: 1}
+	ab = &latticeStub{label: "ab", level: 2, parents: []*latticeStub{a}}
+	ac = &latticeStub{label: "ac", level: 2, parents: []*latticeStub{a}}
+	abc = &latticeStub{label: "abc", level: 3, parents: []*latticeStub{ab, ac}}
+	return a, ab, ac, abc
+}
+
+func latticeEdges(r *LatticeExport) map[string]bool {
+	edges := make(map[string]bool)
+	for _, e := range r.edges {
+		edges[r.nodes[e.Src].name+"->"+r.nodes[e.Targ].name] = true
+	}
+	return edges
+}
+
+func TestLatticeEdgesFromParents(t *testing.T) {
+	x := assert.New(t)
+	expected := map[string]bool{"a->ab": true, "a->ac": true, "ab->abc": true, "ac->abc": true}
+	a, ab, ac, abc := diamond()
+	// top down, bottom up and mixed orders find the same edges
+	for _, order := range [][]*latticeStub{
+		{a, ab, ac, abc},
+		{abc, ac, ab, a},
+		{ab, abc, a, ac},
+	} {
+		r, err := NewLatticeExport(&config.Config{}, &nameFormatter{}, "lattice", "dot")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, n := range order {
+			x.Nil(r.Report(n))
+		}
+		x.Equal(4, len(r.nodes))
+		x.Equal(4, len(r.edges))
+		x.Equal(expected, latticeEdges(r))
+		x.Equal(0, len(r.waiting))
+	}
+}
+
+func TestLatticeUnreportedParents(t *testing.T) {
+	x := assert.New(t)
+	_, ab, _, abc := diamond()
+	r, err := NewLatticeExport(&config.Config{}, &nameFormatter{}, "lattice", "dot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	x.Nil(r.Report(abc))
+	x.Nil(r.Report(ab))
+	x.Nil(r.Report(abc))
+	x.Equal(map[string]bool{"ab->abc": true}, latticeEdges(r))
+	x.Equal(2, r.nodes[0].reported)
+	var buf bytes.Buffer
+	x.Nil(r.writeDot(&buf))
+	x.Contains(buf.String(), "    1 -> 0;\n")
+	x.Contains(buf.String(), `reported: 2`)
+}
+
+func TestLatticeGraphML(t *testing.T) {
+	x := assert.New(t)
+	dir, err := ioutil.TempDir("", "lattice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	a, ab, _, _ := diamond()
+	r, err := NewLatticeExport(&config.Config{Output: dir}, &nameFormatter{}, "lattice", "graphml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	x.Nil(r.Report(a))
+	x.Nil(r.Report(ab))
+	x.Nil(r.Close())
+	out, err := ioutil.ReadFile(filepath.Join(dir, "lattice.graphml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	x.Equal(2, strings.Count(string(out), "<node "))
+	x.Contains(string(out), `<edge id="e0" source="n0" target="n1"/>`)
+	_, err = NewLatticeExport(&config.Config{}, &nameFormatter{}, "lattice", "png")
+	x.Error(err)
+}
@@ -0,0 +1,160 @@
+package reporters
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/lattice"
+	"github.com/timtadh/regrax/types/digraph"
+	"github.com/timtadh/regrax/types/digraph/subgraph"
+)
+
+// occurrenceColumns are the columns of every row (before the attributes).
+var occurrenceColumns = []string{"pattern", "pattern_name", "embedding", "vertex", "oid", "label"}
+
+// Occurrences writes a row for every vertex of every embedding of the
+// reported patterns mapping it back to the original graph: pattern id,
+// embedding index, pattern vertex index, the original id (oid), the vertex
+// label and the selected attributes. The format is csv or jsonl. Every
+// embedding the miner counts (see Digraph.DoEmbeddings) is searched for
+// again as a node only keeps those supporting it (eg. of one vertex under
+// MNI) and may truncate them.
+type Occurrences struct {
+	config *config.Config
+	fmtr   lattice.Formatter
+	attrs  []string
+	format string
+	file   io.WriteCloser
+	csv    *csv.Writer
+	count  int
+	rows   int
+}
+
+func NewOccurrences(c *config.Config, fmtr lattice.Formatter, filename, format string, attrs []string) (*Occurrences, error) {
+	if format != "csv" && format != "jsonl" {
+		return nil, errors.Errorf("unknown occurrences format '%v' (expected csv or jsonl)", format)
+	}
+	for _, a := range attrs {
+		for _, col := range occurrenceColumns {
+			if a == col {
+				return nil, errors.Errorf("the attribute '%v' has the name of an occurrences column", a)
+			}
+		}
+	}
+	if !strings.HasSuffix(filename, "."+format) {
+		filename = filename + "." + format
+	}
+	f, err := os.Create(c.OutputFile(filename))
+	if err != nil {
+		return nil, err
+	}
+	r := &Occurrences{
+		config: c,
+		fmtr:   fmtr,
+		attrs:  attrs,
+		format: format,
+		file:   f,
+	}
+	if format == "csv" {
+		r.csv = csv.NewWriter(f)
+		header := make([]string, 0, len(occurrenceColumns)+len(attrs))
+		header = append(header, occurrenceColumns...)
+		err = r.csv.Write(append(header, attrs...))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *Occurrences) Report(node lattice.Node) error {
+	n, ok := node.(*digraph.EmbListNode)
+	if !ok {
+		return errors.Errorf("the occurrences reporter does not support %T", node)
+	}
+	pattern := r.count
+	r.count++
+	name := r.fmtr.PatternName(n)
+	i := 0
+	return n.Dt.DoEmbeddings(n.Pat, func(emb *subgraph.Embedding) error {
+		for vidx, id := range emb.Ids {
+			attrs, err := vertexAttrs(n.Dt, id)
+			if err != nil {
+				return err
+			}
+			label := n.Dt.Labels.Label(emb.SG.V[vidx].Color)
+			err = r.write(pattern, name, i, vidx, vertexOid(attrs, id), label, attrs)
+			if err != nil {
+				return err
+			}
+			r.rows++
+		}
+		i++
+		return nil
+	})
+}
+
+func (r *Occurrences) write(pattern int, name string, emb, vidx int, oid interface{}, label string, attrs map[string]interface{}) error {
+	switch r.format {
+	case "csv":
+		row := []string{
+			strconv.Itoa(pattern),
+			name,
+			strconv.Itoa(emb),
+			strconv.Itoa(vidx),
+			fmt.Sprint(oid),
+			label,
+		}
+		for _, a := range r.attrs {
+			if v, has := attrs[a]; has {
+				row = append(row, fmt.Sprint(v))
+			} else {
+				row = append(row, "")
+			}
+		}
+		return r.csv.Write(row)
+	case "jsonl":
+		row := map[string]interface{}{
+			"pattern":      pattern,
+			"pattern_name": name,
+			"embedding":    emb,
+			"vertex":       vidx,
+			"oid":          oid,
+			"label":        label,
+		}
+		for _, a := range r.attrs {
+			row[a] = attrs[a]
+		}
+		bytes, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(r.file, "%s\n", bytes)
+		return err
+	}
+	return nil
+}
+
+func (r *Occurrences) Close() error {
+	errors.Logf("INFO", "occurrences: %v rows for %v patterns", r.rows, r.count)
+	if r.csv != nil {
+		r.csv.Flush()
+		if err := r.csv.Error(); err != nil {
+			r.file.Close()
+			return err
+		}
+	}
+	return r.file.Close()
+}
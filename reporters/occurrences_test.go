@@ -0,0 +1,136 @@
+package reporters
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/lattice"
+	"github.com/timtadh/regrax/types/digraph"
+)
+
+type fixedFormatter struct {
+	lattice.Formatter
+}
+
+func (f *fixedFormatter) PatternName(lattice.Node) string {
+	return "a-e-b"
+}
+
+func (f *fixedFormatter) Pattern(lattice.Node) (string, error) {
+	return "a -e-> b", nil
+}
+
+// occurrencesDigraph is the star digraph with the attributes oid and file on
+// its vertices. The c vertex has no file.
+func occurrencesDigraph(t testing.TB, conf *config.Config) *digraph.Digraph {
+	dt := starDigraph(t, conf)
+	for id := 0; id < 4; id++ {
+		attrs := map[string]interface{}{"oid": fmt.Sprintf("v%d", id)}
+		if id < 3 {
+			attrs["file"] = fmt.Sprintf("f%d.c", id)
+		}
+		err := dt.NodeAttrs.Add(int32(id), attrs)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dt
+}
+
+func TestOccurrencesEveryEmbedding(t *testing.T) {
+	x := assert.New(t)
+	dir, err := ioutil.TempDir("", "occurrences")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	conf := &config.Config{Support: 1, Output: dir}
+	dt := occurrencesDigraph(t, conf)
+	defer dt.Close()
+	r, err := NewOccurrences(conf, &fixedFormatter{}, "occurrences", "jsonl", []string{"file"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	x.Nil(r.Report(edgeNode(t, dt)))
+	x.Nil(r.Close())
+	out, err := ioutil.ReadFile(filepath.Join(dir, "occurrences.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	// the node keeps one embedding but both are written
+	x.Equal(4, len(lines))
+	oids := make(map[string]bool)
+	embs := make(map[float64]bool)
+	for _, line := range lines {
+		var row map[string]interface{}
+		x.Nil(json.Unmarshal([]byte(line), &row))
+		x.Equal("a-e-b", row["pattern_name"])
+		x.Equal(row["oid"].(string)[1:], row["file"].(string)[1:2])
+		oids[row["oid"].(string)] = true
+		embs[row["embedding"].(float64)] = true
+	}
+	x.Equal(map[string]bool{"v0": true, "v1": true, "v2": true}, oids)
+	x.Equal(map[float64]bool{0: true, 1: true}, embs)
+}
+
+func TestOccurrencesCsv(t *testing.T) {
+	x := assert.New(t)
+	dir, err := ioutil.TempDir("", "occurrences")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	conf := &config.Config{Support: 1, Output: dir}
+	dt := occurrencesDigraph(t, conf)
+	defer dt.Close()
+	r, err := NewOccurrences(conf, &fixedFormatter{}, "occurrences", "csv", []string{"file", "line"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	x.Nil(r.Report(edgeNode(t, dt)))
+	x.Nil(r.Close())
+	f, err := os.Open(filepath.Join(dir, "occurrences.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	x.Nil(err)
+	if !x.Equal(5, len(rows)) {
+		return
+	}
+	x.Equal([]string{"pattern", "pattern_name", "embedding", "vertex", "oid", "label", "file", "line"}, rows[0])
+	for _, row := range rows[1:] {
+		// the missing line attribute is empty
+		x.Equal("f"+row[4][1:]+".c", row[6])
+		x.Equal("", row[7])
+	}
+}
+
+func TestOccurrencesColumnAttrs(t *testing.T) {
+	x := assert.New(t)
+	dir, err := ioutil.TempDir("", "occurrences")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	conf := &config.Config{Output: dir}
+	for _, attr := range []string{"oid", "label", "pattern"} {
+		_, err := NewOccurrences(conf, &fixedFormatter{}, "occurrences", "jsonl", []string{"file", attr})
+		x.Error(err, attr)
+	}
+	_, err = NewOccurrences(conf, &fixedFormatter{}, "occurrences", "xml", nil)
+	x.Error(err)
+}
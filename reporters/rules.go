@@ -0,0 +1,115 @@
+package reporters
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/lattice"
+	"github.com/timtadh/regrax/types/itemset"
+)
+
+// Rules writes the association rules derived from each reported itemset.
+// The output is either csv (one rule per row) or json (one rule per line).
+type Rules struct {
+	config  *config.Config
+	minConf float64
+	minLift float64
+	format  string
+	file    io.WriteCloser
+	csv     *csv.Writer
+	count   int
+}
+
+func NewRules(c *config.Config, filename, format string, minConf, minLift float64) (*Rules, error) {
+	if format != "csv" && format != "json" {
+		return nil, errors.Errorf("unknown rules format '%v' (expected csv or json)", format)
+	}
+	if !strings.HasSuffix(filename, "."+format) {
+		filename = filename + "." + format
+	}
+	f, err := os.Create(c.OutputFile(filename))
+	if err != nil {
+		return nil, err
+	}
+	r := &Rules{
+		config:  c,
+		minConf: minConf,
+		minLift: minLift,
+		format:  format,
+		file:    f,
+	}
+	if format == "csv" {
+		r.csv = csv.NewWriter(f)
+		err = r.csv.Write([]string{"antecedent", "consequent", "support", "confidence", "lift"})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *Rules) Report(n lattice.Node) error {
+	rules, err := itemset.Rules(n, r.minConf, r.minLift)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		err := r.write(rule)
+		if err != nil {
+			return err
+		}
+		r.count++
+	}
+	return nil
+}
+
+func (r *Rules) write(rule *itemset.Rule) error {
+	switch r.format {
+	case "csv":
+		return r.csv.Write([]string{
+			strings.Join(rule.Antecedent, " "),
+			strings.Join(rule.Consequent, " "),
+			strconv.Itoa(rule.Support),
+			strconv.FormatFloat(rule.Confidence, 'g', -1, 64),
+			strconv.FormatFloat(rule.Lift, 'g', -1, 64),
+		})
+	case "json":
+		bytes, err := json.Marshal(map[string]interface{}{
+			"antecedent": rule.Antecedent,
+			"consequent": rule.Consequent,
+			"support":    rule.Support,
+			"confidence": rule.Confidence,
+			"lift":       rule.Lift,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(r.file, "%s\n", bytes)
+		return err
+	}
+	return nil
+}
+
+func (r *Rules) Close() error {
+	errors.Logf("INFO", "total rules found %v", r.count)
+	if r.csv != nil {
+		r.csv.Flush()
+		if err := r.csv.Error(); err != nil {
+			r.file.Close()
+			return err
+		}
+	}
+	return r.file.Close()
+}
@@ -0,0 +1,273 @@
+package reporters
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+)
+
+import (
+	"github.com/timtadh/regrax/lattice"
+	"github.com/timtadh/regrax/progress"
+	"github.com/timtadh/regrax/sample/miners"
+)
+
+// servedPattern is a reported pattern as served over http.
+type servedPattern struct {
+	Id      int    `json:"id"`
+	Name    string `json:"name"`
+	Level   int    `json:"level"`
+	Support int    `json:"support"`
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// Server wraps the reporter (see --serve) and serves the progress of the run
+// and the patterns reported so far over http while passing them on:
+//
+//	GET /progress             counters, cache hit rate and elapsed time
+//	GET /patterns             the reported patterns (without bodies)
+//	GET /patterns/<id>        a pattern as json
+//	GET /patterns/<id><ext>   a pattern in the format of the type (eg. .dot)
+//	GET /events               a server-sent-events stream of new patterns
+//
+// The events stream replays the patterns after the Last-Event-ID header (or
+// the ?after= parameter) so clients may reconnect without missing any.
+type Server struct {
+	Reporter miners.Reporter
+	fmtr     lattice.Formatter
+	listener net.Listener
+	start    time.Time
+	lock     sync.Mutex
+	patterns []*servedPattern
+	subs     map[chan *servedPattern]bool
+	closed   bool
+}
+
+func NewServer(addr string, fmtr lattice.Formatter, rptr miners.Reporter) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	r := &Server{
+		Reporter: rptr,
+		fmtr:     fmtr,
+		listener: listener,
+		start:    time.Now(),
+		subs:     make(map[chan *servedPattern]bool),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", r.index)
+	mux.HandleFunc("/progress", r.progress)
+	mux.HandleFunc("/patterns", r.list)
+	mux.HandleFunc("/patterns/", r.pattern)
+	mux.HandleFunc("/events", r.events)
+	go func() {
+		err := http.Serve(listener, mux)
+		r.lock.Lock()
+		closed := r.closed
+		r.lock.Unlock()
+		if !closed {
+			errors.Logf("ERROR", "serve: %v", err)
+		}
+	}()
+	errors.Logf("INFO", "serving progress at http://%v/", listener.Addr())
+	return r, nil
+}
+
+func (r *Server) Report(n lattice.Node) error {
+	pat, err := r.fmtr.Pattern(n)
+	if err != nil {
+		return err
+	}
+	p := &servedPattern{
+		Name:    r.fmtr.PatternName(n),
+		Level:   n.Pattern().Level(),
+		Pattern: pat,
+	}
+	if s, ok := n.(supported); ok {
+		p.Support = s.Support()
+	}
+	progress.Reported.Inc()
+	r.lock.Lock()
+	p.Id = len(r.patterns)
+	r.patterns = append(r.patterns, p)
+	for sub := range r.subs {
+		select {
+		case sub <- p:
+		default:
+			// a slow client misses the event but may reconnect with its
+			// Last-Event-ID to get the patterns it missed.
+			delete(r.subs, sub)
+			close(sub)
+		}
+	}
+	r.lock.Unlock()
+	return r.Reporter.Report(n)
+}
+
+func (r *Server) Close() error {
+	r.lock.Lock()
+	r.closed = true
+	for sub := range r.subs {
+		close(sub)
+	}
+	r.subs = make(map[chan *servedPattern]bool)
+	r.lock.Unlock()
+	err := r.listener.Close()
+	if err != nil {
+		errors.Logf("ERROR", "serve: %v", err)
+	}
+	return r.Reporter.Close()
+}
+
+func (r *Server) writeJSON(w http.ResponseWriter, v interface{}) {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(bytes)
+	w.Write([]byte("\n"))
+}
+
+func (r *Server) index(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path != "/" {
+		http.NotFound(w, req)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "/progress\n/patterns\n/patterns/<id>\n/patterns/<id>%v\n/events\n", r.fmtr.FileExt())
+}
+
+func (r *Server) progress(w http.ResponseWriter, req *http.Request) {
+	r.lock.Lock()
+	count := len(r.patterns)
+	r.lock.Unlock()
+	r.writeJSON(w, map[string]interface{}{
+		"counters":        progress.Snapshot(),
+		"cache_hit_rate":  progress.CacheHitRate(),
+		"patterns":        count,
+		"elapsed_seconds": time.Since(r.start).Seconds(),
+	})
+}
+
+func (r *Server) list(w http.ResponseWriter, req *http.Request) {
+	r.lock.Lock()
+	list := make([]servedPattern, 0, len(r.patterns))
+	for _, p := range r.patterns {
+		short := *p
+		short.Pattern = ""
+		list = append(list, short)
+	}
+	r.lock.Unlock()
+	r.writeJSON(w, list)
+}
+
+func (r *Server) pattern(w http.ResponseWriter, req *http.Request) {
+	name := strings.TrimPrefix(req.URL.Path, "/patterns/")
+	ext := r.fmtr.FileExt()
+	raw := ext != "" && strings.HasSuffix(name, ext)
+	if raw {
+		name = strings.TrimSuffix(name, ext)
+	}
+	id, err := strconv.Atoi(name)
+	r.lock.Lock()
+	var p *servedPattern
+	if err == nil && id >= 0 && id < len(r.patterns) {
+		p = r.patterns[id]
+	}
+	r.lock.Unlock()
+	if p == nil {
+		http.NotFound(w, req)
+		return
+	}
+	if raw {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprint(w, p.Pattern)
+		return
+	}
+	r.writeJSON(w, p)
+}
+
+func (r *Server) events(w http.ResponseWriter, req *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	after := -1
+	if last := req.Header.Get("Last-Event-ID"); last != "" {
+		after, _ = strconv.Atoi(last)
+	} else if a := req.URL.Query().Get("after"); a != "" {
+		after, _ = strconv.Atoi(a)
+	}
+	if after < -1 {
+		after = -1
+	}
+	sub := make(chan *servedPattern, 64)
+	r.lock.Lock()
+	if r.closed {
+		r.lock.Unlock()
+		http.Error(w, "the run is over", http.StatusGone)
+		return
+	}
+	missed := make([]*servedPattern, 0, 10)
+	if after+1 < len(r.patterns) {
+		missed = append(missed, r.patterns[after+1:]...)
+	}
+	r.subs[sub] = true
+	r.lock.Unlock()
+	defer func() {
+		r.lock.Lock()
+		if r.subs[sub] {
+			delete(r.subs, sub)
+			close(sub)
+		}
+		r.lock.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	send := func(p *servedPattern) bool {
+		bytes, err := json.Marshal(p)
+		if err != nil {
+			return false
+		}
+		_, err = fmt.Fprintf(w, "event: pattern\nid: %d\ndata: %s\n\n", p.Id, bytes)
+		if err != nil {
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+	for _, p := range missed {
+		if !send(p) {
+			return
+		}
+	}
+	flusher.Flush()
+	done := req.Context().Done()
+	for {
+		select {
+		case p, open := <-sub:
+			if !open {
+				return
+			}
+			if !send(p) {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package reporters
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+import (
+	"github.com/timtadh/regrax/lattice"
+)
+
+type stubFormatter struct {
+	lattice.Formatter
+}
+
+func (f *stubFormatter) FileExt() string {
+	return ".dot"
+}
+
+func (f *stubFormatter) PatternName(n lattice.Node) string {
+	return fmt.Sprintf("pattern-%d", n.(*stubNode).support)
+}
+
+func (f *stubFormatter) Pattern(n lattice.Node) (string, error) {
+	return fmt.Sprintf("digraph { %d }", n.(*stubNode).support), nil
+}
+
+func get(t *testing.T, url string) string {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestServerServesPatterns(t *testing.T) {
+	x := assert.New(t)
+	c := &Collector{}
+	r, err := NewServer("127.0.0.1:0", &stubFormatter{}, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := "http://" + r.listener.Addr().String()
+	for _, s := range []int{3, 5} {
+		err := r.Report(&stubNode{support: s, level: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	x.Equal(2, len(c.Nodes))
+
+	var list []servedPattern
+	x.Nil(json.Unmarshal([]byte(get(t, url+"/patterns")), &list))
+	x.Equal(2, len(list))
+	x.Equal("pattern-5", list[1].Name)
+	x.Equal(5, list[1].Support)
+	x.Equal("", list[1].Pattern)
+
+	x.Equal("digraph { 3 }", get(t, url+"/patterns/0.dot"))
+	var p servedPattern
+	x.Nil(json.Unmarshal([]byte(get(t, url+"/patterns/1")), &p))
+	x.Equal("digraph { 5 }", p.Pattern)
+
+	var prog map[string]interface{}
+	x.Nil(json.Unmarshal([]byte(get(t, url+"/progress")), &prog))
+	x.Equal(float64(2), prog["patterns"])
+
+	// a client reconnecting after the first pattern only gets the second
+	resp, err := http.Get(url + "/events?after=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := bufio.NewReader(resp.Body)
+	event, _ := lines.ReadString('\n')
+	id, _ := lines.ReadString('\n')
+	x.Equal("event: pattern", strings.TrimSpace(event))
+	x.Equal("id: 1", strings.TrimSpace(id))
+	x.Nil(r.Close())
+	resp.Body.Close()
+}
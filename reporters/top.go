@@ -0,0 +1,185 @@
+package reporters
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"sort"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/lattice"
+	"github.com/timtadh/regrax/sample/miners"
+	"github.com/timtadh/regrax/stores/bytes_bytes"
+)
+
+// reloadable nodes can be loaded again from their pattern label so they need
+// not be kept in memory.
+type reloadable interface {
+	Reloadable() bool
+	Reload(label []byte) (lattice.Node, error)
+}
+
+// topEntry is a ranked node. Spilled entries only keep the node they are
+// reloaded through (node is nil and the label is in the spill store).
+type topEntry struct {
+	score  float64
+	seq    uint64
+	node   lattice.Node
+	loader reloadable
+}
+
+// topHeap is a min heap so the worst of the kept nodes is at the top. Ties
+// go to the node reported first.
+type topHeap []*topEntry
+
+func (h topHeap) Len() int { return len(h) }
+func (h topHeap) Less(i, j int) bool {
+	if h[i].score == h[j].score {
+		return h[i].seq > h[j].seq
+	}
+	return h[i].score < h[j].score
+}
+func (h topHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *topHeap) Push(x interface{}) { *h = append(*h, x.(*topEntry)) }
+func (h *topHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
+
+// Top keeps the N highest ranked nodes (by an expression in the filter
+// language, eg. support, vertices or coverage) and reports them, best
+// first, to the inner reporter when it is closed. Once more than inMemory
+// nodes are kept, nodes which can be reloaded from the cache are spilled to
+// a store and only their rank is kept in memory.
+type Top struct {
+	Reporter miners.Reporter
+	N        int
+	by       string
+	key      filterExpr
+	inMemory int
+	held     int
+	seq      uint64
+	heap     topHeap
+	spill    bytes_bytes.MultiMap
+}
+
+func NewTop(conf *config.Config, n int, by string, inMemory int, rptr miners.Reporter) (*Top, error) {
+	if n <= 0 {
+		return nil, errors.Errorf("top needs N > 0 (got %v)", n)
+	}
+	key, err := parseFilter(by)
+	if err != nil {
+		return nil, err
+	}
+	spill, err := conf.MultiMap("top-spill")
+	if err != nil {
+		return nil, err
+	}
+	r := &Top{
+		Reporter: rptr,
+		N:        n,
+		by:       by,
+		key:      key,
+		inMemory: inMemory,
+		heap:     make(topHeap, 0, n),
+		spill:    spill,
+	}
+	return r, nil
+}
+
+func (r *Top) Report(n lattice.Node) error {
+	score, err := filterNum(r.key, &filterEnv{node: n})
+	if err != nil {
+		return errors.Errorf("top --by %q: %v", r.by, err)
+	}
+	r.seq++
+	e := &topEntry{score: score, seq: r.seq}
+	if len(r.heap) >= r.N {
+		worst := r.heap[0]
+		if score <= worst.score {
+			return nil
+		}
+		heap.Pop(&r.heap)
+		err := r.drop(worst)
+		if err != nil {
+			return err
+		}
+	}
+	err = r.hold(e, n)
+	if err != nil {
+		return err
+	}
+	heap.Push(&r.heap, e)
+	return nil
+}
+
+// hold keeps the node of the entry in memory or spills it.
+func (r *Top) hold(e *topEntry, n lattice.Node) error {
+	if l, ok := n.(reloadable); ok && r.held >= r.inMemory && l.Reloadable() {
+		e.loader = l
+		return r.spill.Add(r.seqKey(e.seq), n.Pattern().Label())
+	}
+	e.node = n
+	r.held++
+	return nil
+}
+
+func (r *Top) drop(e *topEntry) error {
+	if e.node != nil {
+		r.held--
+		return nil
+	}
+	return r.spill.Remove(r.seqKey(e.seq), func([]byte) bool { return true })
+}
+
+func (r *Top) seqKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
+
+func (r *Top) load(e *topEntry) (lattice.Node, error) {
+	if e.node != nil {
+		return e.node, nil
+	}
+	var label []byte
+	err := r.spill.DoFind(r.seqKey(e.seq), func(_, value []byte) error {
+		label = value
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if label == nil {
+		return nil, errors.Errorf("spilled node %v was not found", e.seq)
+	}
+	return e.loader.Reload(label)
+}
+
+func (r *Top) Close() error {
+	ranked := make(topHeap, len(r.heap))
+	copy(ranked, r.heap)
+	sort.Sort(sort.Reverse(ranked))
+	for _, e := range ranked {
+		n, err := r.load(e)
+		if err != nil {
+			return err
+		}
+		err = r.Reporter.Report(n)
+		if err != nil {
+			return err
+		}
+	}
+	err := r.spill.Delete()
+	if err != nil {
+		errors.Logf("ERROR", "%v", err)
+	}
+	return r.Reporter.Close()
+}
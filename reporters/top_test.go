@@ -0,0 +1,116 @@
+package reporters
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+import (
+	"fmt"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/lattice"
+)
+
+func TestTopKeepsHighestRanked(t *testing.T) {
+	x := assert.New(t)
+	c := &Collector{}
+	top, err := NewTop(&config.Config{}, 3, "support", 10, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := make([]*stubNode, 0, 8)
+	for _, s := range []int{5, 1, 9, 5, 7, 2, 9, 3} {
+		n := &stubNode{support: s, level: 1}
+		nodes = append(nodes, n)
+		err := top.Report(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	x.Equal(0, len(c.Nodes))
+	err = top.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ties go to the node reported first
+	x.Equal(3, len(c.Nodes))
+	x.True(c.Nodes[0] == nodes[2])
+	x.True(c.Nodes[1] == nodes[6])
+	x.True(c.Nodes[2] == nodes[4])
+}
+
+func TestTopBadKey(t *testing.T) {
+	x := assert.New(t)
+	_, err := NewTop(&config.Config{}, 3, "support >", 10, &Collector{})
+	x.Error(err)
+	_, err = NewTop(&config.Config{}, 0, "support", 10, &Collector{})
+	x.Error(err)
+}
+
+// spillNode can be reloaded from its label through the nodes it was
+// reported from.
+type spillNode struct {
+	lattice.Node
+	label    string
+	support  int
+	reported map[string]*spillNode
+	reloads  *int
+}
+
+func (n *spillNode) Support() int {
+	return n.support
+}
+
+func (n *spillNode) Pattern() lattice.Pattern {
+	return &latticePattern{label: n.label, level: 1}
+}
+
+func (n *spillNode) Reloadable() bool {
+	return true
+}
+
+func (n *spillNode) Reload(label []byte) (lattice.Node, error) {
+	*n.reloads++
+	o := n.reported[string(label)]
+	return &spillNode{label: o.label, support: o.support, reported: o.reported, reloads: o.reloads}, nil
+}
+
+func TestTopSpillsAndReloads(t *testing.T) {
+	x := assert.New(t)
+	c := &Collector{}
+	// at most 2 of the 5 kept nodes stay in memory
+	top, err := NewTop(&config.Config{}, 5, "support", 2, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reported := make(map[string]*spillNode)
+	reloads := 0
+	for i, s := range []int{4, 8, 1, 6, 9, 3, 7, 2, 5, 8} {
+		n := &spillNode{label: fmt.Sprintf("n%d", i), support: s, reported: reported, reloads: &reloads}
+		reported[n.label] = n
+		err := top.Report(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	x.True(top.held <= 2)
+	spilled := 0
+	for _, e := range top.heap {
+		if e.node == nil {
+			spilled++
+		}
+	}
+	x.Equal(3, spilled)
+	err = top.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	x.Equal(spilled, reloads)
+	labels := make([]string, 0, len(c.Nodes))
+	for _, n := range c.Nodes {
+		labels = append(labels, n.(*spillNode).label)
+	}
+	// best first, ties go to the node reported first
+	x.Equal([]string{"n4", "n1", "n9", "n6", "n3"}, labels)
+}
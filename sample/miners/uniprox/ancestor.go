@@ -19,6 +19,8 @@ import (
 	"github.com/timtadh/regrax/types/digraph"
 	dg "github.com/timtadh/regrax/types/digraph/digraph"
 	"github.com/timtadh/regrax/types/itemset"
+	"github.com/timtadh/regrax/types/sequence"
+	"github.com/timtadh/regrax/types/tree"
 )
 
 func CommonAncestor(patterns []lattice.Pattern) (_ lattice.Pattern, err error) {
@@ -32,6 +34,10 @@ func CommonAncestor(patterns []lattice.Pattern) (_ lattice.Pattern, err error) {
 		return digraphCommonAncestor(patterns)
 	case *itemset.Pattern:
 		return itemsetCommonAncestor(patterns)
+	case *sequence.Pattern:
+		return sequenceCommonAncestor(patterns)
+	case *tree.Pattern:
+		return treeCommonAncestor(patterns)
 	default:
 		return nil, errors.Errorf("unknown pattern type %v", patterns[0])
 	}
@@ -53,6 +59,25 @@ func itemsetCommonAncestor(patterns []lattice.Pattern) (_ lattice.Pattern, err e
 	return &itemset.Pattern{items.(*set.SortedSet)}, nil
 }
 
+// sequenceCommonAncestor folds the longest common subsequence over the
+// patterns. The result is a common subsequence of all of the patterns but
+// (for more than two patterns) not necessarily the longest one.
+func sequenceCommonAncestor(patterns []lattice.Pattern) (lattice.Pattern, error) {
+	items := patterns[0].(*sequence.Pattern).Items
+	for _, pat := range patterns[1:] {
+		items = sequence.LongestCommonSubsequence(items, pat.(*sequence.Pattern).Items)
+	}
+	return &sequence.Pattern{items}, nil
+}
+
+func treeCommonAncestor(patterns []lattice.Pattern) (lattice.Pattern, error) {
+	anc := patterns[0].(*tree.Pattern)
+	for _, pat := range patterns[1:] {
+		anc = tree.CommonAncestor(anc, pat.(*tree.Pattern))
+	}
+	return anc, nil
+}
+
 func digraphCommonAncestor(patterns []lattice.Pattern) (lattice.Pattern, error) {
 
 	// construct a in memory configuration for finding common subdigraphs of all patterns
@@ -9,6 +9,7 @@ import (
 import (
 	"github.com/timtadh/regrax/config"
 	"github.com/timtadh/regrax/lattice"
+	"github.com/timtadh/regrax/progress"
 	"github.com/timtadh/regrax/sample/miners"
 )
 
@@ -112,9 +113,11 @@ func (w *Walker) RejectingWalk(samples chan lattice.Node, terminate chan bool) c
 					accept = true
 					i++
 				} else {
+					progress.Duplicates.Inc()
 					errors.Logf("DEBUG", "duplicate %v", sampled)
 				}
 			} else {
+				progress.Rejected.Inc()
 				errors.Logf("DEBUG", "rejected %v", sampled)
 			}
 			if i >= w.Config.Samples {
@@ -126,6 +129,7 @@ func (w *Walker) RejectingWalk(samples chan lattice.Node, terminate chan bool) c
 				terminate <- false
 			}
 			if accept {
+				progress.Accepted.Inc()
 				accepted <- sampled
 			}
 		}
@@ -237,6 +237,8 @@ func Run(argv []string) int {
 			"skip-log=",
 			"cpu-profile=",
 			"parallelism=",
+			"serve=",
+			"metrics=",
 		},
 	)
 	if err != nil {
@@ -253,6 +255,8 @@ func Run(argv []string) int {
 	samples := 0
 	cpuProfile := ""
 	parallelism := -1
+	serve := ""
+	metricsName := ""
 	for _, oa := range optargs {
 		switch oa.Opt() {
 		case "-h", "--help":
@@ -293,6 +297,10 @@ func Run(argv []string) int {
 			errors.SkipLogging[level] = true
 		case "--cpu-profile":
 			cpuProfile = cmd.AssertFile(oa.Arg())
+		case "--serve":
+			serve = oa.Arg()
+		case "--metrics":
+			metricsName = oa.Arg()
 		default:
 			fmt.Fprintf(os.Stderr, "Unknown flag '%v'\n", oa.Opt())
 			cmd.Usage(cmd.ErrorCodes["opts"])
@@ -349,6 +357,8 @@ func Run(argv []string) int {
 		Samples:     samples,
 		Unique:      unique,
 		Parallelism: parallelism,
+		Serve:       serve,
+		Metrics:     metricsName,
 	}
 	return cmd.Main(args, conf, modes)
 }
@@ -1,4 +1,4 @@
-//go:generate fs2-generic --output=wrapper.go --package-name=bytes_extension bptree --key-type=[]byte --key-serializer=github.com/timtadh/regrax/stores/bytes_subgraph/Identity --key-deserializer=github.com/timtadh/regrax/stores/bytes_subgraph/Identity --value-type=*github.com/timtadh/regrax/types/digraph/subgraph/Extension --value-size=20 --value-serializer=SerializeExtension --value-deserializer=DeserializeExtension
+//go:generate fs2-generic --output=wrapper.go --package-name=bytes_extension bptree --key-type=[]byte --key-serializer=github.com/timtadh/regrax/stores/bytes_subgraph/Identity --key-deserializer=github.com/timtadh/regrax/stores/bytes_subgraph/Identity --value-type=*github.com/timtadh/regrax/types/digraph/subgraph/Extension --value-serializer=SerializeExtension --value-deserializer=DeserializeExtension
 package bytes_extension
 
 import (
@@ -9,17 +9,41 @@ import (
 	"github.com/timtadh/regrax/types/digraph/subgraph"
 )
 
+// SerializeExtension writes the edge of the extension (28 bytes) followed by
+// the edges in More (20 bytes each).
 func SerializeExtension(e *subgraph.Extension) []byte {
-	bytes := make([]byte, 20)
+	bytes := make([]byte, 28+20*len(e.More))
+	putEdge(bytes, e)
+	binary.BigEndian.PutUint32(bytes[20:24], uint32(e.Time))
+	if e.Relabel {
+		binary.BigEndian.PutUint32(bytes[24:28], 1)
+	}
+	for i := range e.More {
+		s := 28 + 20*i
+		putEdge(bytes[s:s+20], &e.More[i])
+	}
+	return bytes
+}
+
+func DeserializeExtension(bytes []byte) *subgraph.Extension {
+	ext := getEdge(bytes)
+	ext.Time = int(binary.BigEndian.Uint32(bytes[20:24]))
+	ext.Relabel = binary.BigEndian.Uint32(bytes[24:28]) == 1
+	for s := 28; s+20 <= len(bytes); s += 20 {
+		ext.More = append(ext.More, *getEdge(bytes[s : s+20]))
+	}
+	return ext
+}
+
+func putEdge(bytes []byte, e *subgraph.Extension) {
 	binary.BigEndian.PutUint32(bytes[0:4], uint32(e.Source.Idx))
 	binary.BigEndian.PutUint32(bytes[4:8], uint32(e.Source.Color))
 	binary.BigEndian.PutUint32(bytes[8:12], uint32(e.Target.Idx))
 	binary.BigEndian.PutUint32(bytes[12:16], uint32(e.Target.Color))
 	binary.BigEndian.PutUint32(bytes[16:20], uint32(e.Color))
-	return bytes
 }
 
-func DeserializeExtension(bytes []byte) *subgraph.Extension {
+func getEdge(bytes []byte) *subgraph.Extension {
 	srcIdx := int(binary.BigEndian.Uint32(bytes[0:4]))
 	srcColor := int(binary.BigEndian.Uint32(bytes[4:8]))
 	targIdx := int(binary.BigEndian.Uint32(bytes[8:12]))
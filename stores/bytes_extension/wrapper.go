@@ -17,7 +17,6 @@ package bytes_extension
 *     --key-serializer=github.com/timtadh/regrax/stores/bytes_subgraph/Identity \
 *     --key-deserializer=github.com/timtadh/regrax/stores/bytes_subgraph/Identity \
 *     --value-type=*github.com/timtadh/regrax/types/digraph/subgraph/Extension \
-*     --value-size=20 \
 *     --value-serializer=SerializeExtension \
 *     --value-deserializer=DeserializeExtension
 *
@@ -167,7 +166,7 @@ func OpenBpTree(path string) (*BpTree, error) {
 }
 
 func newBpTree(bf *fmap.BlockFile) (*BpTree, error) {
-	bpt, err := bptree.New(bf, -1, 20)
+	bpt, err := bptree.New(bf, -1, -1)
 	if err != nil {
 		return nil, err
 	}
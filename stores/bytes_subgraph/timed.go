@@ -0,0 +1,54 @@
+package bytes_subgraph
+
+import (
+	"github.com/timtadh/goiso"
+)
+
+import (
+	"github.com/timtadh/regrax/metrics"
+)
+
+var (
+	reads  = metrics.StoreReads("bytes_subgraph")
+	writes = metrics.StoreWrites("bytes_subgraph")
+)
+
+// Timed is a MultiMap which times its finds, adds and removes (in the
+// store_read_seconds and store_write_seconds metrics). Iterating over it is
+// not timed.
+type Timed struct {
+	MultiMap
+}
+
+func NewTimed(m MultiMap) *Timed {
+	return &Timed{m}
+}
+
+func (t *Timed) Find(key []byte) (Iterator, error) {
+	defer reads.Time()()
+	return t.MultiMap.Find(key)
+}
+
+func (t *Timed) DoFind(key []byte, do func([]byte, *goiso.SubGraph) error) error {
+	return Do(func() (Iterator, error) { return t.Find(key) }, do)
+}
+
+func (t *Timed) Has(key []byte) (bool, error) {
+	defer reads.Time()()
+	return t.MultiMap.Has(key)
+}
+
+func (t *Timed) Count(key []byte) (int, error) {
+	defer reads.Time()()
+	return t.MultiMap.Count(key)
+}
+
+func (t *Timed) Add(key []byte, value *goiso.SubGraph) error {
+	defer writes.Time()()
+	return t.MultiMap.Add(key, value)
+}
+
+func (t *Timed) Remove(key []byte, where func(*goiso.SubGraph) bool) error {
+	defer writes.Time()()
+	return t.MultiMap.Remove(key, where)
+}
@@ -0,0 +1,51 @@
+package subgraph_embedding
+
+import (
+	"github.com/timtadh/regrax/metrics"
+	"github.com/timtadh/regrax/types/digraph/subgraph"
+)
+
+var (
+	reads  = metrics.StoreReads("subgraph_embedding")
+	writes = metrics.StoreWrites("subgraph_embedding")
+)
+
+// Timed is a MultiMap which times its finds, adds and removes (in the
+// store_read_seconds and store_write_seconds metrics). Iterating over it is
+// not timed.
+type Timed struct {
+	MultiMap
+}
+
+func NewTimed(m MultiMap) *Timed {
+	return &Timed{m}
+}
+
+func (t *Timed) Find(key *subgraph.SubGraph) (Iterator, error) {
+	defer reads.Time()()
+	return t.MultiMap.Find(key)
+}
+
+func (t *Timed) DoFind(key *subgraph.SubGraph, do func(*subgraph.SubGraph, *subgraph.Embedding) error) error {
+	return Do(func() (Iterator, error) { return t.Find(key) }, do)
+}
+
+func (t *Timed) Has(key *subgraph.SubGraph) (bool, error) {
+	defer reads.Time()()
+	return t.MultiMap.Has(key)
+}
+
+func (t *Timed) Count(key *subgraph.SubGraph) (int, error) {
+	defer reads.Time()()
+	return t.MultiMap.Count(key)
+}
+
+func (t *Timed) Add(key *subgraph.SubGraph, value *subgraph.Embedding) error {
+	defer writes.Time()()
+	return t.MultiMap.Add(key, value)
+}
+
+func (t *Timed) Remove(key *subgraph.SubGraph, where func(*subgraph.Embedding) bool) error {
+	defer writes.Time()()
+	return t.MultiMap.Remove(key, where)
+}
@@ -0,0 +1,165 @@
+package digraph
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+import (
+	"github.com/timtadh/data-structures/hashtable"
+	"github.com/timtadh/data-structures/set"
+	"github.com/timtadh/data-structures/types"
+)
+
+import (
+	"github.com/timtadh/regrax/types/digraph/subgraph"
+)
+
+// The number of partial embeddings extended while looking for an embedding
+// through a sampled vertex before giving up on the vertex.
+const APPROX_SEARCH_BUDGET = 10000
+
+// An Estimate is an approximate MNI support computed by sampling. With
+// probability Confidence the support is in [Lower, Upper].
+type Estimate struct {
+	Support      int
+	Lower, Upper int
+	Samples      int
+	Confidence   float64
+	embs         []*subgraph.Embedding // the sampled embeddings
+}
+
+func (e *Estimate) String() string {
+	return fmt.Sprintf("~%d [%d, %d] (%d samples, %v confidence)", e.Support, e.Lower, e.Upper, e.Samples, e.Confidence)
+}
+
+// Approximation returns the estimate used for the support of the pattern or
+// nil if its support was computed exactly.
+func (dt *Digraph) Approximation(pattern *subgraph.SubGraph) *Estimate {
+	dt.lock.RLock()
+	defer dt.lock.RUnlock()
+	return dt.approx[string(pattern.Label())]
+}
+
+// estimateSupport estimates the MNI support of the pattern. For each vertex
+// of the pattern a sample of the vertices of G with its color are checked
+// for an embedding through them. The fraction of hits scales to an estimate
+// of the number of distinct images of the pattern vertex. The confidence
+// interval comes from the Hoeffding bound (split over the pattern vertices
+// so it holds for all of them at once). Searches which run out of budget
+// count as misses for the lower bound and as hits for the upper bound.
+//
+// The embeddings found along the way are returned as well.
+func estimateSupport(dt *Digraph, pattern *subgraph.SubGraph) (*Estimate, []*subgraph.Embedding) {
+	delta := (1 - dt.ApproxConfidence) / float64(len(pattern.V))
+	est := &Estimate{Confidence: dt.ApproxConfidence}
+	seen := make(map[string]bool)
+	embs := make([]*subgraph.Embedding, 0, dt.ApproxSamples)
+	for idx := range pattern.V {
+		color := pattern.V[idx].Color
+		candidates := make([]int, 0, len(dt.Indices.ColorIndex[dt.Indices.Base(color)]))
+		for _, id := range dt.Indices.ColorIndex[dt.Indices.Base(color)] {
+			if dt.Indices.HasLabels(id, color) {
+				candidates = append(candidates, id)
+			}
+		}
+		N := float64(len(candidates))
+		sample := candidates
+		if len(candidates) > dt.ApproxSamples {
+			sample = make([]int, 0, dt.ApproxSamples)
+			for _, i := range rand.Perm(len(candidates))[:dt.ApproxSamples] {
+				sample = append(sample, candidates[i])
+			}
+		}
+		search := pattern.SearchAt(dt.Indices, idx, APPROX_SEARCH_BUDGET)
+		hits := 0
+		unknown := 0
+		for _, id := range sample {
+			emb, exhausted := search(id)
+			if emb != nil {
+				hits++
+				if key := fmt.Sprint(emb.Ids); !seen[key] {
+					seen[key] = true
+					embs = append(embs, emb)
+				}
+			} else if !exhausted {
+				unknown++
+			}
+		}
+		n := float64(len(sample))
+		var support, lower, upper float64
+		if len(sample) == len(candidates) {
+			support = float64(hits)
+			lower = float64(hits)
+			upper = float64(hits + unknown)
+		} else {
+			eps := math.Sqrt(math.Log(2/delta) / (2 * n))
+			support = float64(hits) / n * N
+			lower = math.Max(0, (float64(hits)/n-eps)*N)
+			upper = math.Min(N, (float64(hits+unknown)/n+eps)*N)
+		}
+		est.Samples += len(sample)
+		if idx == 0 || int(support) < est.Support {
+			est.Support = int(support)
+		}
+		if idx == 0 || int(math.Floor(lower)) < est.Lower {
+			est.Lower = int(math.Floor(lower))
+		}
+		if idx == 0 || int(math.Ceil(upper)) < est.Upper {
+			est.Upper = int(math.Ceil(upper))
+		}
+	}
+	return est, embs
+}
+
+// approxExtsAndEmbs decides the pattern from an estimate of its support if
+// the confidence interval does not contain the support threshold. Since the
+// sampled embeddings do not cover every extension of a frequent pattern its
+// extensions are taken from the frequent edges.
+//
+// The sampled embeddings are not a complete embedding set so they are never
+// written to the embedding cache. The decided estimates are kept (with their
+// sample) instead so the pattern is decided the same way every time.
+func approxExtsAndEmbs(dt *Digraph, pattern *subgraph.SubGraph, unsupExts types.Set) (decided bool, support int, _ []*subgraph.Extension, _ []*subgraph.Embedding) {
+	label := string(pattern.Label())
+	dt.lock.RLock()
+	est, has := dt.approx[label]
+	dt.lock.RUnlock()
+	if !has {
+		var embs []*subgraph.Embedding
+		est, embs = estimateSupport(dt, pattern)
+		est.embs = embs
+	}
+	embs := est.embs
+	if est.Upper < dt.Support() {
+		est.embs = nil
+		dt.lock.Lock()
+		dt.approx[label] = est
+		dt.lock.Unlock()
+		return true, est.Support, nil, nil
+	} else if est.Lower < dt.Support() || len(embs) == 0 {
+		return false, 0, nil, nil
+	}
+	exts := set.NewSetMap(hashtable.NewLinearHash())
+	freqEdgeExts(dt, pattern, func(ext *subgraph.Extension) {
+		if !pattern.HasExtension(ext) {
+			exts.Add(ext)
+		}
+	})
+	extensions := make([]*subgraph.Extension, 0, exts.Size())
+	for i, next := exts.Items()(); next != nil; i, next = next() {
+		ext := i.(*subgraph.Extension)
+		if unsupExts != nil && unsupExts.Has(ext) {
+			continue
+		}
+		extensions = append(extensions, ext)
+	}
+	if dt.Mode&Temporal == Temporal {
+		extensions = temporalExts(pattern, extensions)
+	}
+	dt.lock.Lock()
+	dt.approx[label] = est
+	dt.lock.Unlock()
+	return true, est.Support, extensions, embs
+}
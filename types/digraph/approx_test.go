@@ -0,0 +1,82 @@
+package digraph
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+import (
+	"strings"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/types/digraph/subgraph"
+)
+
+// approxDigraph is the label set digraph with approximate support. Every
+// vertex is sampled so the estimates are exact.
+func approxDigraph(t testing.TB) *Digraph {
+	return labelSetDigraph(t, MNI|Approximate)
+}
+
+// edgeNodes gives the single edge patterns by their pretty label.
+func edgeNodes(t testing.TB, dt *Digraph) map[string]*EmbListNode {
+	nodes := patternNodes(t, dt)
+	for label := range nodes {
+		if !strings.HasPrefix(label, "{1:2}") {
+			delete(nodes, label)
+		}
+	}
+	return nodes
+}
+
+func TestApproximateSupport(t *testing.T) {
+	x := assert.New(t)
+	dt := approxDigraph(t)
+	defer dt.Close()
+	nodes := edgeNodes(t, dt)
+	plain := nodes["{1:2}(a)(b)[0->1:e]"]
+	labeled := nodes["{1:2}(a{x})(b)[0->1:e]"]
+	if !x.NotNil(plain, "%v", nodes) || !x.NotNil(labeled, "%v", nodes) {
+		return
+	}
+	x.Equal(20, plain.Support())
+	// the label set vertex is sampled from the a vertices with the label x
+	x.Equal(12, labeled.Support())
+	est := dt.Approximation(labeled.Pat)
+	if x.NotNil(est) {
+		x.Equal(12, est.Lower)
+		x.Equal(12, est.Upper)
+	}
+}
+
+func TestApproximateSampleNotCached(t *testing.T) {
+	x := assert.New(t)
+	dt := approxDigraph(t)
+	defer dt.Close()
+	n := edgeNodes(t, dt)["{1:2}(a)(b)[0->1:e]"]
+	if !x.NotNil(n) {
+		return
+	}
+	has, _, _, _, _, _, err := loadCachedExtsEmbs(dt, n.Pat)
+	x.Nil(err)
+	x.False(has)
+	// the pattern is decided from the same sample every time
+	support, _, embs, _, _, err := ExtsAndEmbs(dt, n.Pat, nil, nil, nil, dt.Mode, false)
+	x.Nil(err)
+	x.Equal(20, support)
+	again, _, embs2, _, _, err := ExtsAndEmbs(dt, n.Pat, nil, nil, nil, dt.Mode, false)
+	x.Nil(err)
+	x.Equal(support, again)
+	x.Equal(embs, embs2)
+}
+
+func TestApproximateNotTransactions(t *testing.T) {
+	x := assert.New(t)
+	_, err := NewDigraph(&config.Config{Support: 2}, &Config{
+		Mode:                MNI | Approximate | Transactions | ExtFromEmb,
+		EmbSearchStartPoint: subgraph.RandomStart,
+		ApproxSamples:       100,
+		ApproxConfidence:    .9,
+	})
+	x.Error(err)
+}
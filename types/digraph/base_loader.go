@@ -1,43 +1,97 @@
 package digraph
 
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
 import (
 	"github.com/timtadh/data-structures/errors"
 )
 
 import (
+	"github.com/timtadh/regrax/lattice"
 	"github.com/timtadh/regrax/types/digraph/digraph"
 )
 
+// vertex ids are only unique within an input file. When loading a
+// directory each file gets its own namespace so ids do not collide.
+type vertexId struct {
+	ns int
+	id int32
+}
+
 type baseLoader struct {
 	dt *Digraph
 	b *digraph.Builder
-	vidxs map[int32]int32
-	excluded map[int32]bool
+	labels *digraph.Labels
+	vidxs map[vertexId]int32
+	excluded map[vertexId]bool
+	ns int
+	file string
 }
 
-func newBaseLoader(dt *Digraph, b *digraph.Builder) *baseLoader {
+func newBaseLoader(dt *Digraph, b *digraph.Builder, labels *digraph.Labels) *baseLoader {
 	return &baseLoader{
 		dt: dt,
 		b: b,
-		vidxs: make(map[int32]int32),
-		excluded: make(map[int32]bool),
+		labels: labels,
+		vidxs: make(map[vertexId]int32),
+		excluded: make(map[vertexId]bool),
+	}
+}
+
+// startFile switches the loader into the namespace of the given input. If
+// the input is named then the name is recorded as the "file" attribute of
+// each vertex loaded from it and the vertex is assigned to the transaction
+// for that file.
+func (l *baseLoader) startFile(ns int, input lattice.NamedInput) {
+	l.ns = ns
+	l.file = input.Name
+	if l.file != "" && l.dt.VertexTx == nil {
+		l.dt.VertexTx = make([]int, 0, cap(l.b.V))
 	}
 }
 
 func (l *baseLoader) addVertex(id int32, color int, label string, attrs map[string]interface{}) (err error) {
+	vid := vertexId{l.ns, id}
 	if l.dt.Include != nil && !l.dt.Include.MatchString(label) {
-		l.excluded[id] = true
+		l.excluded[vid] = true
 		return nil
 	}
 	if l.dt.Exclude != nil && l.dt.Exclude.MatchString(label) {
-		l.excluded[id] = true
+		l.excluded[vid] = true
 		return nil
 	}
 	vertex := l.b.AddVertex(color)
-	l.vidxs[id] = int32(vertex.Idx)
+	l.vidxs[vid] = int32(vertex.Idx)
+	if l.dt.VertexTx != nil {
+		l.dt.VertexTx = append(l.dt.VertexTx, l.ns)
+	}
+	if l.dt.WeightAttr != "" {
+		w, err := l.weight(attrs)
+		if err != nil {
+			return errors.Errorf("vertex %v: %v", id, err)
+		}
+		l.dt.VertexWeight = append(l.dt.VertexWeight, w)
+	}
+	if l.dt.Mode&LabelSets == LabelSets {
+		extra, err := l.extraLabels(attrs)
+		if err != nil {
+			return errors.Errorf("vertex %v: %v", id, err)
+		}
+		l.dt.VertexLabels = append(l.dt.VertexLabels, extra)
+	}
+	if l.file != "" && attrs == nil {
+		attrs = make(map[string]interface{})
+	}
 	if l.dt.NodeAttrs != nil && attrs != nil {
 		attrs["oid"] = id
 		attrs["color"] = color
+		if l.file != "" {
+			attrs["file"] = l.file
+		}
 		err = l.dt.NodeAttrs.Add(int32(vertex.Idx), attrs)
 		if err != nil {
 			return err
@@ -46,8 +100,10 @@ func (l *baseLoader) addVertex(id int32, color int, label string, attrs map[stri
 	return nil
 }
 
-func (l *baseLoader) addEdge(sid, tid int32, color int, label string) (err error) {
-	if l.excluded[sid] || l.excluded[tid] {
+func (l *baseLoader) addEdge(sid, tid int32, color int, label string, attrs map[string]interface{}) (err error) {
+	src := vertexId{l.ns, sid}
+	targ := vertexId{l.ns, tid}
+	if l.excluded[src] || l.excluded[targ] {
 		return nil
 	}
 	if l.dt.Include != nil && !l.dt.Include.MatchString(label) {
@@ -56,13 +112,102 @@ func (l *baseLoader) addEdge(sid, tid int32, color int, label string) (err error
 	if l.dt.Exclude != nil && l.dt.Exclude.MatchString(label) {
 		return nil
 	}
-	if sidx, has := l.vidxs[sid]; !has {
-		return errors.Errorf("unknown src id %v", tid)
-	} else if tidx, has := l.vidxs[tid]; !has{
+	if sidx, has := l.vidxs[src]; !has {
+		return errors.Errorf("unknown src id %v", sid)
+	} else if tidx, has := l.vidxs[targ]; !has{
 		return errors.Errorf("unknown targ id %v", tid)
 	} else {
 		l.b.AddEdge(&l.b.V[sidx], &l.b.V[tidx], color)
 	}
+	if l.dt.WeightAttr != "" {
+		w, err := l.weight(attrs)
+		if err != nil {
+			return errors.Errorf("edge %v -> %v: %v", sid, tid, err)
+		}
+		l.dt.EdgeWeight = append(l.dt.EdgeWeight, w)
+	}
+	if l.dt.Mode&Temporal == Temporal {
+		t, err := l.time(attrs)
+		if err != nil {
+			return errors.Errorf("edge %v -> %v: %v", sid, tid, err)
+		}
+		l.dt.EdgeTime = append(l.dt.EdgeTime, t)
+	}
 	return nil
 }
 
+// extraLabels reads the colors of the extra labels of a vertex from its
+// labels attribute. It is either a list of labels (veg) or a comma separated
+// string (dot).
+func (l *baseLoader) extraLabels(attrs map[string]interface{}) ([]int, error) {
+	var names []string
+	switch v := attrs["labels"].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		names = strings.Split(v, ",")
+	case []interface{}:
+		for _, x := range v {
+			name, ok := x.(string)
+			if !ok {
+				return nil, errors.Errorf("label %v is not a string", x)
+			}
+			names = append(names, name)
+		}
+	default:
+		return nil, errors.Errorf("labels %v are not a list", v)
+	}
+	extra := make([]int, 0, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			extra = append(extra, l.labels.Color(name))
+		}
+	}
+	return extra, nil
+}
+
+// time reads the timestamp of an edge from its time attribute.
+func (l *baseLoader) time(attrs map[string]interface{}) (int64, error) {
+	val, has := attrs["time"]
+	if !has {
+		return 0, errors.Errorf("temporal mode needs a time on every edge")
+	}
+	t, err := number(val)
+	if err != nil {
+		return 0, errors.Errorf("time %v", err)
+	}
+	return int64(t), nil
+}
+
+// weight reads the weight attribute. Vertices and edges without the
+// attribute weigh 1.
+func (l *baseLoader) weight(attrs map[string]interface{}) (float64, error) {
+	val, has := attrs[l.dt.WeightAttr]
+	if !has {
+		return 1, nil
+	}
+	w, err := number(val)
+	if err != nil {
+		return 0, errors.Errorf("weight %v", err)
+	} else if w < 0 {
+		return 0, errors.Errorf("weight %v is negative", w)
+	}
+	return w, nil
+}
+
+// number reads a numeric attribute value. Values parsed from json are
+// json.Numbers and values from dot files are strings.
+func number(val interface{}) (float64, error) {
+	switch v := val.(type) {
+	case json.Number:
+		return v.Float64()
+	case string:
+		return strconv.ParseFloat(v, 64)
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	}
+	return 0, errors.Errorf("%v is not a number", val)
+}
+
@@ -8,6 +8,7 @@ import (
 
 import (
 	"github.com/timtadh/regrax/lattice"
+	"github.com/timtadh/regrax/progress"
 	"github.com/timtadh/regrax/stores/bytes_bytes"
 	"github.com/timtadh/regrax/stores/bytes_int"
 )
@@ -46,8 +47,10 @@ func cachedAdj(n Node, dt *Digraph, count bytes_int.MultiMap, cache bytes_bytes.
 	if has, err := count.Has(key); err != nil {
 		return nil, false, err
 	} else if !has {
+		progress.CacheMisses.Inc()
 		return nil, false, nil
 	}
+	progress.CacheHits.Inc()
 	// errors.Logf("DEBUG", "loading %v", n)
 	err = cache.DoFind(key, func(_, adj []byte) (err error) {
 		// WHY DO WE NEED TO UNLOCK?
@@ -12,9 +12,18 @@ import (
 	"github.com/timtadh/regrax/types/digraph/subgraph"
 )
 
-func isCanonicalExtension(cur *subgraph.SubGraph, ext *subgraph.SubGraph) (bool, error) {
+func isCanonicalExtension(dt *Digraph, cur *subgraph.SubGraph, ext *subgraph.SubGraph) (bool, error) {
 	// errors.Logf("DEBUG", "is %v a canonical ext of %v", ext.Label(), n)
-	parent, err := firstParent(subgraph.Build(len(ext.V), len(ext.E)).From(ext))
+	// extra labels are dropped before any edge
+	parent := firstLabelParent(dt, subgraph.Build(len(ext.V), len(ext.E)).From(ext))
+	var err error
+	if parent == nil && dt.Mode&Induced == Induced {
+		if parents := InducedParents(subgraph.Build(len(ext.V), len(ext.E)).From(ext)); len(parents) > 0 {
+			parent = parents[0]
+		}
+	} else if parent == nil {
+		parent, err = firstParent(subgraph.Build(len(ext.V), len(ext.E)).From(ext))
+	}
 	if err != nil {
 		return false, err
 	} else if parent == nil {
@@ -81,3 +90,21 @@ func AllParents(b *subgraph.Builder) (parents []*subgraph.Builder, err error) {
 	}
 	return parents, nil
 }
+
+// InducedParents removes each vertex (with all of its edges) whose removal
+// leaves the pattern connected. Induced mode grows the patterns a vertex at
+// a time so these are the parents.
+func InducedParents(b *subgraph.Builder) []*subgraph.Builder {
+	parents := make([]*subgraph.Builder, 0, len(b.V))
+	if len(b.V) <= 1 {
+		return parents
+	}
+	for i := len(b.V) - 1; i >= 0; i-- {
+		nb := b.Copy()
+		nb.RemoveVertex(i)
+		if nb.Connected() {
+			parents = append(parents, nb)
+		}
+	}
+	return parents
+}
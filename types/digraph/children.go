@@ -12,6 +12,7 @@ import (
 
 import (
 	"github.com/timtadh/regrax/lattice"
+	"github.com/timtadh/regrax/progress"
 	"github.com/timtadh/regrax/stores/bytes_bytes"
 	"github.com/timtadh/regrax/stores/bytes_int"
 	"github.com/timtadh/regrax/types/digraph/subgraph"
@@ -19,7 +20,7 @@ import (
 
 type Node interface {
 	lattice.Node
-	New(*subgraph.SubGraph, []*subgraph.Extension, []*subgraph.Embedding, []map[int]bool, subgraph.VertexEmbeddings) Node
+	New(*subgraph.SubGraph, int, []*subgraph.Extension, []*subgraph.Embedding, []map[int]bool, subgraph.VertexEmbeddings) Node
 	Label() []byte
 	Extensions() ([]*subgraph.Extension, error)
 	Embeddings() ([]*subgraph.Embedding, error)
@@ -63,7 +64,7 @@ func canonChildren(n Node) (nodes []lattice.Node, err error) {
 	}
 	sg := n.SubGraph()
 	nodes, err = findChildren(n, func(pattern *subgraph.SubGraph) (bool, error) {
-		return isCanonicalExtension(sg, pattern)
+		return isCanonicalExtension(dt, sg, pattern)
 	}, false)
 	if err != nil {
 		return nil, err
@@ -168,7 +169,7 @@ func findChildren(n Node, allow func(*subgraph.SubGraph) (bool, error), debug bo
 					errors.Logf("CHILDREN-DEBUG", "pattern %v support %v exts %v", pattern.Pretty(dt.Labels), len(embs), len(exts))
 				}
 				if support >= dt.Support() {
-					nodeCh <- nodeEp{n.New(pattern, exts, embs, overlap, dropped), vord}
+					nodeCh <- nodeEp{n.New(pattern, support, exts, embs, overlap, dropped), vord}
 				} else {
 					epCh <- ep
 				}
@@ -207,6 +208,7 @@ func extendNode(dt *Digraph, n Node, debug bool) (*hashtable.LinearHash, error)
 	if debug {
 		errors.Logf("DEBUG", "n.SubGraph %v", n.SubGraph())
 	}
+	progress.Expanded.Inc()
 	sg := n.SubGraph()
 	b := subgraph.Build(len(sg.V), len(sg.E)).From(sg)
 	extPoints, err := n.Extensions()
@@ -217,7 +219,11 @@ func extendNode(dt *Digraph, n Node, debug bool) (*hashtable.LinearHash, error)
 	for _, ep := range extPoints {
 		bc := b.Copy()
 		bc.Extend(ep)
-		if len(bc.V) > dt.MaxVertices {
+		if len(bc.V) > dt.MaxVertices || len(bc.E) > dt.MaxEdges {
+			// an induced extension may add several edges
+			continue
+		}
+		if !dt.allowedShape(bc) {
 			continue
 		}
 		vord, eord := bc.CanonicalPermutation()
@@ -4,7 +4,6 @@ import "testing"
 import "github.com/stretchr/testify/assert"
 
 import (
-	"fmt"
 	"bytes"
 	"encoding/binary"
 	"math/rand"
@@ -15,11 +14,11 @@ import (
 import (
 	"github.com/timtadh/data-structures/errors"
 	"github.com/timtadh/data-structures/set"
-	"github.com/timtadh/goiso"
 )
 
 import (
 	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/types/digraph/digraph"
 	"github.com/timtadh/regrax/types/digraph/subgraph"
 )
 
@@ -36,28 +35,25 @@ func init() {
 	}
 }
 
-func randomGraph(t testing.TB, V, E int, vlabels, elabels []string) (*Digraph, *goiso.Graph, *goiso.SubGraph, *subgraph.SubGraph, *EmbListNode) {
-	Graph := goiso.NewGraph(10, 10)
-	G := &Graph
+func randomGraph(t testing.TB, V, E int, vlabels, elabels []string) (*Digraph, *EmbListNode) {
+	labels := digraph.NewLabels()
+	b := digraph.Build(V, E)
 
-	vidxs := make([]int, 0, V)
-	vertices := make([]*goiso.Vertex, 0, V)
+	vertices := make([]*digraph.Vertex, 0, V)
 	for i := 0; i < V; i++ {
-		v := G.AddVertex(i, vlabels[rand.Intn(len(vlabels))])
-		vertices = append(vertices, v)
-		vidxs = append(vidxs, v.Idx)
+		vertices = append(vertices, b.AddVertex(labels.Color(vlabels[rand.Intn(len(vlabels))])))
 	}
+	added := make(map[[3]int]bool)
 	for i := 0; i < E; i++ {
 		src := rand.Intn(len(vertices))
 		targ := rand.Intn(len(vertices))
-		elabel := rand.Intn(len(elabels))
-		if !G.HasEdge(vertices[src], vertices[targ], elabels[elabel]) {
-			G.AddEdge(vertices[src], vertices[targ], elabels[elabel])
+		elabel := labels.Color(elabels[rand.Intn(len(elabels))])
+		if !added[[3]int{src, targ, elabel}] {
+			added[[3]int{src, targ, elabel}] = true
+			b.AddEdge(vertices[src], vertices[targ], elabel)
 		}
 	}
 
-	sg, _ := G.SubGraph(vidxs, nil)
-
 	// make config
 	conf := &config.Config{
 		Support: 2,
@@ -67,10 +63,10 @@ func randomGraph(t testing.TB, V, E int, vlabels, elabels []string) (*Digraph, *
 	// make the *Digraph
 	dt, err := NewDigraph(conf, &Config{
 		MinEdges: 0,
-		MaxEdges: len(G.E),
+		MaxEdges: len(b.E),
 		MinVertices: 0,
-		MaxVertices: len(G.V),
-		Mode: Automorphs | Caching | ExtensionPruning | EmbeddingPruning | ExtFromFreqEdges,
+		MaxVertices: len(b.V),
+		Mode: MNI | Caching | ExtensionPruning | EmbeddingPruning | ExtFromFreqEdges,
 		EmbSearchStartPoint: subgraph.RandomStart,
 	})
 	if err != nil {
@@ -78,18 +74,12 @@ func randomGraph(t testing.TB, V, E int, vlabels, elabels []string) (*Digraph, *
 		t.Fatal(err)
 	}
 
-	err = dt.Init(G)
+	err = dt.Init(b, labels)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	f, err := os.Create("/tmp/random-graph.dot")
-	if err == nil {
-		fmt.Fprintf(f, "%v", G)
-		f.Close()
-	}
-
-	return dt, G, sg, subgraph.FromEmbedding(sg), RootEmbListNode(dt)
+	return dt, RootEmbListNode(dt)
 }
 
 func BenchmarkEmbList(b *testing.B) {
@@ -100,11 +90,12 @@ func BenchmarkEmbList(b *testing.B) {
 		vlabels := []string{"a", "b", "c", "d", "e", "f"}
 		elabels := []string{"g", "h", "i"}
 		V := 100
-		_, _, _, _, eroot := randomGraph(
+		dt, eroot := randomGraph(
 			b, V, int(float64(V)*2.25), vlabels, elabels)
 		b.StartTimer()
 		dfs(b, x, eroot)
 		b.StopTimer()
+		dt.Close()
 	}
 }
 
@@ -113,7 +104,8 @@ func TestVerifyEmbList(t *testing.T) {
 	vlabels := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i"}
 	elabels := []string{"j", "k"}
 	V := 350
-	_, _, _, _, eroot := randomGraph(t, V, int(float64(V)*1.5), vlabels, elabels)
+	dt, eroot := randomGraph(t, V, int(float64(V)*1.5), vlabels, elabels)
+	defer dt.Close()
 	dfs(t, x, eroot)
 }
 
@@ -242,3 +234,84 @@ func checkKid(t testing.TB, x *assert.Assertions, parent, kid Node) {
 		}
 	}
 }
+
+// loopDigraph has four copies of a vertex with a self-loop and two
+// differently labeled parallel edges to another vertex. Half of the copies
+// repeat one of the parallel edges. Each copy is a transaction and every
+// vertex and edge weighs 1.
+func loopDigraph(t testing.TB, mode Mode) *Digraph {
+	labels := digraph.NewLabels()
+	b := digraph.Build(8, 14)
+	for i := 0; i < 4; i++ {
+		x := b.AddVertex(labels.Color("a"))
+		y := b.AddVertex(labels.Color("b"))
+		b.AddEdge(x, x, labels.Color("loop"))
+		b.AddEdge(x, y, labels.Color("call"))
+		b.AddEdge(x, y, labels.Color("jump"))
+		if i%2 == 0 {
+			b.AddEdge(x, y, labels.Color("call"))
+		}
+	}
+	dc := &Config{
+		Mode:                mode | ExtFromEmb | Caching,
+		EmbSearchStartPoint: subgraph.RandomStart,
+	}
+	if mode&Weighted != 0 {
+		dc.WeightAttr = "weight"
+	}
+	dt, err := NewDigraph(&config.Config{Support: 2}, dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode&Transactions == Transactions {
+		dt.VertexTx = []int{0, 0, 1, 1, 2, 2, 3, 3}
+	}
+	if mode&Weighted != 0 {
+		dt.VertexWeight = []float64{1, 1, 1, 1, 1, 1, 1, 1}
+		dt.EdgeWeight = make([]float64, 14)
+		for i := range dt.EdgeWeight {
+			dt.EdgeWeight[i] = 1
+		}
+	}
+	err = dt.Init(b, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dt
+}
+
+func TestSelfLoopParallelCountModes(t *testing.T) {
+	// the support of each pattern (with |V| vertices and |E| edges). every
+	// pattern has one embedding per copy.
+	modes := map[string]struct {
+		mode    Mode
+		support func(V, E int) int
+	}{
+		"MNI":          {MNI, func(V, E int) int { return 4 }},
+		"FIS":          {FIS, func(V, E int) int { return 4 }},
+		"GIS":          {GIS, func(V, E int) int { return 4 }},
+		"MIS":          {MIS, func(V, E int) int { return 4 }},
+		"Transactions": {MNI | Transactions, func(V, E int) int { return 4 }},
+		"WMNI":         {WeightedMNI, func(V, E int) int { return 4 }},
+		"WMIN":         {WeightedMin, func(V, E int) int { return 4 }},
+		"WSUM":         {WeightedSum, func(V, E int) int { return 4 * (V + E) }},
+	}
+	for name, c := range modes {
+		x := assert.New(t)
+		dt := loopDigraph(t, c.mode)
+		dfs(t, x, RootEmbListNode(dt))
+		nodes := patternNodes(t, dt)
+		maxEdges := 0
+		for label, n := range nodes {
+			if len(n.Pat.E) > maxEdges {
+				maxEdges = len(n.Pat.E)
+			}
+			x.Equal(c.support(len(n.Pat.V), len(n.Pat.E)), n.Support(), name+" "+label)
+		}
+		// 2 vertices, the loop, the 2 parallel edges, the 3 pairs of those
+		// edges and the whole copy. The repeated edge adds no pattern.
+		x.Equal(9, len(nodes), name)
+		x.Equal(3, maxEdges, name)
+		dt.Close()
+	}
+}
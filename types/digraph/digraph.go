@@ -18,6 +18,7 @@ import (
 	"github.com/timtadh/regrax/stores/bytes_extension"
 	"github.com/timtadh/regrax/stores/bytes_int"
 	"github.com/timtadh/regrax/stores/int_json"
+	"github.com/timtadh/regrax/stores/ints_ints"
 	"github.com/timtadh/regrax/stores/subgraph_embedding"
 	"github.com/timtadh/regrax/stores/subgraph_overlap"
 	"github.com/timtadh/regrax/types/digraph/digraph"
@@ -31,12 +32,25 @@ type Config struct {
 	Mode                     Mode
 	Include, Exclude         *regexp.Regexp
 	EmbSearchStartPoint      subgraph.EmbSearchStartPoint
+	ApproxSamples            int     // vertices sampled per pattern vertex (Approximate mode)
+	ApproxConfidence         float64 // confidence of the support bounds (Approximate mode)
+	MaxEmbeddings            int     // embeddings kept per pattern (0 keeps all of them)
+	Shape                    Shape   // restrict the shape of the patterns (0 allows any)
+	MaxInDegree              int     // max in degree of a pattern vertex (0 is unbounded)
+	MaxOutDegree             int     // max out degree of a pattern vertex (0 is unbounded)
+	WeightAttr               string  // vertex and edge attribute holding their weights
+	TimeWindow               int64   // max time between the edges of an embedding (Temporal mode, 0 is unbounded)
 }
 
 type Digraph struct {
 	Config
 	config                   *config.Config
 	G                        *digraph.Digraph
+	VertexTx                 []int // the transaction (input file) of each vertex in G
+	VertexWeight             []float64 // the weight of each vertex in G (weighted modes)
+	EdgeWeight               []float64 // the weight of each edge in G (weighted modes)
+	EdgeTime                 []int64 // the timestamp of each edge in G (temporal mode)
+	VertexLabels             [][]int // the extra labels of each vertex in G (label set mode)
 	Labels                   *digraph.Labels
 	FrequentVertices         []*EmbListNode
 	NodeAttrs                int_json.MultiMap
@@ -52,7 +66,12 @@ type Digraph struct {
 	CanonKids                bytes_bytes.MultiMap
 	CanonKidCount            bytes_int.MultiMap
 	Frequency                bytes_int.MultiMap
+	Truncated                bytes_int.MultiMap // embedding counts of the truncated patterns (see MaxEmbeddings)
+	LabelSets                ints_ints.MultiMap // color -> base color and extra labels of the cached label sets
 	Indices                  *digraph.Indices
+	approx                   map[string]*Estimate
+	weighted                 map[string]float64
+	truncated                map[string]int
 	pool                     *pool.Pool
 	lock                     sync.RWMutex
 }
@@ -70,6 +89,41 @@ func NewDigraph(config *config.Config, dc *Config) (g *Digraph, err error) {
 	if dc.MinVertices > dc.MaxVertices {
 		dc.MinVertices = dc.MaxVertices - 1
 	}
+	if dc.Mode&Approximate == Approximate {
+		if dc.Mode&MNI == 0 {
+			return nil, errors.Errorf("approximate support is only available for MNI support")
+		} else if dc.Mode&Transactions == Transactions {
+			return nil, errors.Errorf("approximate support cannot be combined with transaction support")
+		} else if dc.ApproxSamples <= 0 {
+			return nil, errors.Errorf("approximate support needs a positive number of samples")
+		} else if dc.ApproxConfidence <= 0 || dc.ApproxConfidence >= 1 {
+			return nil, errors.Errorf("approximate support confidence must be in (0, 1)")
+		}
+	}
+	if dc.Mode&Induced == Induced {
+		if dc.Mode&ExtFromEmb == 0 {
+			return nil, errors.Errorf("induced mode extends the patterns from their embeddings")
+		} else if dc.Mode&(Approximate|Temporal) != 0 {
+			return nil, errors.Errorf("induced mode cannot be combined with approximate support or temporal mode")
+		}
+	}
+	if dc.Mode&Weighted != 0 && dc.WeightAttr == "" {
+		return nil, errors.Errorf("weighted support needs a weight attribute")
+	}
+	if dc.Mode&(WeightedSum|WeightedMin) != 0 {
+		// a child may outweigh its parent so an infrequent parent does not
+		// make its children infrequent
+		if dc.Mode&ExtensionPruning == ExtensionPruning {
+			return nil, errors.Errorf("extension pruning needs an anti-monotone support (WSUM and WMIN are not)")
+		}
+		errors.Logf("WARN", "WSUM and WMIN are not anti-monotone: patterns below a parent under the threshold may be missed")
+	}
+	if dc.Mode&Temporal == Temporal && dc.Mode&ExtensionPruning == ExtensionPruning {
+		return nil, errors.Errorf("extension pruning is not available in temporal mode")
+	}
+	if dc.TimeWindow < 0 {
+		return nil, errors.Errorf("the time window must not be negative")
+	}
 	nodeAttrs, err := config.IntJsonMultiMap("digraph-node-attrs")
 	if err != nil {
 		return nil, err
@@ -131,6 +185,20 @@ func NewDigraph(config *config.Config, dc *Config) (g *Digraph, err error) {
 	if err != nil {
 		return nil, err
 	}
+	var labelSets ints_ints.MultiMap
+	if dc.Mode&LabelSets == LabelSets {
+		labelSets, err = config.IntsIntsMultiMap("digraph-label-sets")
+		if err != nil {
+			return nil, err
+		}
+	}
+	var truncated bytes_int.MultiMap
+	if dc.MaxEmbeddings > 0 {
+		truncated, err = config.BytesIntMultiMap("digraph-truncated-count")
+		if err != nil {
+			return nil, err
+		}
+	}
 	g = &Digraph{
 		Config: *dc,
 		NodeAttrs:     nodeAttrs,
@@ -146,16 +214,44 @@ func NewDigraph(config *config.Config, dc *Config) (g *Digraph, err error) {
 		CanonKids:     canonKids,
 		CanonKidCount: canonKidCount,
 		Frequency:     frequency,
+		Truncated:     truncated,
+		LabelSets:     labelSets,
 		config: config,
+		approx: make(map[string]*Estimate),
+		weighted: make(map[string]float64),
+		truncated: make(map[string]int),
 		pool: pool.New(config.Workers()),
 	}
 	return g, nil
 }
 
 func (dt *Digraph) Init(b *digraph.Builder, l *digraph.Labels) (err error) {
+	if dt.Mode&Transactions == Transactions && dt.VertexTx == nil {
+		return errors.Errorf("transaction support requires a directory of input graphs")
+	}
+	if dt.Mode&Transactions == Transactions && dt.Mode&(Weighted|Approximate) != 0 {
+		return errors.Errorf("transaction support cannot be combined with weighted or approximate support")
+	}
+	if dt.Mode&Temporal == Temporal && len(dt.EdgeTime) != len(b.E) {
+		return errors.Errorf("temporal mode requires a time on every edge")
+	}
+	// a color (or edge) occurring less often than the support may still
+	// weigh enough so under weighted support nothing is pruned by count
+	minSupport := dt.config.Support
+	if dt.Mode&Weighted != 0 {
+		minSupport = 1
+	}
 	dt.lock.Lock()
 	// i := digraph.NewIndices(b, dt.config.Support, dt.Mode & ExtFromFreqEdges == ExtFromFreqEdges)
-	i := digraph.NewIndices(b, dt.config.Support)
+	i := digraph.NewIndices(b, minSupport)
+	i.Homomorphic = dt.Mode&Homomorphism == Homomorphism
+	if dt.Mode&Temporal == Temporal {
+		i.EdgeTime = dt.EdgeTime
+		i.TimeWindow = dt.TimeWindow
+	}
+	if dt.Mode&LabelSets == LabelSets {
+		i.IndexLabels(l, dt.VertexLabels, minSupport)
+	}
 	errors.Logf("DEBUG", "done building indices")
 	dt.G = i.G
 	dt.Indices = i
@@ -165,11 +261,16 @@ func (dt *Digraph) Init(b *digraph.Builder, l *digraph.Labels) (err error) {
 	errors.Logf("DEBUG", "computing starting points")
 	for color, _ := range dt.Indices.ColorIndex {
 		sg := subgraph.Build(1, 0).FromVertex(color).Build()
-		_, exts, embs, _, _, err := ExtsAndEmbs(dt, sg, nil, nil, nil, dt.Mode, false)
+		support, exts, embs, _, _, err := ExtsAndEmbs(dt, sg, nil, nil, nil, dt.Mode, false)
 		if err != nil {
 			return err
 		}
-		n := NewEmbListNode(dt, sg, exts, embs, nil, nil)
+		if dt.Mode&(Transactions|Weighted) != 0 && support < dt.Support() {
+			// the color index only knows how often a color occurs not in
+			// how many transactions (or how much it weighs)
+			continue
+		}
+		n := NewEmbListNode(dt, sg, support, exts, embs, nil, nil)
 		dt.lock.Lock()
 		dt.FrequentVertices = append(dt.FrequentVertices, n)
 		dt.lock.Unlock()
@@ -177,6 +278,48 @@ func (dt *Digraph) Init(b *digraph.Builder, l *digraph.Labels) (err error) {
 	return nil
 }
 
+// truncatedCount is the number of embeddings the pattern had before its
+// embedding list was truncated. Patterns loaded from the cache find their
+// count in the Truncated store.
+func (dt *Digraph) truncatedCount(pattern *subgraph.SubGraph) (int, bool) {
+	dt.lock.RLock()
+	defer dt.lock.RUnlock()
+	label := pattern.Label()
+	if count, has := dt.truncated[string(label)]; has {
+		return count, true
+	}
+	if dt.Truncated == nil {
+		return 0, false
+	}
+	count, has := 0, false
+	err := dt.Truncated.DoFind(label, func(_ []byte, c int32) error {
+		count, has = int(c), true
+		return nil
+	})
+	if err != nil {
+		errors.Logf("ERROR", "%v", err)
+		return 0, false
+	}
+	return count, has
+}
+
+// DoEmbeddings calls do with every embedding of the pattern in G (not only
+// the ones its node keeps). In induced mode only the induced embeddings are
+// given as only those are counted by the miner.
+func (dt *Digraph) DoEmbeddings(pattern *subgraph.SubGraph, do func(*subgraph.Embedding) error) error {
+	ei, _ := pattern.IterEmbeddings(dt.EmbSearchStartPoint, dt.Indices, nil, nil, nil)
+	if dt.Mode&Induced == Induced {
+		ei = subgraph.FilterInduced(ei, dt.G)
+	}
+	for emb, next := ei(false); next != nil; emb, next = next(false) {
+		err := do(emb)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (g *Digraph) Support() int {
 	return g.config.Support
 }
@@ -195,7 +338,7 @@ func (g *Digraph) MinimumLevel() int {
 }
 
 func RootEmbListNode(g *Digraph) *EmbListNode {
-	return NewEmbListNode(g, subgraph.EmptySubGraph(), nil, nil, nil, nil)
+	return NewEmbListNode(g, subgraph.EmptySubGraph(), 0, nil, nil, nil, nil)
 }
 
 func (g *Digraph) Root() lattice.Node {
@@ -244,5 +387,11 @@ func (g *Digraph) Close() error {
 	}
 	g.NodeAttrs.Close()
 	g.Frequency.Close()
+	if g.Truncated != nil {
+		g.Truncated.Close()
+	}
+	if g.LabelSets != nil {
+		g.LabelSets.Close()
+	}
 	return nil
 }
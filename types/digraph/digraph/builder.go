@@ -44,23 +44,25 @@ func (b *Builder) Build(indexVertex func(*Vertex), indexEdge func(*Edge)) *Digra
 		parents := 0
 		for j, e := range b.Adj[i] {
 			g.Adj[i][j] = e
+			if b.E[e].Src != i && b.E[e].Targ != i {
+				panic("edge on neither source or target")
+			}
 			if b.E[e].Src == i {
 				kids++
-			} else if b.E[e].Targ == i {
+			}
+			if b.E[e].Targ == i {
 				parents++
-			} else {
-				panic("edge on neither source or target")
 			}
 		}
 		g.Kids[i] = make([]int, 0, kids)
 		g.Parents[i] = make([]int, 0, parents)
+		// a self-loop is both a kid and a parent edge of its vertex
 		for _, e := range b.Adj[i] {
 			if b.E[e].Src == i {
 				g.Kids[i] = append(g.Kids[i], e)
-			} else if b.E[e].Targ == i {
+			}
+			if b.E[e].Targ == i {
 				g.Parents[i] = append(g.Parents[i], e)
-			} else {
-				panic("edge on neither source or target")
 			}
 		}
 		if indexVertex != nil {
@@ -117,7 +119,10 @@ func (b *Builder) AddEdge(u, v *Vertex, color int) *Edge {
 	}
 	e := &b.E[idx]
 	b.Adj[e.Src] = append(b.Adj[e.Src], idx)
-	b.Adj[e.Targ] = append(b.Adj[e.Targ], idx)
+	if e.Targ != e.Src {
+		// a self-loop is only adjacent to its vertex once
+		b.Adj[e.Targ] = append(b.Adj[e.Targ], idx)
+	}
 	b.EdgeColors[color]++
 	return e
 }
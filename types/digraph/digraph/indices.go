@@ -1,5 +1,9 @@
 package digraph
 
+import (
+	"sort"
+)
+
 import (
 	"github.com/timtadh/data-structures/errors"
 )
@@ -24,6 +28,12 @@ type Indices struct {
 	EdgesToColor    map[int][]Colors       // freq targ-colors -> color triples
 	VertexColors    map[int]int            // the color frequency for vertices
 	EdgeColors      map[int]int            // the color frequency for edges
+	Homomorphic     bool                   // embeddings need not be injective
+	EdgeTime        []int64                // the timestamp of each edge in G (temporal mode)
+	TimeWindow      int64                  // max time between the edges of an embedding (0 is unbounded)
+	Labels          *Labels                // label set colors (label set mode, nil otherwise)
+	VertexLabels    [][]int                // the sorted extra labels of each vertex in G (label set mode)
+	FreqLabels      map[int][]int          // color -> extra labels on at least min support vertices of the color
 }
 
 func NewIndices(b *Builder, minSupport int) *Indices {
@@ -61,9 +71,16 @@ func NewIndices(b *Builder, minSupport int) *Indices {
 			if i.TargIndex[targKey] == nil {
 				i.TargIndex[targKey] = make([]int, 0, len(b.Adj[e.Targ]))
 			}
-			i.EdgeIndex[edge] = e
+			// parallel edges of the same color (eg. repeated interactions
+			// in a temporal graph) connect the same vertices so they are
+			// only indexed (and counted) once. Self-loops and parallel
+			// edges of different colors are indexed like any other edge.
+			if _, has := i.EdgeIndex[edge]; has {
+				return
+			}
 			i.SrcIndex[srcKey] = append(i.SrcIndex[srcKey], e.Targ)
 			i.TargIndex[targKey] = append(i.TargIndex[targKey], e.Src)
+			i.EdgeIndex[edge] = e
 			i.EdgeCounts[colorKey] += 1
 			// only add to frequent edges exactly when this colorKey has
 			// surpassed min_support.
@@ -86,6 +103,63 @@ func NewIndices(b *Builder, minSupport int) *Indices {
 	return i
 }
 
+// IndexLabels adds the extra labels of the vertices of G (indexed as G.V).
+// A pattern vertex then matches the vertices of G with the same base color
+// whose labels contain its extra labels. So the label set of a pattern
+// vertex is contained in the label set of the vertices it matches but it
+// always holds their base color: there are no pattern vertices made of extra
+// labels only.
+func (i *Indices) IndexLabels(labels *Labels, vertexLabels [][]int, minSupport int) {
+	i.Labels = labels
+	i.VertexLabels = make([][]int, len(vertexLabels))
+	type colorLabel struct {
+		color, label int
+	}
+	counts := make(map[colorLabel]int)
+	for id, extra := range vertexLabels {
+		i.VertexLabels[id] = labels.Extra(labels.SetColor(i.G.V[id].Color, extra))
+		for _, l := range i.VertexLabels[id] {
+			counts[colorLabel{i.G.V[id].Color, l}]++
+		}
+	}
+	i.FreqLabels = make(map[int][]int)
+	for key, count := range counts {
+		if count >= minSupport {
+			i.FreqLabels[key.color] = append(i.FreqLabels[key.color], key.label)
+		}
+	}
+	for _, ls := range i.FreqLabels {
+		sort.Ints(ls)
+	}
+}
+
+// Base strips the extra labels off a pattern vertex color.
+func (i *Indices) Base(color int) int {
+	if i.Labels == nil {
+		return color
+	}
+	return i.Labels.Base(color)
+}
+
+// HasLabels checks the vertex of G has every extra label of the color.
+func (i *Indices) HasLabels(id, color int) bool {
+	if i.Labels == nil {
+		return true
+	}
+	extra := i.Labels.Extra(color)
+	if len(extra) == 0 {
+		return true
+	}
+	has := i.VertexLabels[id]
+	for _, l := range extra {
+		j := sort.SearchInts(has, l)
+		if j >= len(has) || has[j] != l {
+			return false
+		}
+	}
+	return true
+}
+
 func (i *Indices) VertexColorFrequency(color int) int {
 	return i.VertexColors[color]
 }
@@ -123,19 +197,25 @@ func (indices *Indices) HasEdge(srcId, targId, color int) bool {
 }
 
 func (indices *Indices) TargsFromSrc(srcId, edgeColor, targColor int, exclude func(int) bool, do func(int)) {
-	for _, targId := range indices.SrcIndex[IdColorColor{srcId, edgeColor, targColor}] {
+	for _, targId := range indices.SrcIndex[IdColorColor{srcId, edgeColor, indices.Base(targColor)}] {
 		if exclude != nil && exclude(targId) {
 			continue
 		}
+		if !indices.HasLabels(targId, targColor) {
+			continue
+		}
 		do(targId)
 	}
 }
 
 func (indices *Indices) SrcsToTarg(targId, edgeColor, srcColor int, exclude func(int) bool, do func(int)) {
-	for _, srcId := range indices.TargIndex[IdColorColor{targId, edgeColor, srcColor}] {
+	for _, srcId := range indices.TargIndex[IdColorColor{targId, edgeColor, indices.Base(srcColor)}] {
 		if exclude != nil && exclude(srcId) {
 			continue
 		}
+		if !indices.HasLabels(srcId, srcColor) {
+			continue
+		}
 		do(srcId)
 	}
 }
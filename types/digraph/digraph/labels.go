@@ -2,21 +2,42 @@ package digraph
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 )
 
 type Labels struct {
-	colors map[string]int
-	labels []string
+	colors    map[string]int
+	labels    []string
+	sets      map[int]*labelSet
+	setColors map[string]int // label set name -> color (apart from colors)
+	lock      sync.RWMutex
+}
+
+// A labelSet is a vertex color standing for a set of labels: the base color
+// of the vertex plus the (sorted) extra labels.
+type labelSet struct {
+	base  int
+	extra []int
 }
 
 func NewLabels() *Labels {
 	return &Labels{
-		colors: make(map[string]int, 1000),
-		labels: make([]string, 0, 1000),
+		colors:    make(map[string]int, 1000),
+		labels:    make([]string, 0, 1000),
+		sets:      make(map[int]*labelSet),
+		setColors: make(map[string]int),
 	}
 }
 
 func (c *Labels) Color(label string) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.color(label)
+}
+
+func (c *Labels) color(label string) int {
 	if color, has := c.colors[label]; has {
 		return color
 	} else {
@@ -28,6 +49,8 @@ func (c *Labels) Color(label string) int {
 }
 
 func (c *Labels) Label(color int) string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
 	if color < 0 || color >= len(c.labels) {
 		return fmt.Sprintf("color-[%d]", color)
 	}
@@ -35,5 +58,88 @@ func (c *Labels) Label(color int) string {
 }
 
 func (c *Labels) Labels() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
 	return c.labels
 }
+
+// SetColor gives the color of the vertex label set made of the base color
+// and the extra labels. Without extra labels it is the base color. Label sets
+// are labeled as "base{extra,...}" but they are kept apart from the input
+// labels so an input label of that form is not a label set.
+func (c *Labels) SetColor(base int, extra []int) int {
+	if len(extra) == 0 {
+		return base
+	}
+	sorted := make([]int, 0, len(extra))
+	for _, l := range extra {
+		i := sort.SearchInts(sorted, l)
+		if i < len(sorted) && sorted[i] == l {
+			continue
+		}
+		sorted = append(sorted, 0)
+		copy(sorted[i+1:], sorted[i:])
+		sorted[i] = l
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	names := make([]string, 0, len(sorted))
+	for _, l := range sorted {
+		names = append(names, c.labels[l])
+	}
+	name := fmt.Sprintf("%v{%v}", c.labels[base], strings.Join(names, ","))
+	if color, has := c.setColors[name]; has {
+		return color
+	}
+	color := len(c.labels)
+	c.labels = append(c.labels, name)
+	c.setColors[name] = color
+	c.sets[color] = &labelSet{base: base, extra: sorted}
+	return color
+}
+
+// AddSet records that the color stands for the label set (see SetColor). It
+// restores the label sets of patterns read back from a cache. Their names
+// were registered as plain labels so they are moved over to the label sets.
+func (c *Labels) AddSet(color, base int, extra []int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, has := c.sets[color]; !has {
+		c.sets[color] = &labelSet{base: base, extra: extra}
+	}
+	if color < len(c.labels) {
+		name := c.labels[color]
+		if c.colors[name] == color {
+			delete(c.colors, name)
+		}
+		c.setColors[name] = color
+	}
+}
+
+// IsSet is true if the color stands for a label set with extra labels.
+func (c *Labels) IsSet(color int) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	_, has := c.sets[color]
+	return has
+}
+
+// Base is the color of the vertex without its extra labels.
+func (c *Labels) Base(color int) int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if s, has := c.sets[color]; has {
+		return s.base
+	}
+	return color
+}
+
+// Extra is the sorted extra labels of the color (nil for a plain color).
+func (c *Labels) Extra(color int) []int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if s, has := c.sets[color]; has {
+		return s.extra
+	}
+	return nil
+}
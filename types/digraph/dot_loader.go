@@ -36,8 +36,16 @@ func (v *DotLoader) Load(input lattice.Input) (dt lattice.DataType, err error) {
 	return v.LoadWithLabels(input, digraph.NewLabels())
 }
 
+func (v *DotLoader) LoadDir(inputs []lattice.NamedInput) (dt lattice.DataType, err error) {
+	return v.loadWithLabels(inputs, digraph.NewLabels())
+}
+
 func (v *DotLoader) LoadWithLabels(input lattice.Input, labels *digraph.Labels) (lattice.DataType, error) {
-	G, err := v.loadDigraph(input, labels)
+	return v.loadWithLabels([]lattice.NamedInput{{Input: input}}, labels)
+}
+
+func (v *DotLoader) loadWithLabels(inputs []lattice.NamedInput, labels *digraph.Labels) (lattice.DataType, error) {
+	G, err := v.loadDigraph(inputs, labels)
 	if err != nil {
 		return nil, err
 	}
@@ -48,28 +56,33 @@ func (v *DotLoader) LoadWithLabels(input lattice.Input, labels *digraph.Labels)
 	return v.dt, nil
 }
 
-func (v *DotLoader) loadDigraph(input lattice.Input, labels *digraph.Labels) (graph *digraph.Builder, err error) {
-	r, closer := input()
-	text, err := ioutil.ReadAll(r)
-	closer()
-	if err != nil {
-		return nil, err
-	}
+func (v *DotLoader) loadDigraph(inputs []lattice.NamedInput, labels *digraph.Labels) (graph *digraph.Builder, err error) {
 	G := digraph.Build(100, 1000)
 	dp := &dotParse{
-		b: newBaseLoader(v.dt, G),
+		b: newBaseLoader(v.dt, G, labels),
 		d: v,
 		labels: labels,
-		vids: make(map[string]int32),
-	}
-	// s, err := dot.Lexer.Scanner(text)
-	// if err != nil {
-	// 	return nil, err
-	// }
-	// for _, _, eof := s.Next(); !eof; _, _, eof = s.Next() {}
-	err = dot.StreamParse(text, dp)
-	if err != nil {
-		return nil, err
+	}
+	for ns, input := range inputs {
+		r, closer := input.Input()
+		text, err := ioutil.ReadAll(r)
+		closer()
+		if err != nil {
+			return nil, err
+		}
+		// node names are only meaningful inside of the file they are
+		// declared in.
+		dp.b.startFile(ns, input)
+		dp.vids = make(map[string]int32)
+		// s, err := dot.Lexer.Scanner(text)
+		// if err != nil {
+		// 	return nil, err
+		// }
+		// for _, _, eof := s.Next(); !eof; _, _, eof = s.Next() {}
+		err = dot.StreamParse(text, dp)
+		if err != nil {
+			return nil, err
+		}
 	}
 	return G, nil
 }
@@ -164,12 +177,14 @@ func (p *dotParse) loadEdge(n *combos.Node) (err error) {
 		return err
 	}
 	label := ""
+	attrs := make(map[string]interface{})
 	for _, attr := range n.Get(2).Children {
 		name := attr.Get(0).Value.(string)
-		if name == "label" {
-			label = attr.Get(1).Value.(string)
-			break
+		value := attr.Get(1).Value.(string)
+		attrs[name] = value
+		if name == "label" && label == "" {
+			label = value
 		}
 	}
-	return p.b.addEdge(sid, tid, p.labels.Color(label), label)
+	return p.b.addEdge(sid, tid, p.labels.Color(label), label, attrs)
 }
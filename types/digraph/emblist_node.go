@@ -16,6 +16,7 @@ import (
 
 type EmbListNode struct {
 	SubgraphPattern
+	support    int
 	extensions []*subgraph.Extension
 	embeddings []*subgraph.Embedding
 	overlap    []map[int]bool
@@ -23,18 +24,18 @@ type EmbListNode struct {
 	unsupExts  *set.SortedSet
 }
 
-func NewEmbListNode(dt *Digraph, pattern *subgraph.SubGraph, exts []*subgraph.Extension, embs []*subgraph.Embedding, overlap []map[int]bool, unsupEmbs subgraph.VertexEmbeddings) *EmbListNode {
+func NewEmbListNode(dt *Digraph, pattern *subgraph.SubGraph, support int, exts []*subgraph.Extension, embs []*subgraph.Embedding, overlap []map[int]bool, unsupEmbs subgraph.VertexEmbeddings) *EmbListNode {
 	if embs != nil {
 		if exts == nil {
 			panic("nil exts")
 		}
-		return &EmbListNode{SubgraphPattern{dt, pattern}, exts, embs, overlap, unsupEmbs, nil}
+		return &EmbListNode{SubgraphPattern{dt, pattern}, support, exts, embs, overlap, unsupEmbs, nil}
 	}
-	return &EmbListNode{SubgraphPattern{dt, pattern}, nil, nil, nil, nil, nil}
+	return &EmbListNode{SubgraphPattern{dt, pattern}, 0, nil, nil, nil, nil, nil}
 }
 
-func (n *EmbListNode) New(pattern *subgraph.SubGraph, exts []*subgraph.Extension, embs []*subgraph.Embedding, overlap []map[int]bool, unsupEmbs subgraph.VertexEmbeddings) Node {
-	return NewEmbListNode(n.Dt, pattern, exts, embs, overlap, unsupEmbs)
+func (n *EmbListNode) New(pattern *subgraph.SubGraph, support int, exts []*subgraph.Extension, embs []*subgraph.Embedding, overlap []map[int]bool, unsupEmbs subgraph.VertexEmbeddings) Node {
+	return NewEmbListNode(n.Dt, pattern, support, exts, embs, overlap, unsupEmbs)
 }
 
 func LoadEmbListNode(dt *Digraph, label []byte) (*EmbListNode, error) {
@@ -42,7 +43,7 @@ func LoadEmbListNode(dt *Digraph, label []byte) (*EmbListNode, error) {
 	if err != nil {
 		return nil, err
 	}
-	has, _, exts, embs, overlap, unsupEmbs, err := loadCachedExtsEmbs(dt, sg)
+	has, support, exts, embs, overlap, unsupEmbs, err := loadCachedExtsEmbs(dt, sg)
 	if err != nil {
 		return nil, err
 	}
@@ -52,6 +53,7 @@ func LoadEmbListNode(dt *Digraph, label []byte) (*EmbListNode, error) {
 
 	n := &EmbListNode{
 		SubgraphPattern: SubgraphPattern{Dt: dt, Pat: sg},
+		support:         support,
 		extensions:      exts,
 		embeddings:      embs,
 		overlap:         overlap,
@@ -72,6 +74,45 @@ func (n *EmbListNode) Embeddings() ([]*subgraph.Embedding, error) {
 	return n.embeddings, nil
 }
 
+// Support is the support of the pattern. It may be larger than the number
+// of embeddings if the embedding list was truncated.
+func (n *EmbListNode) Support() int {
+	return n.support
+}
+
+// Truncated is true if the node does not hold every embedding counted
+// towards its support (see Config.MaxEmbeddings).
+func (n *EmbListNode) Truncated() bool {
+	_, has := n.Dt.truncatedCount(n.Pat)
+	return has
+}
+
+// EmbeddingCount is the number of embeddings found before the embedding list
+// was truncated.
+func (n *EmbListNode) EmbeddingCount() int {
+	if count, has := n.Dt.truncatedCount(n.Pat); has {
+		return count
+	}
+	return len(n.embeddings)
+}
+
+// Reloadable is true if the node was cached and so can be loaded again from
+// its label (see Reload).
+func (n *EmbListNode) Reloadable() bool {
+	if n.Dt.Mode&Caching == 0 {
+		return false
+	}
+	n.Dt.lock.RLock()
+	defer n.Dt.lock.RUnlock()
+	has, err := n.Dt.Frequency.Has(n.Label())
+	return err == nil && has && n.support >= n.Dt.Support()
+}
+
+// Reload loads the node with the label from the cache.
+func (n *EmbListNode) Reload(label []byte) (lattice.Node, error) {
+	return LoadEmbListNode(n.Dt, label)
+}
+
 func (n *EmbListNode) Overlap() ([]map[int]bool, error) {
 	return n.overlap, nil
 }
@@ -5,30 +5,29 @@ import "github.com/stretchr/testify/assert"
 
 import (
 	"github.com/timtadh/data-structures/errors"
-	"github.com/timtadh/goiso"
 )
 
 import (
 	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/types/digraph/digraph"
 	"github.com/timtadh/regrax/types/digraph/subgraph"
 )
 
-func graph(t *testing.T) (*Digraph, *goiso.Graph, *goiso.SubGraph, *subgraph.SubGraph, *EmbListNode) {
-	Graph := goiso.NewGraph(10, 10)
-	G := &Graph
-	n1 := G.AddVertex(1, "black")
-	n2 := G.AddVertex(2, "black")
-	n3 := G.AddVertex(3, "red")
-	n4 := G.AddVertex(4, "red")
-	n5 := G.AddVertex(5, "red")
-	n6 := G.AddVertex(6, "red")
-	G.AddEdge(n1, n3, "")
-	G.AddEdge(n1, n4, "")
-	G.AddEdge(n2, n5, "")
-	G.AddEdge(n2, n6, "")
-	G.AddEdge(n5, n3, "")
-	G.AddEdge(n4, n6, "")
-	sg, _ := G.SubGraph([]int{n1.Idx, n2.Idx, n3.Idx, n4.Idx, n5.Idx, n6.Idx}, nil)
+func graph(t *testing.T) (*Digraph, *EmbListNode) {
+	labels := digraph.NewLabels()
+	b := digraph.Build(6, 6)
+	n1 := b.AddVertex(labels.Color("black"))
+	n2 := b.AddVertex(labels.Color("black"))
+	n3 := b.AddVertex(labels.Color("red"))
+	n4 := b.AddVertex(labels.Color("red"))
+	n5 := b.AddVertex(labels.Color("red"))
+	n6 := b.AddVertex(labels.Color("red"))
+	b.AddEdge(n1, n3, labels.Color(""))
+	b.AddEdge(n1, n4, labels.Color(""))
+	b.AddEdge(n2, n5, labels.Color(""))
+	b.AddEdge(n2, n6, labels.Color(""))
+	b.AddEdge(n5, n3, labels.Color(""))
+	b.AddEdge(n4, n6, labels.Color(""))
 
 	// make config
 	conf := &config.Config{
@@ -38,27 +37,28 @@ func graph(t *testing.T) (*Digraph, *goiso.Graph, *goiso.SubGraph, *subgraph.Sub
 	// make the *Digraph
 	dt, err := NewDigraph(conf, &Config{
 		MinEdges: 0,
-		MaxEdges: len(G.E),
+		MaxEdges: len(b.E),
 		MinVertices: 0,
-		MaxVertices: len(G.V),
-		Mode: Automorphs | ExtFromEmb,
+		MaxVertices: len(b.V),
+		Mode: MNI | ExtFromEmb,
 		EmbSearchStartPoint: subgraph.RandomStart,
 	})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	err = dt.Init(G)
+	err = dt.Init(b, labels)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	return dt, G, sg, subgraph.FromEmbedding(sg), RootEmbListNode(dt)
+	return dt, RootEmbListNode(dt)
 }
 
 func TestEmbChildren(t *testing.T) {
 	x := assert.New(t)
-	_, _, _, _, n := graph(t)
+	dt, n := graph(t)
+	defer dt.Close()
 	x.NotNil(n)
 	kids, err := n.Children()
 	if err != nil {
@@ -153,7 +153,8 @@ func TestEmbChildren(t *testing.T) {
 
 func TestEmbCount(t *testing.T) {
 	x := assert.New(t)
-	_, _, _, _, n := graph(t)
+	dt, n := graph(t)
+	defer dt.Close()
 	x.NotNil(n)
 	count, err := n.ChildCount()
 	if err != nil {
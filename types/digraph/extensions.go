@@ -1,6 +1,10 @@
 package digraph
 
-import ()
+import (
+	"math"
+	"sort"
+	"time"
+)
 
 import (
 	"github.com/timtadh/data-structures/errors"
@@ -10,6 +14,8 @@ import (
 )
 
 import (
+	"github.com/timtadh/regrax/metrics"
+	"github.com/timtadh/regrax/progress"
 	"github.com/timtadh/regrax/stats"
 	"github.com/timtadh/regrax/types/digraph/digraph"
 	"github.com/timtadh/regrax/types/digraph/subgraph"
@@ -32,41 +38,50 @@ const CACHE_DEBUG = false
 // 5. Add a parallel implementation of extending from embedding list ala original
 //    graple. This will give a benchmarking point of comparison.
 
-func extensionPoint(G *digraph.Digraph, emb *subgraph.Embedding, e *digraph.Edge, src, targ int) *subgraph.Extension {
-	hasTarg := false
-	hasSrc := false
-	var srcIdx int = len(emb.SG.V)
-	var targIdx int = len(emb.SG.V)
-	if src >= 0 {
-		hasSrc = true
-		srcIdx = src
+// extensionPoints calls do with each extension of the embedding by the edge
+// e. One end of e is the vertex at pattern index src (or targ when src is
+// -1). The other end is the pattern index its vertex is embedded at or a new
+// vertex when it is not in the embedding. Under homomorphism the vertex may
+// be embedded at several pattern indices and a new pattern vertex may be
+// mapped onto it as well so every one of these is an extension.
+func extensionPoints(dt *Digraph, emb *subgraph.Embedding, e *digraph.Edge, src, targ int, do func(*subgraph.Extension)) {
+	homomorphic := dt.Mode&Homomorphism == Homomorphism
+	newIdx := len(emb.SG.V)
+	vertex := func(idx, id int) subgraph.Vertex {
+		// vertices already in the pattern keep their (label set) color
+		if idx < newIdx {
+			return subgraph.Vertex{Idx: idx, Color: emb.SG.V[idx].Color}
+		}
+		return subgraph.Vertex{Idx: idx, Color: dt.G.V[id].Color}
 	}
-	if targ >= 0 {
-		hasTarg = true
-		targIdx = targ
+	other := e.Targ
+	if src < 0 {
+		other = e.Src
 	}
+	ends := make([]int, 0, 2)
 	for idx, id := range emb.Ids {
-		if hasTarg && hasSrc {
-			break
-		}
-		if !hasSrc && e.Src == id {
-			hasSrc = true
-			srcIdx = idx
+		if id == other {
+			ends = append(ends, idx)
+			if !homomorphic {
+				break
+			}
 		}
-		if !hasTarg && e.Targ == id {
-			hasTarg = true
-			targIdx = idx
+	}
+	if len(ends) == 0 || homomorphic {
+		ends = append(ends, newIdx)
+	}
+	for _, end := range ends {
+		if src >= 0 {
+			do(subgraph.NewExt(vertex(src, e.Src), vertex(end, e.Targ), e.Color))
+		} else {
+			do(subgraph.NewExt(vertex(end, e.Src), vertex(targ, e.Targ), e.Color))
 		}
 	}
-	return subgraph.NewExt(
-		subgraph.Vertex{Idx: srcIdx, Color: G.V[e.Src].Color},
-		subgraph.Vertex{Idx: targIdx, Color: G.V[e.Targ].Color},
-		e.Color)
 }
 
-func validExtChecker(dt *Digraph, do func(*subgraph.Embedding, *subgraph.Extension)) func(*subgraph.Embedding, *digraph.Edge, int, int) int {
+func validExtChecker(dt *Digraph, do func(*subgraph.Embedding, *subgraph.Extension, *digraph.Edge)) func(*subgraph.Embedding, *digraph.Edge, int, int) int {
 	return func(emb *subgraph.Embedding, e *digraph.Edge, src, targ int) int {
-		if dt.Indices.EdgeCounts[dt.Indices.Colors(e)] < dt.Support() {
+		if dt.Mode&Weighted == 0 && dt.Indices.EdgeCounts[dt.Indices.Colors(e)] < dt.Support() {
 			return 0
 		}
 		// if dt.G.ColorFrequency(e.Color) < dt.Support() {
@@ -76,17 +91,78 @@ func validExtChecker(dt *Digraph, do func(*subgraph.Embedding, *subgraph.Extensi
 		// } else if dt.G.ColorFrequency(dt.G.V[e.Targ].Color) < dt.Support() {
 		// 	return 0
 		// }
-		ep := extensionPoint(dt.G, emb, e, src, targ)
-		if !emb.SG.HasExtension(ep) {
-			do(emb, ep)
-			return 1
+		count := 0
+		extensionPoints(dt, emb, e, src, targ, func(ep *subgraph.Extension) {
+			if !emb.SG.HasExtension(ep) {
+				do(emb, ep, e)
+				count++
+			}
+		})
+		return count
+	}
+}
+
+// inducedExt is the extension adding the vertex other (adjacent to the
+// embedding) along with every edge between it and the embedding (so the
+// extended embedding stays induced). It is nil if one of the edges is
+// infrequent since the extension cannot be supported (infrequent edges may
+// weigh enough under weighted support so they are kept).
+func inducedExt(dt *Digraph, emb *subgraph.Embedding, idxs map[int]int, other int) *subgraph.Extension {
+	newIdx := len(emb.SG.V)
+	vertex := func(id int) subgraph.Vertex {
+		if idx, has := idxs[id]; has {
+			return subgraph.Vertex{Idx: idx, Color: emb.SG.V[idx].Color}
 		}
-		return 0
+		return subgraph.Vertex{Idx: newIdx, Color: dt.G.V[id].Color}
 	}
+	edges := make(extensionList, 0, 2)
+	loops := make(extensionList, 0, 1)
+	add := func(e *digraph.Edge) bool {
+		if dt.Mode&Weighted == 0 && dt.Indices.EdgeCounts[dt.Indices.Colors(e)] < dt.Support() {
+			return false
+		}
+		ext := subgraph.NewExt(vertex(e.Src), vertex(e.Targ), e.Color)
+		if e.Src == e.Targ {
+			loops = append(loops, *ext)
+		} else {
+			edges = append(edges, *ext)
+		}
+		return true
+	}
+	for _, e := range dt.G.Kids[other] {
+		if _, has := idxs[dt.G.E[e].Targ]; has || dt.G.E[e].Targ == other {
+			if !add(&dt.G.E[e]) {
+				return nil
+			}
+		}
+	}
+	for _, e := range dt.G.Parents[other] {
+		if _, has := idxs[dt.G.E[e].Src]; has {
+			if !add(&dt.G.E[e]) {
+				return nil
+			}
+		}
+	}
+	// the loops can only be added once the new vertex exists
+	sort.Sort(edges)
+	sort.Sort(loops)
+	ext := edges[0]
+	ext.More = append(edges[1:], loops...)
+	if len(ext.More) == 0 {
+		ext.More = nil
+	}
+	return &ext
 }
 
+type extensionList []subgraph.Extension
+
+func (l extensionList) Len() int           { return len(l) }
+func (l extensionList) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+func (l extensionList) Less(i, j int) bool { return l[i].Less(&l[j]) }
+
 func extensionsFromEmbeddings(dt *Digraph, pattern *subgraph.SubGraph, ei subgraph.EmbIterator, seen map[int]bool) (total int, overlap []map[int]bool, fisEmbs []*subgraph.Embedding, sets []*hashtable.LinearHash, exts types.Set) {
-	if dt.Mode&FIS == FIS {
+	defer metrics.ExtsFromEmbs.Time()()
+	if dt.Mode&(FIS|MIS|Weighted) != 0 {
 		seen = make(map[int]bool)
 		fisEmbs = make([]*subgraph.Embedding, 0, 10)
 	} else {
@@ -96,10 +172,27 @@ func extensionsFromEmbeddings(dt *Digraph, pattern *subgraph.SubGraph, ei subgra
 		overlap = make([]map[int]bool, len(pattern.V))
 	}
 	exts = set.NewSetMap(hashtable.NewLinearHash())
-	add := validExtChecker(dt, func(emb *subgraph.Embedding, ext *subgraph.Extension) {
+	add := validExtChecker(dt, func(emb *subgraph.Embedding, ext *subgraph.Extension, e *digraph.Edge) {
+		if dt.Mode&Temporal == Temporal {
+			// only the positions in the temporal order this embedding
+			// can take the edge at
+			emb.TimedExtensions(dt.Indices, ext, e.Src, e.Targ, func(x *subgraph.Extension) {
+				exts.Add(x)
+			})
+			return
+		}
 		exts.Add(ext)
 	})
 	for emb, next := ei(false); next != nil; emb, next = next(false) {
+		var idxs map[int]int
+		var adjacent map[int]bool
+		if dt.Mode&Induced == Induced {
+			idxs = make(map[int]int, len(emb.Ids))
+			for idx, id := range emb.Ids {
+				idxs[id] = idx
+			}
+			adjacent = make(map[int]bool)
+		}
 		seenIt := false
 		for idx, id := range emb.Ids {
 			if fisEmbs != nil {
@@ -120,10 +213,27 @@ func extensionsFromEmbeddings(dt *Digraph, pattern *subgraph.SubGraph, ei subgra
 				if sets[idx] == nil {
 					sets[idx] = hashtable.NewLinearHash()
 				}
-				set := sets[idx]
-				if !set.Has(types.Int(id)) {
-					set.Put(types.Int(id), emb)
+				putCapped(dt, sets[idx], id, emb)
+			}
+			if dt.Mode&LabelSets == LabelSets {
+				relabelExts(dt, pattern, idx, dt.Indices.VertexLabels[id], func(ext *subgraph.Extension) {
+					exts.Add(ext)
+				})
+			}
+			if adjacent != nil {
+				// the embedding is induced so every edge between its
+				// vertices is already in the pattern
+				for _, e := range dt.G.Kids[id] {
+					if _, has := idxs[dt.G.E[e].Targ]; !has {
+						adjacent[dt.G.E[e].Targ] = true
+					}
+				}
+				for _, e := range dt.G.Parents[id] {
+					if _, has := idxs[dt.G.E[e].Src]; !has {
+						adjacent[dt.G.E[e].Src] = true
+					}
 				}
+				continue
 			}
 			for _, e := range dt.G.Kids[id] {
 				add(emb, &dt.G.E[e], idx, -1)
@@ -132,7 +242,16 @@ func extensionsFromEmbeddings(dt *Digraph, pattern *subgraph.SubGraph, ei subgra
 				add(emb, &dt.G.E[e], -1, idx)
 			}
 		}
-		if fisEmbs != nil && !seenIt {
+		for other := range adjacent {
+			if ext := inducedExt(dt, emb, idxs, other); ext != nil {
+				exts.Add(ext)
+			}
+		}
+		if dt.Mode&(MIS|Weighted) != 0 {
+			// all embeddings are kept. the independent set (or the
+			// weighted support) is computed once the search is complete.
+			fisEmbs = append(fisEmbs, emb)
+		} else if fisEmbs != nil && !seenIt {
 			fisEmbs = append(fisEmbs, emb)
 		}
 		total++
@@ -140,8 +259,57 @@ func extensionsFromEmbeddings(dt *Digraph, pattern *subgraph.SubGraph, ei subgra
 	return total, overlap, fisEmbs, sets, exts
 }
 
+// putCapped adds the vertex id (and the embedding it was found in) to the
+// set. Once the set holds MaxEmbeddings embeddings the ids are still counted
+// for the support but their embeddings are not kept.
+func putCapped(dt *Digraph, set *hashtable.LinearHash, id int, emb *subgraph.Embedding) {
+	if set.Has(types.Int(id)) {
+		return
+	}
+	if dt.MaxEmbeddings > 0 && set.Size() >= dt.MaxEmbeddings {
+		set.Put(types.Int(id), nil)
+		return
+	}
+	set.Put(types.Int(id), emb)
+}
+
+// freqEdgeExts calls do for every extension of the pattern by a frequent
+// edge (whether or not the extension is supported by an embedding).
+func freqEdgeExts(dt *Digraph, pattern *subgraph.SubGraph, do func(*subgraph.Extension)) {
+	for i := range pattern.V {
+		u := &pattern.V[i]
+		if dt.Mode&LabelSets == LabelSets {
+			relabelExts(dt, pattern, i, dt.Indices.FreqLabels[dt.Indices.Base(u.Color)], do)
+		}
+		for _, e := range dt.Indices.EdgesFromColor[dt.Indices.Base(u.Color)] {
+			for j := range pattern.V {
+				v := &pattern.V[j]
+				if dt.Indices.Base(v.Color) == e.TargColor {
+					ep := subgraph.NewExt(
+						subgraph.Vertex{Idx: i, Color: u.Color},
+						subgraph.Vertex{Idx: j, Color: v.Color},
+						e.EdgeColor)
+					do(ep)
+				}
+			}
+			ep := subgraph.NewExt(
+				subgraph.Vertex{Idx: i, Color: u.Color},
+				subgraph.Vertex{Idx: len(pattern.V), Color: e.TargColor},
+				e.EdgeColor)
+			do(ep)
+		}
+		for _, e := range dt.Indices.EdgesToColor[dt.Indices.Base(u.Color)] {
+			ep := subgraph.NewExt(
+				subgraph.Vertex{Idx: len(pattern.V), Color: e.SrcColor},
+				subgraph.Vertex{Idx: i, Color: u.Color},
+				e.EdgeColor)
+			do(ep)
+		}
+	}
+}
+
 func extensionsFromFreqEdges(dt *Digraph, pattern *subgraph.SubGraph, ei subgraph.EmbIterator, seen map[int]bool) (total int, overlap []map[int]bool, fisEmbs []*subgraph.Embedding, sets []*hashtable.LinearHash, exts types.Set) {
-	if dt.Mode&FIS == FIS {
+	if dt.Mode&(FIS|MIS|Weighted) != 0 {
 		seen = make(map[int]bool)
 		fisEmbs = make([]*subgraph.Embedding, 0, 10)
 	} else {
@@ -150,6 +318,10 @@ func extensionsFromFreqEdges(dt *Digraph, pattern *subgraph.SubGraph, ei subgrap
 	if dt.Mode&OverlapPruning == OverlapPruning {
 		overlap = make([]map[int]bool, len(pattern.V))
 	}
+	var txs map[int]bool
+	if dt.Mode&Transactions == Transactions {
+		txs = make(map[int]bool)
+	}
 	support := dt.Support()
 	done := make(chan types.Set)
 	go func(done chan types.Set) {
@@ -164,35 +336,12 @@ func extensionsFromFreqEdges(dt *Digraph, pattern *subgraph.SubGraph, ei subgrap
 			done<-hash
 			close(done)
 		}()
-		for i := range pattern.V {
-			u := &pattern.V[i]
-			for _, e := range dt.Indices.EdgesFromColor[u.Color] {
-				for j := range pattern.V {
-					v := &pattern.V[j]
-					if v.Color == e.TargColor {
-						ep := subgraph.NewExt(
-							subgraph.Vertex{Idx: i, Color: e.SrcColor},
-							subgraph.Vertex{Idx: j, Color: e.TargColor},
-							e.EdgeColor)
-						exts <-ep
-					}
-				}
-				ep := subgraph.NewExt(
-					subgraph.Vertex{Idx: i, Color: u.Color},
-					subgraph.Vertex{Idx: len(pattern.V), Color: e.TargColor},
-					e.EdgeColor)
-				exts <-ep
-			}
-			for _, e := range dt.Indices.EdgesToColor[u.Color] {
-				ep := subgraph.NewExt(
-					subgraph.Vertex{Idx: len(pattern.V), Color: e.SrcColor},
-					subgraph.Vertex{Idx: i, Color: u.Color},
-					e.EdgeColor)
-				exts <-ep
-			}
-		}
+		freqEdgeExts(dt, pattern, func(ep *subgraph.Extension) {
+			exts <-ep
+		})
 		close(exts)
 	}(done)
+	greedy := 0
 	stop := false
 	for emb, next := ei(stop); next != nil; emb, next = next(stop) {
 		min := -1
@@ -216,53 +365,110 @@ func extensionsFromFreqEdges(dt *Digraph, pattern *subgraph.SubGraph, ei subgrap
 				if sets[idx] == nil {
 					sets[idx] = hashtable.NewLinearHash()
 				}
-				set := sets[idx]
-				if !set.Has(types.Int(id)) {
-					set.Put(types.Int(id), emb)
-				}
-				size := set.Size()
+				putCapped(dt, sets[idx], id, emb)
+				size := sets[idx].Size()
 				if min == -1 || size < min {
 					min = size
 				}
 			}
 		}
-		if fisEmbs != nil && !seenIt {
+		if dt.Mode&Weighted != 0 {
+			// the weights are only known once every embedding is found
+			fisEmbs = append(fisEmbs, emb)
+		} else if dt.Mode&MIS == MIS {
+			// the greedy independent set is a lower bound on the MIS
+			fisEmbs = append(fisEmbs, emb)
+			if !seenIt {
+				greedy++
+				min = greedy
+			}
+		} else if fisEmbs != nil && !seenIt {
 			fisEmbs = append(fisEmbs, emb)
 			min = len(fisEmbs)
 		}
+		if txs != nil {
+			txs[dt.VertexTx[emb.Ids[0]]] = true
+			min = len(txs)
+		}
 		total++
 		if min >= support {
 			stop = true
 		}
 	}
-	if total < support {
+	if total < support && dt.Mode&Weighted == 0 {
 		return total, overlap, fisEmbs, sets, nil
 	}
 	return total, overlap, fisEmbs, sets, <-done
 }
 
+// temporalExts places each extension at every position of the temporal
+// order of the pattern (before, between and after its edges). It is used
+// for the extensions which do not come from embeddings (so which positions
+// are feasible is not known). The infeasible ones are found unsupported by
+// the embedding search.
+func temporalExts(pattern *subgraph.SubGraph, exts []*subgraph.Extension) []*subgraph.Extension {
+	timed := make([]*subgraph.Extension, 0, len(exts)*(len(pattern.E)+1))
+	for _, ext := range exts {
+		if ext.Relabel {
+			timed = append(timed, ext)
+			continue
+		}
+		for t := 1; t <= len(pattern.E)+1; t++ {
+			x := *ext
+			x.Time = t
+			timed = append(timed, &x)
+		}
+	}
+	return timed
+}
+
 // unique extensions and supported embeddings
 func ExtsAndEmbs(dt *Digraph, pattern *subgraph.SubGraph, patternOverlap []map[int]bool, unsupExts types.Set, unsupEmbs map[subgraph.VrtEmb]bool, mode Mode, debug bool) (int, []*subgraph.Extension, []*subgraph.Embedding, []map[int]bool, subgraph.VertexEmbeddings, error) {
 	if !debug {
+		start := time.Now()
 		if has, support, exts, embs, overlap, unsupEmbs, err := loadCachedExtsEmbs(dt, pattern); err != nil {
 			return 0, nil, nil, nil, nil, err
 		} else if has {
+			progress.CacheHits.Inc()
+			metrics.ExtsAndEmbsCached.Since(start)
 			if false {
 				errors.Logf("LOAD-DEBUG", "Loaded cached %v exts %v embs %v", pattern, len(exts), len(embs))
 			}
 			return support, exts, embs, overlap, unsupEmbs, nil
+		} else if dt.Mode&Caching != 0 {
+			progress.CacheMisses.Inc()
 		}
 	}
+	defer metrics.ExtsAndEmbsComputed.Time()()
 	if CACHE_DEBUG || debug {
 		errors.Logf("CACHE-DEBUG", "ExtsAndEmbs %v", pattern.Pretty(dt.Labels))
 	}
 
+	if mode&Approximate == Approximate && mode&MNI == MNI && len(pattern.E) > 0 {
+		// try to decide the pattern from a sample of its embeddings. if
+		// the confidence interval straddles the support threshold fall
+		// through to the exact computation.
+		if decided, support, exts, embs := approxExtsAndEmbs(dt, pattern, unsupExts); decided {
+			if support < dt.Support() {
+				return 0, nil, nil, nil, nil, nil
+			}
+			if dt.MaxEmbeddings > 0 && len(embs) > dt.MaxEmbeddings {
+				dt.lock.Lock()
+				dt.truncated[string(pattern.Label())] = len(embs)
+				dt.lock.Unlock()
+				embs = embs[:dt.MaxEmbeddings]
+			}
+			// the sample is not cached (see approxExtsAndEmbs)
+			return support, exts, embs, nil, nil, nil
+		}
+	}
+
 	// compute the embeddings
 	var seen map[int]bool = nil
 	var ei subgraph.EmbIterator
 	var dropped *subgraph.VertexEmbeddings
 	switch {
-	case mode&(MNI|FIS) != 0:
+	case mode&(MNI|FIS|MIS|Weighted) != 0:
 		ei, dropped = pattern.IterEmbeddings(
 			dt.EmbSearchStartPoint, dt.Indices, unsupEmbs, patternOverlap, nil)
 	case mode&(GIS) == GIS:
@@ -275,6 +481,12 @@ func ExtsAndEmbs(dt *Digraph, pattern *subgraph.SubGraph, patternOverlap []map[i
 			func(ids *subgraph.IdNode) bool {
 				for c := ids; c != nil; c = c.Prev {
 					if _, has := seen[c.Id]; has {
+						if mode&Induced == Induced {
+							// the partial embedding may never be
+							// induced. only the embeddings which pass
+							// the filter mark their vertices.
+							return true
+						}
 						for c := ids; c != nil; c = c.Prev {
 							seen[c.Id] = true
 						}
@@ -286,6 +498,9 @@ func ExtsAndEmbs(dt *Digraph, pattern *subgraph.SubGraph, patternOverlap []map[i
 	default:
 		return 0, nil, nil, nil, nil, errors.Errorf("Unknown support counting strategy %v", mode)
 	}
+	if mode&Induced == Induced {
+		ei = subgraph.FilterInduced(ei, dt.G)
+	}
 
 	// find the actual embeddings and compute the extensions
 	// the extensions are stored in exts
@@ -295,10 +510,14 @@ func ExtsAndEmbs(dt *Digraph, pattern *subgraph.SubGraph, patternOverlap []map[i
 	var sets []*hashtable.LinearHash
 	var overlap []map[int]bool
 	var total int
-	if mode&ExtFromEmb == ExtFromEmb && len(pattern.E) > 0 {
+	fromEmbs := false
+	if mode&ExtFromEmb == ExtFromEmb && (len(pattern.E) > 0 || mode&Induced == Induced) {
+		// induced extensions add a vertex with all of its edges so even
+		// the vertices are extended from their embeddings
 		// add the supported embeddings to the vertex sets
 		// add the extensions to the extensions set
 		total, overlap, fisEmbs, sets, exts = extensionsFromEmbeddings(dt, pattern, ei, seen)
+		fromEmbs = true
 		if total == 0 {
 			// return 0, nil, nil, nil, nil, errors.Errorf("could not find any embedding of %v", pattern)
 			// because we are extending from frequent edges for vertices this
@@ -307,7 +526,7 @@ func ExtsAndEmbs(dt *Digraph, pattern *subgraph.SubGraph, patternOverlap []map[i
 		}
 	} else if mode&ExtFromFreqEdges == ExtFromFreqEdges || len(pattern.E) <= 0 {
 		total, overlap, fisEmbs, sets, exts = extensionsFromFreqEdges(dt, pattern, ei, seen)
-		if total < dt.Support() {
+		if total == 0 || (total < dt.Support() && mode&Weighted == 0) {
 			return 0, nil, nil, nil, nil, nil
 		}
 	} else {
@@ -323,6 +542,9 @@ func ExtsAndEmbs(dt *Digraph, pattern *subgraph.SubGraph, patternOverlap []map[i
 		}
 		extensions = append(extensions, ext)
 	}
+	if mode&Temporal == Temporal && !fromEmbs {
+		extensions = temporalExts(pattern, extensions)
+	}
 
 	if mode&EmbeddingPruning == EmbeddingPruning && unsupEmbs != nil {
 		// for i, next := unsupEmbs.Items()(); next != nil; i, next = next() {
@@ -334,7 +556,25 @@ func ExtsAndEmbs(dt *Digraph, pattern *subgraph.SubGraph, patternOverlap []map[i
 	}
 
 	var embeddings []*subgraph.Embedding
-	if mode&(MNI|GIS) != 0 {
+	var weight float64
+	count := 0 // the number of embeddings before the cap
+	if mode&Weighted != 0 {
+		weight, embeddings = weightedSupport(dt, pattern, fisEmbs)
+	} else if mode&Transactions == Transactions && sets != nil {
+		// every transaction with an embedding has one which is indexed
+		// by the vertex at idx 0. keep one embedding per transaction.
+		txs := make(map[int]bool)
+		embeddings = make([]*subgraph.Embedding, 0, 10)
+		for id, i, next := sets[0].Iterate()(); next != nil; id, i, next = next() {
+			if tx := dt.VertexTx[int(id.(types.Int))]; !txs[tx] {
+				txs[tx] = true
+				if emb, ok := i.(*subgraph.Embedding); ok {
+					embeddings = append(embeddings, emb)
+				}
+			}
+		}
+		count = len(txs)
+	} else if mode&(MNI|GIS) != 0 {
 		// compute the minimally supported vertex
 		arg, size := stats.Min(stats.RandomPermutation(len(sets)), func(i int) float64 {
 			return float64(sets[i].Size())
@@ -342,25 +582,52 @@ func ExtsAndEmbs(dt *Digraph, pattern *subgraph.SubGraph, patternOverlap []map[i
 		// construct the embeddings output slice
 		embeddings = make([]*subgraph.Embedding, 0, int(size)+1)
 		for i, next := sets[arg].Values()(); next != nil; i, next = next() {
-			emb := i.(*subgraph.Embedding)
-			embeddings = append(embeddings, emb)
+			// the ids past the cap have no embedding (see putCapped)
+			if emb, ok := i.(*subgraph.Embedding); ok {
+				embeddings = append(embeddings, emb)
+			}
 		}
+		count = int(size)
 	} else if mode&(FIS) == FIS {
 		embeddings = fisEmbs
+	} else if mode&(MIS) == MIS {
+		embeddings = maxIndependentEmbeddings(fisEmbs)
 	} else {
 		return 0, nil, nil, nil, nil, errors.Errorf("Unknown support counting strategy %v", mode)
 	}
 
+	if count < len(embeddings) {
+		count = len(embeddings)
+	}
+	support := count
+	if mode&Weighted != 0 {
+		support = int(math.Floor(weight))
+		dt.lock.Lock()
+		dt.weighted[string(pattern.Label())] = weight
+		dt.lock.Unlock()
+	}
+
+	// the support is established and the extensions and overlap have
+	// been computed from every embedding so the rest may be dropped
+	if dt.MaxEmbeddings > 0 && count > dt.MaxEmbeddings {
+		dt.lock.Lock()
+		dt.truncated[string(pattern.Label())] = count
+		dt.lock.Unlock()
+		if len(embeddings) > dt.MaxEmbeddings {
+			embeddings = embeddings[:dt.MaxEmbeddings]
+		}
+	}
+
 	if CACHE_DEBUG || debug {
-		errors.Logf("CACHE-DEBUG", "Caching exts %v embs %v total-embs %v : %v", len(extensions), len(embeddings), total, pattern.Pretty(dt.Labels))
+		errors.Logf("CACHE-DEBUG", "Caching exts %v embs %v support %v total-embs %v : %v", len(extensions), len(embeddings), support, total, pattern.Pretty(dt.Labels))
 	}
 	if !debug {
-		err := cacheExtsEmbs(dt, pattern, len(embeddings), extensions, embeddings, overlap, *dropped)
+		err := cacheExtsEmbs(dt, pattern, support, extensions, embeddings, overlap, *dropped)
 		if err != nil {
 			return 0, nil, nil, nil, nil, err
 		}
 	}
-	return len(embeddings), extensions, embeddings, overlap, *dropped, nil
+	return support, extensions, embeddings, overlap, *dropped, nil
 }
 
 func cacheExtsEmbs(dt *Digraph, pattern *subgraph.SubGraph, support int, exts []*subgraph.Extension, embs []*subgraph.Embedding, overlap []map[int]bool, unsupEmbs subgraph.VertexEmbeddings) error {
@@ -399,13 +666,25 @@ func cacheExtsEmbs(dt *Digraph, pattern *subgraph.SubGraph, support int, exts []
 			return err
 		}
 	}
-	if dt.Overlap != nil && len(pattern.E) > 3 {
+	if dt.Overlap != nil && overlap != nil && len(pattern.E) > 3 {
 		// save the overlap if using
 		err = dt.Overlap.Add(pattern, overlap)
 		if err != nil {
 			return err
 		}
 	}
+	err = saveLabelSets(dt, pattern)
+	if err != nil {
+		return err
+	}
+	if dt.Truncated != nil {
+		if count, has := dt.truncated[string(label)]; has {
+			err = dt.Truncated.Add(label, int32(count))
+			if err != nil {
+				return err
+			}
+		}
+	}
 	if dt.UnsupEmbs != nil {
 		// for _, emb := range unsupEmbs {
 		// 	err = dt.UnsupEmbs.Add(pattern, emb)
@@ -438,6 +717,10 @@ func loadCachedExtsEmbs(dt *Digraph, pattern *subgraph.SubGraph) (bool, int, []*
 	if err != nil {
 		return false, 0, nil, nil, nil, nil, err
 	}
+	err = loadLabelSets(dt, pattern)
+	if err != nil {
+		return false, 0, nil, nil, nil, nil, err
+	}
 
 	exts := make([]*subgraph.Extension, 0, 10)
 	err = dt.Extensions.DoFind(label, func(_ []byte, ext *subgraph.Extension) error {
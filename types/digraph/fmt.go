@@ -65,7 +65,7 @@ func (f *Formatter) Pattern(node lattice.Node) (string, error) {
 				}
 			}
 			dot := n.embeddings[0].Dotty(n.Dt.Labels, attrs)
-			return fmt.Sprintf("// %s\n\n%s\n", Pat, dot), nil
+			return fmt.Sprintf("// %s%s\n\n%s\n", Pat, supportNote(n), dot), nil
 		} else {
 			return fmt.Sprintf("// {0:0}\n\ndigraph{}\n"), nil
 		}
@@ -126,7 +126,26 @@ func (f *Formatter) FormatEmbeddings(w io.Writer, node lattice.Node) error {
 		return err
 	}
 	pat := f.PatternName(node)
+	if n, ok := node.(*EmbListNode); ok {
+		pat += supportNote(n)
+	}
 	embeddings := strings.Join(embs, "\n")
 	_, err = fmt.Fprintf(w, "// %s\n\n%s\n\n", pat, embeddings)
 	return err
 }
+
+// supportNote flags patterns whose support was estimated by sampling (their
+// embeddings are only the sampled embeddings), shows weighted supports and
+// flags patterns whose embedding list was truncated.
+func supportNote(n *EmbListNode) string {
+	note := ""
+	if est := n.Dt.Approximation(n.Pat); est != nil {
+		note += fmt.Sprintf("\n// approximate support %v", est)
+	} else if w, has := n.Dt.WeightedSupport(n.Pat); has {
+		note += fmt.Sprintf("\n// weighted support %v", w)
+	}
+	if n.Truncated() {
+		note += fmt.Sprintf("\n// embeddings truncated to %v of %v", len(n.embeddings), n.EmbeddingCount())
+	}
+	return note
+}
@@ -0,0 +1,106 @@
+package digraph
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/types/digraph/digraph"
+	"github.com/timtadh/regrax/types/digraph/subgraph"
+)
+
+// backAndForthDigraph has two copies of a -> b -> a over the same a vertex.
+func backAndForthDigraph(t testing.TB, mode Mode) *Digraph {
+	labels := digraph.NewLabels()
+	b := digraph.Build(4, 4)
+	for c := 0; c < 2; c++ {
+		u := b.AddVertex(labels.Color("a"))
+		v := b.AddVertex(labels.Color("b"))
+		b.AddEdge(u, v, labels.Color("e"))
+		b.AddEdge(v, u, labels.Color("e"))
+	}
+	dt, err := NewDigraph(&config.Config{Support: 2}, &Config{
+		MaxEdges:            2,
+		Mode:                mode | ExtFromEmb | Caching,
+		EmbSearchStartPoint: subgraph.RandomStart,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = dt.Init(b, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dt
+}
+
+// pathNodes finds the patterns a -> b -> a' (three vertices, two edges).
+func pathNodes(t testing.TB, dt *Digraph) []*EmbListNode {
+	a := dt.Labels.Color("a")
+	found := make([]*EmbListNode, 0, 1)
+	seen := make(map[string]bool)
+	queue := []Node{RootEmbListNode(dt)}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		kids, err := n.Children()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, k := range kids {
+			kid := k.(*EmbListNode)
+			label := kid.Pat.Pretty(dt.Labels)
+			if seen[label] {
+				continue
+			}
+			seen[label] = true
+			queue = append(queue, kid)
+			if len(kid.Pat.V) != 3 || len(kid.Pat.E) != 2 {
+				continue
+			}
+			in, out := kid.Pat.E[0], kid.Pat.E[1]
+			if in.Targ != out.Src {
+				in, out = out, in
+			}
+			if in.Targ == out.Src && in.Src != out.Targ &&
+				kid.Pat.V[in.Src].Color == a && kid.Pat.V[out.Targ].Color == a {
+				found = append(found, kid)
+			}
+		}
+	}
+	return found
+}
+
+func TestHomomorphicPath(t *testing.T) {
+	x := assert.New(t)
+	dt := backAndForthDigraph(t, MNI|Homomorphism)
+	defer dt.Close()
+	nodes := pathNodes(t, dt)
+	if !x.Equal(1, len(nodes)) {
+		return
+	}
+	n := nodes[0]
+	x.Equal(2, n.Support())
+	embs, err := n.Embeddings()
+	x.Nil(err)
+	x.Equal(2, len(embs))
+	for _, emb := range embs {
+		// both a vertices of the pattern are mapped onto the same vertex
+		for i := range n.Pat.V {
+			for j := range n.Pat.V {
+				if i != j && n.Pat.V[i].Color == n.Pat.V[j].Color {
+					x.Equal(emb.Ids[i], emb.Ids[j])
+				}
+			}
+		}
+		x.True(emb.ExistsHomomorphic(dt.G))
+		x.False(emb.Exists(dt.G))
+	}
+}
+
+func TestNotHomomorphicPath(t *testing.T) {
+	x := assert.New(t)
+	dt := backAndForthDigraph(t, MNI)
+	defer dt.Close()
+	x.Equal(0, len(pathNodes(t, dt)))
+}
@@ -0,0 +1,164 @@
+package digraph
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+import (
+	"strings"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/lattice"
+	"github.com/timtadh/regrax/types/digraph/digraph"
+	"github.com/timtadh/regrax/types/digraph/subgraph"
+)
+
+// cycleDigraph has two copies of the directed cycle over the labels.
+func cycleDigraph(t testing.TB, mode Mode, labelNames ...string) *Digraph {
+	labels := digraph.NewLabels()
+	b := digraph.Build(2*len(labelNames), 2*len(labelNames))
+	for c := 0; c < 2; c++ {
+		vs := make([]*digraph.Vertex, 0, len(labelNames))
+		for _, name := range labelNames {
+			vs = append(vs, b.AddVertex(labels.Color(name)))
+		}
+		for i := range vs {
+			b.AddEdge(vs[i], vs[(i+1)%len(vs)], labels.Color("e"))
+		}
+	}
+	dt, err := NewDigraph(&config.Config{Support: 2}, &Config{
+		MaxEdges:            len(labelNames),
+		Mode:                mode | ExtFromEmb | Caching,
+		EmbSearchStartPoint: subgraph.RandomStart,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = dt.Init(b, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dt
+}
+
+// sizes counts the patterns by their "{|E|:|V|}" prefix.
+func sizes(supports map[string]int) map[string]int {
+	count := make(map[string]int)
+	for label := range supports {
+		count[label[:strings.Index(label, "}")+1]]++
+	}
+	return count
+}
+
+func TestInducedTriangle(t *testing.T) {
+	x := assert.New(t)
+	dt := cycleDigraph(t, MNI|Induced, "a", "b", "c")
+	defer dt.Close()
+	supports := patternSupports(t, dt)
+	// no path of two edges is induced. the triangle is reached by adding
+	// the third vertex with both of its edges.
+	x.Equal(map[string]int{"{0:1}": 3, "{1:2}": 3, "{3:3}": 1}, sizes(supports), "%v", supports)
+	for _, support := range supports {
+		x.Equal(2, support)
+	}
+}
+
+func TestInducedCycle(t *testing.T) {
+	x := assert.New(t)
+	dt := cycleDigraph(t, MNI|Induced, "a", "b", "c", "d")
+	defer dt.Close()
+	supports := patternSupports(t, dt)
+	// paths of three vertices are induced but paths of four are not
+	x.Equal(map[string]int{"{0:1}": 4, "{1:2}": 4, "{2:3}": 4, "{4:4}": 1}, sizes(supports), "%v", supports)
+}
+
+func TestNotInducedTriangle(t *testing.T) {
+	x := assert.New(t)
+	dt := cycleDigraph(t, MNI, "a", "b", "c")
+	defer dt.Close()
+	supports := patternSupports(t, dt)
+	x.Equal(map[string]int{"{0:1}": 3, "{1:2}": 3, "{2:3}": 3, "{3:3}": 1}, sizes(supports), "%v", supports)
+}
+
+func TestInducedParentsChildren(t *testing.T) {
+	x := assert.New(t)
+	dt := cycleDigraph(t, MNI|Induced, "a", "b", "c", "d")
+	defer dt.Close()
+	name := func(n lattice.Node) string {
+		return n.(*EmbListNode).Pat.Pretty(dt.Labels)
+	}
+	seen := make(map[string]bool)
+	queue := []lattice.Node{RootEmbListNode(dt)}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		kids, err := n.Children()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, kid := range kids {
+			parents, err := kid.Parents()
+			if err != nil {
+				t.Fatal(err)
+			}
+			found := false
+			for _, p := range parents {
+				if p.(*EmbListNode).isRoot() || name(p) == name(n) {
+					found = true
+				}
+			}
+			x.True(found, "%v is not a parent of its child %v", n, kid)
+			if !seen[name(kid)] {
+				seen[name(kid)] = true
+				queue = append(queue, kid)
+			}
+		}
+	}
+	x.Equal(13, len(seen))
+}
+
+func TestInducedModeNeedsEmbeddings(t *testing.T) {
+	x := assert.New(t)
+	_, err := NewDigraph(&config.Config{Support: 2}, &Config{
+		Mode:                MNI | Induced | ExtFromFreqEdges,
+		EmbSearchStartPoint: subgraph.RandomStart,
+	})
+	x.Error(err)
+}
+
+func TestInducedDoEmbeddings(t *testing.T) {
+	x := assert.New(t)
+	count := func(dt *Digraph, pattern *subgraph.SubGraph) int {
+		embs := 0
+		err := dt.DoEmbeddings(pattern, func(*subgraph.Embedding) error {
+			embs++
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return embs
+	}
+	path := func(dt *Digraph, names ...string) *subgraph.SubGraph {
+		b := subgraph.Build(len(names), len(names)-1)
+		var prev *subgraph.Vertex
+		for _, name := range names {
+			v := b.AddVertex(dt.Labels.Color(name))
+			if prev != nil {
+				b.AddEdge(prev, v, dt.Labels.Color("e"))
+			}
+			prev = v
+		}
+		return b.Build()
+	}
+	induced := cycleDigraph(t, MNI|Induced, "a", "b", "c")
+	defer induced.Close()
+	all := cycleDigraph(t, MNI, "a", "b", "c")
+	defer all.Close()
+	// the c -> a edge closes every a -> b -> c path
+	x.Equal(0, count(induced, path(induced, "a", "b", "c")))
+	x.Equal(2, count(all, path(all, "a", "b", "c")))
+	x.Equal(2, count(induced, path(induced, "a", "b")))
+	x.Equal(2, count(all, path(all, "a", "b")))
+}
@@ -35,8 +35,16 @@ func (v *IntLoader) Load(input lattice.Input) (dt lattice.DataType, err error) {
 	return v.LoadWithLabels(input, digraph.NewLabels())
 }
 
+func (v *IntLoader) LoadDir(inputs []lattice.NamedInput) (dt lattice.DataType, err error) {
+	return v.loadWithLabels(inputs, digraph.NewLabels())
+}
+
 func (v *IntLoader) LoadWithLabels(input lattice.Input, labels *digraph.Labels) (lattice.DataType, error) {
-	G, err := v.loadDigraph(input, labels)
+	return v.loadWithLabels([]lattice.NamedInput{{Input: input}}, labels)
+}
+
+func (v *IntLoader) loadWithLabels(inputs []lattice.NamedInput, labels *digraph.Labels) (lattice.DataType, error) {
+	G, err := v.loadDigraph(inputs, labels)
 	if err != nil {
 		return nil, err
 	}
@@ -47,38 +55,46 @@ func (v *IntLoader) LoadWithLabels(input lattice.Input, labels *digraph.Labels)
 	return v.dt, nil
 }
 
-func (v *IntLoader) loadDigraph(input lattice.Input, labels *digraph.Labels) (*digraph.Builder, error) {
+func (v *IntLoader) loadDigraph(inputs []lattice.NamedInput, labels *digraph.Labels) (*digraph.Builder, error) {
 	var errs ErrorList
-	V, E, err := intGraphSize(input)
-	if err != nil {
-		return nil, err
+	V, E := 0, 0
+	for _, input := range inputs {
+		fV, fE, err := intGraphSize(input.Input)
+		if err != nil {
+			return nil, err
+		}
+		V += fV
+		E += fE
 	}
 	errors.Logf("DEBUG", "Got graph size %v %v", V, E)
 	G := digraph.Build(V, E)
-	b := newBaseLoader(v.dt, G)
+	b := newBaseLoader(v.dt, G, labels)
 
-	in, closer := input()
-	defer closer()
-	err = processLines(in, func(line []byte) {
-		if len(line) == 0 || bytes.Contains(line, []byte("#")) {
-			return
-		}
-		line_type, data := intParseLine(line)
-		switch line_type {
-		case "v":
-			if err := v.loadVertex(labels, b, data); err != nil {
-				errs = append(errs, err)
+	for ns, input := range inputs {
+		b.startFile(ns, input)
+		in, closer := input.Input()
+		err := processLines(in, func(line []byte) {
+			if len(line) == 0 || bytes.Contains(line, []byte("#")) {
+				return
 			}
-		case "e":
-			if err := v.loadEdge(labels, b, data); err != nil {
-				errs = append(errs, err)
+			line_type, data := intParseLine(line)
+			switch line_type {
+			case "v":
+				if err := v.loadVertex(labels, b, data); err != nil {
+					errs = append(errs, err)
+				}
+			case "e":
+				if err := v.loadEdge(labels, b, data); err != nil {
+					errs = append(errs, err)
+				}
+			default:
+				errs = append(errs, errors.Errorf("Unknown line type %v", line_type))
 			}
-		default:
-			errs = append(errs, errors.Errorf("Unknown line type %v", line_type))
+		})
+		closer()
+		if err != nil {
+			return nil, err
 		}
-	})
-	if err != nil {
-		return nil, err
 	}
 	if len(errs) == 0 {
 		return G, nil
@@ -107,7 +123,7 @@ func (v *IntLoader) loadEdge(labels *digraph.Labels, b *baseLoader, data []byte)
 		return err
 	}
 	label := string(split[2])
-	return b.addEdge(int32(src), int32(targ), labels.Color(label), label )
+	return b.addEdge(int32(src), int32(targ), labels.Color(label), label, nil)
 }
 
 func intParseLine(line []byte) (line_type string, data []byte) {
@@ -0,0 +1,149 @@
+package digraph
+
+import ()
+
+import (
+	"github.com/timtadh/regrax/types/digraph/subgraph"
+)
+
+// saveLabelSets adds the label sets of the pattern's vertices to the
+// LabelSets store so the colors of cached patterns keep their meaning.
+func saveLabelSets(dt *Digraph, pattern *subgraph.SubGraph) error {
+	if dt.LabelSets == nil {
+		return nil
+	}
+	for idx := range pattern.V {
+		color := pattern.V[idx].Color
+		if !dt.Labels.IsSet(color) {
+			continue
+		}
+		key := []int32{int32(color)}
+		if has, err := dt.LabelSets.Has(key); err != nil {
+			return err
+		} else if has {
+			continue
+		}
+		extra := dt.Labels.Extra(color)
+		set := make([]int32, 0, len(extra)+1)
+		set = append(set, int32(dt.Labels.Base(color)))
+		for _, l := range extra {
+			set = append(set, int32(l))
+		}
+		err := dt.LabelSets.Add(key, set)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadLabelSets restores the label sets of the pattern's vertices missing
+// from dt.Labels from the LabelSets store.
+func loadLabelSets(dt *Digraph, pattern *subgraph.SubGraph) error {
+	if dt.LabelSets == nil {
+		return nil
+	}
+	for idx := range pattern.V {
+		color := pattern.V[idx].Color
+		if dt.Labels.IsSet(color) {
+			continue
+		}
+		err := dt.LabelSets.DoFind([]int32{int32(color)}, func(_ []int32, set []int32) error {
+			extra := make([]int, 0, len(set)-1)
+			for _, l := range set[1:] {
+				extra = append(extra, int(l))
+			}
+			dt.Labels.AddSet(color, int(set[0]), extra)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// relabelExts calls do with the extensions adding each of the labels to the
+// vertex idx of the pattern. Labels the vertex already has and labels which
+// are infrequent on vertices of its color are skipped.
+func relabelExts(dt *Digraph, pattern *subgraph.SubGraph, idx int, labels []int, do func(*subgraph.Extension)) {
+	color := pattern.V[idx].Color
+	base := dt.Labels.Base(color)
+	has := dt.Labels.Extra(color)
+	freq := dt.Indices.FreqLabels[base]
+	for _, l := range labels {
+		if containsLabel(has, l) || !containsLabel(freq, l) {
+			continue
+		}
+		extra := make([]int, 0, len(has)+1)
+		extra = append(extra, has...)
+		extra = append(extra, l)
+		v := subgraph.Vertex{Idx: idx, Color: dt.Labels.SetColor(base, extra)}
+		ext := subgraph.NewExt(v, v, l)
+		ext.Relabel = true
+		do(ext)
+	}
+}
+
+// labelParents gives the patterns with one extra label less on one vertex.
+func labelParents(dt *Digraph, b *subgraph.Builder) []*subgraph.Builder {
+	parents := make([]*subgraph.Builder, 0, len(b.V))
+	if dt.Mode&LabelSets == 0 {
+		return parents
+	}
+	for idx := range b.V {
+		extra := dt.Labels.Extra(b.V[idx].Color)
+		for i := range extra {
+			parents = append(parents, dropLabel(dt, b, idx, i))
+		}
+	}
+	return parents
+}
+
+// firstLabelParent is the canonical parent of a pattern with extra labels:
+// the last label of the last vertex with extra labels is dropped. It is nil
+// for patterns without extra labels.
+func firstLabelParent(dt *Digraph, b *subgraph.Builder) *subgraph.Builder {
+	if dt.Mode&LabelSets == 0 {
+		return nil
+	}
+	for idx := len(b.V) - 1; idx >= 0; idx-- {
+		if extra := dt.Labels.Extra(b.V[idx].Color); len(extra) > 0 {
+			return dropLabel(dt, b, idx, len(extra)-1)
+		}
+	}
+	return nil
+}
+
+func dropLabel(dt *Digraph, b *subgraph.Builder, idx, i int) *subgraph.Builder {
+	color := b.V[idx].Color
+	extra := dt.Labels.Extra(color)
+	rest := make([]int, 0, len(extra)-1)
+	rest = append(rest, extra[:i]...)
+	rest = append(rest, extra[i+1:]...)
+	nb := b.Copy()
+	nb.V[idx].Color = dt.Labels.SetColor(dt.Labels.Base(color), rest)
+	return nb
+}
+
+// hasExtraLabels is true if some vertex of the pattern has extra labels.
+func hasExtraLabels(dt *Digraph, sg *subgraph.SubGraph) bool {
+	if dt.Mode&LabelSets == 0 {
+		return false
+	}
+	for idx := range sg.V {
+		if len(dt.Labels.Extra(sg.V[idx].Color)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func containsLabel(labels []int, l int) bool {
+	for _, x := range labels {
+		if x == l {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package digraph
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/types/digraph/digraph"
+	"github.com/timtadh/regrax/types/digraph/subgraph"
+)
+
+// labelSetDigraph has 20 a -> b edges. The first 12 a vertices also have
+// the label x and the first 4 of them the label y as well.
+func labelSetDigraph(t testing.TB, mode Mode) *Digraph {
+	labels := digraph.NewLabels()
+	b := digraph.Build(40, 20)
+	dt, err := NewDigraph(&config.Config{Support: 2}, &Config{
+		MaxEdges:            1,
+		Mode:                mode | LabelSets | ExtFromEmb | Caching,
+		EmbSearchStartPoint: subgraph.RandomStart,
+		ApproxSamples:       100,
+		ApproxConfidence:    .9,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, y := labels.Color("x"), labels.Color("y")
+	for i := 0; i < 20; i++ {
+		u := b.AddVertex(labels.Color("a"))
+		v := b.AddVertex(labels.Color("b"))
+		b.AddEdge(u, v, labels.Color("e"))
+		switch {
+		case i < 4:
+			dt.VertexLabels = append(dt.VertexLabels, []int{y, x})
+		case i < 12:
+			dt.VertexLabels = append(dt.VertexLabels, []int{x})
+		default:
+			dt.VertexLabels = append(dt.VertexLabels, nil)
+		}
+		dt.VertexLabels = append(dt.VertexLabels, nil)
+	}
+	err = dt.Init(b, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dt
+}
+
+func TestLabelSetMatching(t *testing.T) {
+	x := assert.New(t)
+	dt := labelSetDigraph(t, MNI)
+	defer dt.Close()
+	supports := patternSupports(t, dt)
+	// a pattern vertex matches the vertices whose labels contain its own
+	x.Equal(20, supports["{0:1}(a)"])
+	x.Equal(12, supports["{0:1}(a{x})"])
+	x.Equal(4, supports["{0:1}(a{x,y})"])
+	x.Equal(4, supports["{0:1}(a{y})"])
+	x.Equal(12, supports["{1:2}(a{x})(b)[0->1:e]"])
+	x.Equal(4, supports["{1:2}(a{x,y})(b)[0->1:e]"])
+}
+
+func TestLabelSetsCached(t *testing.T) {
+	x := assert.New(t)
+	dt := labelSetDigraph(t, MNI)
+	defer dt.Close()
+	n := edgeNodes(t, dt)["{1:2}(a{x,y})(b)[0->1:e]"]
+	if !x.NotNil(n) {
+		return
+	}
+	// the same names without the label sets (as when the labels are read
+	// back without the run which made them)
+	labels := digraph.NewLabels()
+	for _, name := range dt.Labels.Labels() {
+		labels.Color(name)
+	}
+	dt.Labels = labels
+	has, support, _, _, _, _, err := loadCachedExtsEmbs(dt, n.Pat)
+	x.Nil(err)
+	x.True(has)
+	x.Equal(4, support)
+	for idx := range n.Pat.V {
+		if labels.Label(n.Pat.V[idx].Color) == "a{x,y}" {
+			color := n.Pat.V[idx].Color
+			x.Equal(labels.Color("a"), labels.Base(color))
+			x.Equal([]int{labels.Color("x"), labels.Color("y")}, labels.Extra(color))
+		}
+	}
+}
+
+func TestLabelSetNotInputLabel(t *testing.T) {
+	x := assert.New(t)
+	labels := digraph.NewLabels()
+	a, l := labels.Color("a"), labels.Color("x")
+	set := labels.SetColor(a, []int{l})
+	// an input label named like the label set is a plain label
+	input := labels.Color("a{x}")
+	x.NotEqual(set, input)
+	x.Equal(set, labels.SetColor(a, []int{l}))
+	x.Equal(input, labels.Color("a{x}"))
+	x.Equal("a{x}", labels.Label(set))
+	x.Equal("a{x}", labels.Label(input))
+	x.True(labels.IsSet(set))
+	x.Equal(a, labels.Base(set))
+	x.Equal([]int{l}, labels.Extra(set))
+	x.False(labels.IsSet(input))
+	x.Equal(input, labels.Base(input))
+	x.Nil(labels.Extra(input))
+}
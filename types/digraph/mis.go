@@ -0,0 +1,222 @@
+package digraph
+
+import (
+	"github.com/timtadh/data-structures/errors"
+)
+
+import (
+	"github.com/timtadh/regrax/types/digraph/subgraph"
+)
+
+// Components of the overlap graph up to this size are solved exactly. The
+// exact solver works on 64 bit vertex sets so this may not exceed 64.
+const MIS_EXACT_LIMIT = 64
+
+// Bound on the number of branches the exact solver explores on a single
+// component before settling for the best independent set found so far.
+const MIS_BRANCH_LIMIT = 1 << 20
+
+// maxIndependentEmbeddings computes the maximum independent set of the
+// overlap graph of the embeddings. In the overlap graph each embedding is a
+// vertex and two embeddings are adjacent if they share a vertex of G. The
+// size of the maximum independent set is an anti-monotone support measure
+// (Vanetik et al. 2002). Each connected component of the overlap graph is
+// solved on its own: exactly if it is small enough and otherwise with a
+// greedy minimum degree heuristic (which gives a lower bound).
+func maxIndependentEmbeddings(embs []*subgraph.Embedding) []*subgraph.Embedding {
+	g := newOverlapGraph(embs)
+	mis := make([]*subgraph.Embedding, 0, len(embs))
+	for _, comp := range g.components() {
+		var chosen []int
+		if len(comp) <= MIS_EXACT_LIMIT {
+			chosen = g.exactMIS(comp)
+		} else {
+			chosen = g.greedyMIS(comp)
+		}
+		for _, i := range chosen {
+			mis = append(mis, embs[i])
+		}
+	}
+	return mis
+}
+
+// overlapGraph is the overlap graph of the embeddings indexed by the
+// vertices of G. Its edges are never materialized: the embeddings sharing
+// a vertex form a clique so listing them per vertex keeps the graph linear
+// in the size of the embeddings.
+type overlapGraph struct {
+	vertices [][]int       // the distinct vertices of G in each embedding
+	byVertex map[int][]int // the embeddings containing each vertex of G
+}
+
+func newOverlapGraph(embs []*subgraph.Embedding) *overlapGraph {
+	g := &overlapGraph{
+		vertices: make([][]int, len(embs)),
+		byVertex: make(map[int][]int),
+	}
+	for i, emb := range embs {
+		vertices := make([]int, 0, len(emb.Ids))
+		for _, id := range emb.Ids {
+			if n := len(g.byVertex[id]); n > 0 && g.byVertex[id][n-1] == i {
+				continue
+			}
+			g.byVertex[id] = append(g.byVertex[id], i)
+			vertices = append(vertices, id)
+		}
+		g.vertices[i] = vertices
+	}
+	return g
+}
+
+// neighbors calls do on every embedding overlapping i (possibly more than
+// once).
+func (g *overlapGraph) neighbors(i int, do func(j int)) {
+	for _, id := range g.vertices[i] {
+		for _, j := range g.byVertex[id] {
+			if j != i {
+				do(j)
+			}
+		}
+	}
+}
+
+// components of the overlap graph. Every vertex of G is expanded once.
+func (g *overlapGraph) components() [][]int {
+	comps := make([][]int, 0, 10)
+	seen := make([]bool, len(g.vertices))
+	expanded := make(map[int]bool, len(g.byVertex))
+	for s := range g.vertices {
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		comp := []int{s}
+		for k := 0; k < len(comp); k++ {
+			for _, id := range g.vertices[comp[k]] {
+				if expanded[id] {
+					continue
+				}
+				expanded[id] = true
+				for _, j := range g.byVertex[id] {
+					if !seen[j] {
+						seen[j] = true
+						comp = append(comp, j)
+					}
+				}
+			}
+		}
+		comps = append(comps, comp)
+	}
+	return comps
+}
+
+func (g *overlapGraph) greedyMIS(comp []int) []int {
+	alive := make(map[int]bool, len(comp))
+	for _, v := range comp {
+		alive[v] = true
+	}
+	degree := func(v int) int {
+		nbrs := make(map[int]bool)
+		g.neighbors(v, func(u int) {
+			if alive[u] {
+				nbrs[u] = true
+			}
+		})
+		return len(nbrs)
+	}
+	chosen := make([]int, 0, len(comp))
+	for len(alive) > 0 {
+		min := -1
+		minDeg := 0
+		for _, v := range comp {
+			if !alive[v] {
+				continue
+			}
+			if d := degree(v); min == -1 || d < minDeg {
+				min = v
+				minDeg = d
+			}
+		}
+		chosen = append(chosen, min)
+		delete(alive, min)
+		g.neighbors(min, func(u int) {
+			delete(alive, u)
+		})
+	}
+	return chosen
+}
+
+func (g *overlapGraph) exactMIS(comp []int) []int {
+	n := uint(len(comp))
+	local := make(map[int]uint, n)
+	for i, v := range comp {
+		local[v] = uint(i)
+	}
+	nbrs := make([]uint64, n)
+	for i, v := range comp {
+		g.neighbors(v, func(u int) {
+			nbrs[i] |= 1 << local[u]
+		})
+	}
+	popcount := func(x uint64) int {
+		c := 0
+		for ; x != 0; x &= x - 1 {
+			c++
+		}
+		return c
+	}
+	var best uint64
+	bestSize := 0
+	branches := 0
+	var search func(cur uint64, size int, P uint64)
+	search = func(cur uint64, size int, P uint64) {
+		branches++
+		if P == 0 {
+			if size > bestSize {
+				best = cur
+				bestSize = size
+			}
+			return
+		}
+		if size+popcount(P) <= bestSize || branches > MIS_BRANCH_LIMIT {
+			return
+		}
+		// vertices of degree <= 1 are always in some maximum independent
+		// set. otherwise branch on the vertex of maximum degree.
+		pick := uint(0)
+		pickDeg := -1
+		for i := uint(0); i < n; i++ {
+			if P&(1<<i) == 0 {
+				continue
+			}
+			d := popcount(nbrs[i] & P)
+			if d <= 1 {
+				search(cur|(1<<i), size+1, P&^(nbrs[i]|(1<<i)))
+				return
+			}
+			if d > pickDeg {
+				pick = i
+				pickDeg = d
+			}
+		}
+		search(cur|(1<<pick), size+1, P&^(nbrs[pick]|(1<<pick)))
+		search(cur, size, P&^(1<<pick))
+	}
+	var all uint64
+	if n == 64 {
+		all = ^uint64(0)
+	} else {
+		all = (1 << n) - 1
+	}
+	search(0, 0, all)
+	if branches > MIS_BRANCH_LIMIT {
+		errors.Logf("DEBUG", "MIS branch limit reached on overlap component of size %v (found %v)", n, bestSize)
+	}
+	chosen := make([]int, 0, bestSize)
+	for i := uint(0); i < n; i++ {
+		if best&(1<<i) != 0 {
+			chosen = append(chosen, comp[i])
+		}
+	}
+	return chosen
+}
@@ -0,0 +1,92 @@
+package digraph
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/types/digraph/digraph"
+	"github.com/timtadh/regrax/types/digraph/subgraph"
+)
+
+func idEmbeddings(ids ...[]int) []*subgraph.Embedding {
+	embs := make([]*subgraph.Embedding, 0, len(ids))
+	for _, x := range ids {
+		embs = append(embs, &subgraph.Embedding{Ids: x})
+	}
+	return embs
+}
+
+func TestMaxIndependentEmbeddings(t *testing.T) {
+	x := assert.New(t)
+	// a path of overlaps (0 - 1 - 2 - 3 - 4), a star sharing vertex 10 and
+	// an embedding overlapping nothing
+	embs := idEmbeddings(
+		[]int{0, 1}, []int{1, 2}, []int{2, 3}, []int{3, 4}, []int{4, 5},
+		[]int{10, 11}, []int{10, 12}, []int{13, 10},
+		[]int{20, 20},
+	)
+	mis := maxIndependentEmbeddings(embs)
+	x.Equal(5, len(mis))
+	used := make(map[int]*subgraph.Embedding)
+	for _, emb := range mis {
+		for _, id := range emb.Ids {
+			if other, has := used[id]; has {
+				x.True(other == emb, "%v overlaps %v", emb, other)
+			}
+			used[id] = emb
+		}
+	}
+	g := newOverlapGraph(embs)
+	x.Equal(3, len(g.components()))
+	x.Equal([]int{20}, g.vertices[8])
+}
+
+func TestMaxIndependentEmbeddingsGreedy(t *testing.T) {
+	x := assert.New(t)
+	// a path too long to solve exactly. the minimum degree heuristic is
+	// optimal on paths.
+	ids := make([][]int, 0, 2*MIS_EXACT_LIMIT+1)
+	for i := 0; i < 2*MIS_EXACT_LIMIT+1; i++ {
+		ids = append(ids, []int{i, i + 1})
+	}
+	x.Equal(MIS_EXACT_LIMIT+1, len(maxIndependentEmbeddings(idEmbeddings(ids...))))
+}
+
+// misDigraph is a triangle of a vertices: every pair of a -> a embeddings
+// overlaps.
+func misDigraph(t testing.TB, mode Mode) *Digraph {
+	labels := digraph.NewLabels()
+	b := digraph.Build(3, 3)
+	vs := make([]*digraph.Vertex, 0, 3)
+	for i := 0; i < 3; i++ {
+		vs = append(vs, b.AddVertex(labels.Color("a")))
+	}
+	for i := range vs {
+		b.AddEdge(vs[i], vs[(i+1)%len(vs)], labels.Color("e"))
+	}
+	dt, err := NewDigraph(&config.Config{Support: 1}, &Config{
+		MaxEdges:            2,
+		Mode:                mode | ExtFromEmb,
+		EmbSearchStartPoint: subgraph.RandomStart,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = dt.Init(b, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dt
+}
+
+func TestMISSupport(t *testing.T) {
+	x := assert.New(t)
+	mni := misDigraph(t, MNI)
+	defer mni.Close()
+	mis := misDigraph(t, MIS)
+	defer mis.Close()
+	edge := "{1:2}(a)(a)[0->1:e]"
+	x.Equal(3, patternSupports(t, mni)[edge])
+	x.Equal(1, patternSupports(t, mis)[edge])
+}
@@ -12,4 +12,14 @@ const (
 	ExtFromEmb           // extend the lattice node from its embeddings
 	ExtFromFreqEdges     // extend the lattice node from the frequent edges
 	Caching              // enable caching layer (not good for complete mining)
+	Transactions         // count support as the number of input graphs with an embedding
+	MIS                  // Maximum Independent Set of the embedding overlap graph
+	Induced              // only count induced embeddings (no extra edges between the embedded vertices)
+	Homomorphism         // embeddings may map several pattern vertices to the same vertex
+	Approximate          // estimate MNI support from a sample of the embeddings
+	WeightedSum          // sum of the total weights of the embeddings
+	WeightedMin          // sum of the min weights of the embeddings
+	WeightedMNI          // Min-Image Support where each image counts its weight
+	Temporal             // embeddings must respect the time order of the pattern edges
+	LabelSets            // vertices have sets of labels and patterns can add labels to vertices
 )
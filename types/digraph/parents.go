@@ -11,6 +11,7 @@ import (
 	"github.com/timtadh/regrax/lattice"
 	"github.com/timtadh/regrax/stores/bytes_bytes"
 	"github.com/timtadh/regrax/stores/bytes_int"
+	"github.com/timtadh/regrax/types/digraph/subgraph"
 )
 
 
@@ -21,7 +22,7 @@ func parents(n Node, parents bytes_bytes.MultiMap, parentCount bytes_int.MultiMa
 	}
 	dt := n.dt()
 	sg := n.SubGraph()
-	if len(sg.V) == 1 && len(sg.E) == 0 {
+	if len(sg.V) == 1 && len(sg.E) == 0 && !hasExtraLabels(dt, sg) {
 		return []lattice.Node{dt.Root()}, nil
 	}
 	if nodes, has, err := cachedAdj(n, dt, dt.ParentCount, dt.Parents); err != nil {
@@ -29,10 +30,16 @@ func parents(n Node, parents bytes_bytes.MultiMap, parentCount bytes_int.MultiMa
 	} else if has {
 		return nodes, nil
 	}
-	parentBuilders, err := AllParents(n.SubGraph().Builder())
-	if err != nil {
-		return nil, err
+	var parentBuilders []*subgraph.Builder
+	if dt.Mode&Induced == Induced {
+		parentBuilders = InducedParents(n.SubGraph().Builder())
+	} else {
+		parentBuilders, err = AllParents(n.SubGraph().Builder())
+		if err != nil {
+			return nil, err
+		}
 	}
+	parentBuilders = append(parentBuilders, labelParents(dt, n.SubGraph().Builder())...)
 	seen := set.NewSortedSet(10)
 	nodes = make([]lattice.Node, 0, 10)
 	for _, pBuilder := range parentBuilders {
@@ -53,7 +60,7 @@ func parents(n Node, parents bytes_bytes.MultiMap, parentCount bytes_int.MultiMa
 
 			errors.Logf("WARN", "for node %v parent %v had support %v less than required %v due to automorphism", n, parent.Pretty(dt.Labels), support, dt.Support())
 		} else {
-			nodes = append(nodes, n.New(parent, pexts, pembs, poverlap, punsupEmbs))
+			nodes = append(nodes, n.New(parent, support, pexts, pembs, poverlap, punsupEmbs))
 		}
 	}
 	if len(nodes) == 0 {
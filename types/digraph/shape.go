@@ -0,0 +1,79 @@
+package digraph
+
+import ()
+
+import (
+	"github.com/timtadh/regrax/types/digraph/subgraph"
+)
+
+type Shape uint64
+
+// Every shape is closed under taking connected sub-patterns so restricting
+// the candidate children of a node never disconnects the lattice.
+const (
+	Paths         Shape = 1 << iota // simple paths (ignoring edge direction)
+	DirectedPaths                   // paths with every edge pointing the same way
+	Trees                           // trees (ignoring edge direction)
+	DAGs                            // patterns without a directed cycle
+)
+
+// allowedShape checks the pattern being built against the shape and degree
+// constraints of the config.
+func (c *Config) allowedShape(b *subgraph.Builder) bool {
+	if c.Shape == 0 && c.MaxInDegree <= 0 && c.MaxOutDegree <= 0 {
+		return true
+	}
+	indeg := make([]int, len(b.V))
+	outdeg := make([]int, len(b.V))
+	for i := range b.E {
+		outdeg[b.E[i].Src]++
+		indeg[b.E[i].Targ]++
+	}
+	for idx := range b.V {
+		if c.MaxInDegree > 0 && indeg[idx] > c.MaxInDegree {
+			return false
+		}
+		if c.MaxOutDegree > 0 && outdeg[idx] > c.MaxOutDegree {
+			return false
+		}
+		if c.Shape&(Paths|DirectedPaths) != 0 && indeg[idx]+outdeg[idx] > 2 {
+			return false
+		}
+		if c.Shape&DirectedPaths == DirectedPaths && (indeg[idx] > 1 || outdeg[idx] > 1) {
+			return false
+		}
+	}
+	// patterns are connected so they are trees exactly when they have one
+	// fewer edges than vertices. (a path is a tree of max degree 2.)
+	if c.Shape&(Paths|DirectedPaths|Trees) != 0 && len(b.E) >= len(b.V) {
+		return false
+	}
+	if c.Shape&DAGs == DAGs && !acyclic(b, indeg) {
+		return false
+	}
+	return true
+}
+
+// acyclic runs Kahn's algorithm over the pattern. It consumes indeg.
+func acyclic(b *subgraph.Builder, indeg []int) bool {
+	kids := b.Kids()
+	queue := make([]int, 0, len(b.V))
+	for idx := range b.V {
+		if indeg[idx] == 0 {
+			queue = append(queue, idx)
+		}
+	}
+	visited := 0
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		visited++
+		for _, e := range kids[u] {
+			indeg[e.Targ]--
+			if indeg[e.Targ] == 0 {
+				queue = append(queue, e.Targ)
+			}
+		}
+	}
+	return visited == len(b.V)
+}
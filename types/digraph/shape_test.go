@@ -0,0 +1,114 @@
+package digraph
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+import (
+	"strings"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/types/digraph/digraph"
+	"github.com/timtadh/regrax/types/digraph/subgraph"
+)
+
+// shapeDigraph has two copies of the triangle a -> b -> c -> a with an
+// extra edge a -> d.
+func shapeDigraph(t testing.TB, shape Shape, maxIn, maxOut int) *Digraph {
+	labels := digraph.NewLabels()
+	b := digraph.Build(8, 8)
+	for c := 0; c < 2; c++ {
+		a := b.AddVertex(labels.Color("a"))
+		bv := b.AddVertex(labels.Color("b"))
+		cv := b.AddVertex(labels.Color("c"))
+		d := b.AddVertex(labels.Color("d"))
+		b.AddEdge(a, bv, labels.Color("e"))
+		b.AddEdge(bv, cv, labels.Color("e"))
+		b.AddEdge(cv, a, labels.Color("e"))
+		b.AddEdge(a, d, labels.Color("e"))
+	}
+	dt, err := NewDigraph(&config.Config{Support: 2}, &Config{
+		MaxEdges:            4,
+		Mode:                MNI | ExtFromEmb | Caching,
+		EmbSearchStartPoint: subgraph.RandomStart,
+		Shape:               shape,
+		MaxInDegree:         maxIn,
+		MaxOutDegree:        maxOut,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = dt.Init(b, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dt
+}
+
+func TestAllowedShape(t *testing.T) {
+	x := assert.New(t)
+	// b <- a -> c
+	star := subgraph.Build(3, 2)
+	a := star.AddVertex(0)
+	star.AddEdge(a, star.AddVertex(1), 0)
+	star.AddEdge(a, star.AddVertex(2), 0)
+	x.True((&Config{Shape: Paths}).allowedShape(star))
+	x.True((&Config{Shape: Trees | DAGs}).allowedShape(star))
+	x.False((&Config{Shape: DirectedPaths}).allowedShape(star))
+	x.False((&Config{MaxOutDegree: 1}).allowedShape(star))
+	x.True((&Config{MaxInDegree: 1}).allowedShape(star))
+	// a -> b -> a
+	cycle := subgraph.Build(2, 2)
+	u := cycle.AddVertex(0)
+	v := cycle.AddVertex(1)
+	cycle.AddEdge(u, v, 0)
+	cycle.AddEdge(v, u, 0)
+	x.True((&Config{}).allowedShape(cycle))
+	x.False((&Config{Shape: Trees}).allowedShape(cycle))
+	x.False((&Config{Shape: DAGs}).allowedShape(cycle))
+}
+
+// The shapes are closed under connected sub-patterns so mining with a shape
+// finds exactly the unconstrained patterns of that shape.
+func TestShapeConstraints(t *testing.T) {
+	all := shapeDigraph(t, 0, 0, 0)
+	defer all.Close()
+	patterns := patternNodes(t, all)
+	x := assert.New(t)
+	whole := 0
+	for label := range patterns {
+		if strings.HasPrefix(label, "{4:4}") {
+			whole++
+		}
+	}
+	x.Equal(1, whole, "%v", patterns)
+	for _, c := range []struct {
+		shape         Shape
+		maxIn, maxOut int
+	}{
+		{Paths, 0, 0},
+		{DirectedPaths, 0, 0},
+		{Trees, 0, 0},
+		{DAGs, 0, 0},
+		{0, 0, 1},
+	} {
+		dt := shapeDigraph(t, c.shape, c.maxIn, c.maxOut)
+		found := patternNodes(t, dt)
+		expected := make(map[string]bool)
+		for label, n := range patterns {
+			if dt.allowedShape(subgraph.Build(0, 0).From(n.Pat)) {
+				expected[label] = true
+			}
+		}
+		x.True(len(expected) < len(patterns))
+		for label := range found {
+			x.True(expected[label], "%v found with %v", label, c)
+		}
+		for label := range expected {
+			_, has := found[label]
+			x.True(has, "%v missing with %v", label, c)
+		}
+		dt.Close()
+	}
+}
@@ -6,6 +6,7 @@ import (
 )
 
 import (
+	"github.com/timtadh/regrax/metrics"
 	"github.com/timtadh/regrax/types/digraph/digraph"
 )
 
@@ -30,7 +31,8 @@ func (b *Builder) From(sg *SubGraph) *Builder {
 		b.AddVertex(sg.V[i].Color)
 	}
 	for i := range sg.E {
-		b.AddEdge(&b.V[sg.E[i].Src], &b.V[sg.E[i].Targ], sg.E[i].Color)
+		e := b.AddEdge(&b.V[sg.E[i].Src], &b.V[sg.E[i].Targ], sg.E[i].Color)
+		e.Time = sg.E[i].Time
 	}
 	return b
 }
@@ -113,6 +115,36 @@ func (b *Builder) RemoveEdge(edgeIdx int) error {
 	return nil
 }
 
+// RemoveVertex removes the vertex and all of its edges.
+func (b *Builder) RemoveVertex(vertexIdx int) {
+	adjustIdx := func(idx int) int {
+		if idx > vertexIdx {
+			return idx - 1
+		}
+		return idx
+	}
+	V := make([]Vertex, 0, len(b.V))
+	for idx := range b.V {
+		if idx != vertexIdx {
+			V = append(V, Vertex{Idx: adjustIdx(idx), Color: b.V[idx].Color})
+		}
+	}
+	E := make([]Edge, 0, len(b.E))
+	for idx := range b.E {
+		e := &b.E[idx]
+		if e.Src == vertexIdx || e.Targ == vertexIdx {
+			continue
+		}
+		E = append(E, Edge{
+			Src:   adjustIdx(e.Src),
+			Targ:  adjustIdx(e.Targ),
+			Color: e.Color,
+			Time:  e.Time,
+		})
+	}
+	b.V, b.E = V, E
+}
+
 func (b *Builder) droppedVertexOnEdgeRm(edgeIdx int) (drop bool, idx int, err error) {
 	edge := &b.E[edgeIdx]
 	rmSrc := true
@@ -159,6 +191,14 @@ func (b *Builder) removeVertexAndEdge(dropVertex bool, vertexIdx, edgeIdx int) (
 		}
 		V = append(V, Vertex{Idx: adjustIdx(idx), Color: b.V[idx].Color})
 	}
+	// the edges after the removed edge in the temporal order move up
+	rmTime := b.E[edgeIdx].Time
+	adjustTime := func(t int) int {
+		if rmTime > 0 && t > rmTime {
+			return t - 1
+		}
+		return t
+	}
 	E := make([]Edge, 0, len(b.E)-1)
 	for idx := range b.E {
 		if idx == edgeIdx {
@@ -168,6 +208,7 @@ func (b *Builder) removeVertexAndEdge(dropVertex bool, vertexIdx, edgeIdx int) (
 			Src:   adjustIdx(b.E[idx].Src),
 			Targ:  adjustIdx(b.E[idx].Targ),
 			Color: b.E[idx].Color,
+			Time:  adjustTime(b.E[idx].Time),
 		})
 	}
 	return V, E
@@ -181,6 +222,13 @@ func (b *Builder) Extend(e *Extension) (newe *Edge, newv *Vertex, err error) {
 	} else if e.Source.Idx == len(b.V) && e.Target.Idx == len(b.V) {
 		return nil, nil, errors.Errorf("Only one new vertice allowed (Extension would create a disconnnected graph)")
 	}
+	if e.Relabel {
+		if e.Source.Idx == len(b.V) {
+			return nil, nil, errors.Errorf("Cannot relabel the new vertex %v", e.Source.Idx)
+		}
+		b.V[e.Source.Idx].Color = e.Source.Color
+		return nil, nil, nil
+	}
 	var src *Vertex = &e.Source
 	var targ *Vertex = &e.Target
 	if e.Source.Idx == len(b.V) {
@@ -190,7 +238,24 @@ func (b *Builder) Extend(e *Extension) (newe *Edge, newv *Vertex, err error) {
 		targ = b.AddVertex(e.Target.Color)
 		newv = targ
 	}
+	if e.Time > 0 {
+		// insert the edge into the temporal order
+		for i := range b.E {
+			if b.E[i].Time >= e.Time {
+				b.E[i].Time++
+			}
+		}
+	}
 	newe = b.AddEdge(src, targ, e.Color)
+	newe.Time = e.Time
+	for i := range e.More {
+		// the new vertex has been added so these only connect existing
+		// vertices
+		_, _, err := b.Extend(&e.More[i])
+		if err != nil {
+			return nil, nil, err
+		}
+	}
 	return newe, newv, nil
 }
 
@@ -272,8 +337,11 @@ func (b *Builder) BuildFromPermutation(vord, eord []int) *SubGraph {
 		pat.E[j].Src = vord[b.E[i].Src]
 		pat.E[j].Targ = vord[b.E[i].Targ]
 		pat.E[j].Color = b.E[i].Color
+		pat.E[j].Time = b.E[i].Time
 		pat.Adj[pat.E[j].Src] = append(pat.Adj[pat.E[j].Src], j)
-		pat.Adj[pat.E[j].Targ] = append(pat.Adj[pat.E[j].Targ], j)
+		if pat.E[j].Targ != pat.E[j].Src {
+			pat.Adj[pat.E[j].Targ] = append(pat.Adj[pat.E[j].Targ], j)
+		}
 		pat.OutDeg[pat.E[j].Src]++
 		pat.InDeg[pat.E[j].Targ]++
 	}
@@ -281,6 +349,7 @@ func (b *Builder) BuildFromPermutation(vord, eord []int) *SubGraph {
 }
 
 func (b *Builder) CanonicalPermutation() (vord, eord []int) {
+	defer metrics.Canonicalize.Time()()
 	bMap := bliss.NewMap(len(b.V), len(b.E), b.V.Iterate(), b.E.Iterate())
 	vord, eord, _ = bMap.CanonicalPermutation()
 	return vord, eord
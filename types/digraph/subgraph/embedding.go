@@ -33,6 +33,9 @@ func (emb *Embedding) Builder() *EmbeddingBuilder {
 }
 
 func (sg *SubGraph) HasExtension(ext *Extension) bool {
+	if ext.Relabel {
+		return ext.Source.Idx < len(sg.V) && ext.Source.Color == sg.V[ext.Source.Idx].Color
+	}
 	if ext.Source.Idx >= len(sg.V) || ext.Source.Color != sg.V[ext.Source.Idx].Color {
 		return false
 	}
@@ -48,6 +51,8 @@ func (sg *SubGraph) HasExtension(ext *Extension) bool {
 	return false
 }
 
+// Exists checks the embedding is an (injective) embedding of its pattern
+// in G.
 func (emb *Embedding) Exists(G *digraph.Digraph) bool {
 	seen := make(map[int]bool, len(emb.Ids))
 	for _, id := range emb.Ids {
@@ -56,6 +61,13 @@ func (emb *Embedding) Exists(G *digraph.Digraph) bool {
 		}
 		seen[id] = true
 	}
+	return emb.ExistsHomomorphic(G)
+}
+
+// ExistsHomomorphic checks every edge of the pattern is mapped onto an edge
+// of G. Unlike Exists several vertices of the pattern may be mapped onto the
+// same vertex.
+func (emb *Embedding) ExistsHomomorphic(G *digraph.Digraph) bool {
 	for i := range emb.SG.E {
 		e := &emb.SG.E[i]
 		found := false
@@ -91,9 +103,11 @@ func (emb *Embedding) MarshalBinary() ([]byte, error) {
 }
 
 func (emb *Embedding) Serialize() []byte {
-	size := 8 + len(emb.SG.V)*8 + len(emb.SG.E)*12
+	timed := emb.SG.timed()
+	edgeSize := edgeBytes(timed)
+	size := 8 + len(emb.SG.V)*8 + len(emb.SG.E)*edgeSize
 	label := make([]byte, size)
-	binary.BigEndian.PutUint32(label[0:4], uint32(len(emb.SG.E)))
+	binary.BigEndian.PutUint32(label[0:4], edgeCount(len(emb.SG.E), timed))
 	binary.BigEndian.PutUint32(label[4:8], uint32(len(emb.SG.V)))
 	off := 8
 	for i := range emb.SG.V {
@@ -107,7 +121,7 @@ func (emb *Embedding) Serialize() []byte {
 	off += len(emb.SG.V) * 8
 	for i := range emb.SG.E {
 		edge := &emb.SG.E[i]
-		s := off + i*12
+		s := off + i*edgeSize
 		e := s + 4
 		binary.BigEndian.PutUint32(label[s:e], uint32(edge.Src))
 		s += 4
@@ -116,6 +130,11 @@ func (emb *Embedding) Serialize() []byte {
 		s += 4
 		e += 4
 		binary.BigEndian.PutUint32(label[s:e], uint32(edge.Color))
+		if timed {
+			s += 4
+			e += 4
+			binary.BigEndian.PutUint32(label[s:e], uint32(edge.Time))
+		}
 	}
 	return label
 }
@@ -127,10 +146,11 @@ func (emb *Embedding) UnmarshalBinary(bytes []byte) error {
 	if len(bytes) < 8 {
 		return errors.Errorf("bytes was too small %v < 8", len(bytes))
 	}
-	lenE := int(binary.BigEndian.Uint32(bytes[0:4]))
+	lenE, timed := splitEdgeCount(binary.BigEndian.Uint32(bytes[0:4]))
+	edgeSize := edgeBytes(timed)
 	lenV := int(binary.BigEndian.Uint32(bytes[4:8]))
 	off := 8
-	expected := 8 + lenV*8 + lenE*12
+	expected := 8 + lenV*8 + lenE*edgeSize
 	if len(bytes) < expected {
 		return errors.Errorf("bytes was too small %v < %v", len(bytes), expected)
 	}
@@ -154,7 +174,7 @@ func (emb *Embedding) UnmarshalBinary(bytes []byte) error {
 	}
 	off += lenV * 8
 	for i := 0; i < lenE; i++ {
-		s := off + i*12
+		s := off + i*edgeSize
 		e := s + 4
 		src := int(binary.BigEndian.Uint32(bytes[s:e]))
 		s += 4
@@ -163,11 +183,20 @@ func (emb *Embedding) UnmarshalBinary(bytes []byte) error {
 		s += 4
 		e += 4
 		color := int(binary.BigEndian.Uint32(bytes[s:e]))
+		time := 0
+		if timed {
+			s += 4
+			e += 4
+			time = int(binary.BigEndian.Uint32(bytes[s:e]))
+		}
 		sg.E[i].Src = src
 		sg.E[i].Targ = targ
 		sg.E[i].Color = color
+		sg.E[i].Time = time
 		sg.Adj[src] = append(sg.Adj[src], i)
-		sg.Adj[targ] = append(sg.Adj[targ], i)
+		if targ != src {
+			sg.Adj[targ] = append(sg.Adj[targ], i)
+		}
 	}
 	emb.SG = sg
 	emb.Ids = ids
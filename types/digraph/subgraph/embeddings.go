@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/rand"
 	"strings"
+	"time"
 )
 
 import (
@@ -16,6 +17,7 @@ import (
 )
 
 import (
+	"github.com/timtadh/regrax/metrics"
 	"github.com/timtadh/regrax/types/digraph/digraph"
 )
 
@@ -254,8 +256,19 @@ func (sg *SubGraph) IterEmbeddings(spMode EmbSearchStartPoint, indices *digraph.
 	//	errors.Logf("DEBUG", "prune points %v", set.SortedFromSet(prunePoints))
 	}
 	pruneLevel := len(chain) + 2
-
-	ei = func(stop bool) (*Embedding, EmbIterator) {
+	temporal := sg.temporalSearch(indices)
+
+	// the search is lazy so its time is summed over the calls and observed
+	// by the last one (when the search is exhausted or stopped early)
+	var spent time.Duration
+	ei = func(stop bool) (_ *Embedding, next EmbIterator) {
+		start := time.Now()
+		defer func() {
+			spent += time.Since(start)
+			if next == nil {
+				metrics.IterEmbeddings.Observe(spent.Seconds())
+			}
+		}()
 		for !stop && len(stack) > 0 {
 			var i entry
 			i, stack = pop(stack)
@@ -280,6 +293,10 @@ func (sg *SubGraph) IterEmbeddings(spMode EmbSearchStartPoint, indices *digraph.
 				}
 				continue
 			}
+			if temporal && i.eid > 0 && !sg.temporalEdges(indices, i.ids.list(len(sg.V)), chain[:i.eid]) {
+				// the edges matched so far already break the time order
+				continue
+			}
 			// otherwise success we have an embedding we haven't seen
 			if i.eid >= len(chain) {
 				// check that this is the subgraph we sought
@@ -291,7 +308,11 @@ func (sg *SubGraph) IterEmbeddings(spMode EmbSearchStartPoint, indices *digraph.
 					used[c.VrtEmb] = true
 				}
 				if false {
-					if !emb.Exists(indices.G) {
+					exists := emb.Exists
+					if indices.Homomorphic {
+						exists = emb.ExistsHomomorphic
+					}
+					if !exists(indices.G) {
 						errors.Logf("FOUND", "NOT EXISTS\n  builder %v\n    built %v\n  pattern %v", i.ids, emb, emb.SG)
 						panic("wat")
 					}
@@ -300,6 +321,7 @@ func (sg *SubGraph) IterEmbeddings(spMode EmbSearchStartPoint, indices *digraph.
 						panic("wat")
 					}
 				}
+				metrics.Embeddings.Inc()
 				return emb, ei
 			} else {
 				// ok extend the embedding
@@ -378,7 +400,7 @@ func argMax(length int, f func(int) int) (arg int) {
 
 func (sg *SubGraph) vertexFrequency(indices *digraph.Indices) func(int) int {
 	return func(idx int) int {
-		return indices.VertexColorFrequency(sg.V[idx].Color)
+		return indices.VertexColorFrequency(indices.Base(sg.V[idx].Color))
 	}
 }
 
@@ -400,8 +422,12 @@ func (sg *SubGraph) vertexExtensions(indices *digraph.Indices, overlap []map[int
 
 func (sg *SubGraph) startEmbeddings(indices *digraph.Indices, startIdx int) []*IdNode {
 	color := sg.V[startIdx].Color
-	embs := make([]*IdNode, 0, indices.VertexColorFrequency(color))
-	for _, gIdx := range indices.ColorIndex[color] {
+	base := indices.Base(color)
+	embs := make([]*IdNode, 0, indices.VertexColorFrequency(base))
+	for _, gIdx := range indices.ColorIndex[base] {
+		if !indices.HasLabels(gIdx, color) {
+			continue
+		}
 		embs = append(embs, &IdNode{VrtEmb:VrtEmb{Id: gIdx, Idx: startIdx}})
 	}
 	return embs
@@ -565,6 +591,15 @@ func (sg *SubGraph) extendEmbedding(indices *digraph.Indices, cur *IdNode, e *Ed
 			do(&IdNode{VrtEmb:VrtEmb{Id: newId, Idx: newIdx}, Prev: cur})
 		}
 	}
+	// under homomorphism several vertices of sg may map to the same vertex
+	// in the graph so neither injectivity nor the degrees are checked.
+	exclude := cur.hasId
+	if indices.Homomorphic {
+		exclude = nil
+	}
+	degreeOk := func(id, outDeg, inDeg int) bool {
+		return indices.Homomorphic || (outDeg <= indices.OutDegree(id) && inDeg <= indices.InDegree(id))
+	}
 	srcId, targId := cur.ids(e.Src, e.Targ)
 	if srcId == -1 && targId == -1 {
 		panic("src and targ == -1. Which means the edge chain was not connected.")
@@ -576,16 +611,16 @@ func (sg *SubGraph) extendEmbedding(indices *digraph.Indices, cur *IdNode, e *Ed
 	} else if srcId != -1 {
 		outDeg := sg.OutDeg[e.Targ]
 		inDeg := sg.InDeg[e.Targ]
-		indices.TargsFromSrc(srcId, e.Color, sg.V[e.Targ].Color, cur.hasId, func(targId int) {
-			if outDeg <= indices.OutDegree(targId) && inDeg <= indices.InDegree(targId) {
+		indices.TargsFromSrc(srcId, e.Color, sg.V[e.Targ].Color, exclude, func(targId int) {
+			if degreeOk(targId, outDeg, inDeg) {
 				doNew(e.Targ, targId)
 			}
 		})
 	} else if targId != -1 {
 		outDeg := sg.OutDeg[e.Src]
 		inDeg := sg.InDeg[e.Src]
-		indices.SrcsToTarg(targId, e.Color, sg.V[e.Src].Color, cur.hasId, func(srcId int) {
-			if outDeg <= indices.OutDegree(srcId) && inDeg <= indices.InDegree(srcId) {
+		indices.SrcsToTarg(targId, e.Color, sg.V[e.Src].Color, exclude, func(srcId int) {
+			if degreeOk(srcId, outDeg, inDeg) {
 				doNew(e.Src, srcId)
 			}
 		})
@@ -604,7 +639,10 @@ outer:
 				continue outer
 			}
 		}
-		for _, id := range indices.ColorIndex[sg.V[idx].Color] {
+		for _, id := range indices.ColorIndex[indices.Base(sg.V[idx].Color)] {
+			if !indices.HasLabels(id, sg.V[idx].Color) {
+				continue
+			}
 			if overlap == nil || len(overlap[idx]) == 0 || overlap[idx][id] {
 				sg.extendEmbedding(indices, &IdNode{VrtEmb:VrtEmb{Id: id, Idx: idx}}, e, overlap, func(_ *IdNode) {
 					total++
@@ -619,8 +657,8 @@ func (sg *SubGraph) vertexCard(indices *digraph.Indices, idx int) int {
 	card := 0
 	for _, eid := range sg.Adj[idx] {
 		e := &sg.E[eid]
-		s := sg.V[e.Src].Color
-		t := sg.V[e.Targ].Color
+		s := indices.Base(sg.V[e.Src].Color)
+		t := indices.Base(sg.V[e.Targ].Color)
 		card += indices.EdgeCounts[digraph.Colors{SrcColor: s, TargColor: t, EdgeColor: e.Color}]
 	}
 	return card
@@ -8,6 +8,17 @@ type Extension struct {
 	Source Vertex
 	Target Vertex
 	Color  int
+	// Time is the position the new edge takes in the temporal order of
+	// the pattern (0 when not mining temporal patterns).
+	Time int
+	// Relabel extensions add the label Color to the vertex Source.Idx
+	// (instead of adding an edge). Source.Color is the label set color the
+	// vertex ends up with.
+	Relabel bool
+	// More are the other edges between the new vertex and the pattern which
+	// are added along with this one. Induced mode adds a vertex with all
+	// of its edges at once.
+	More []Extension
 }
 
 func NewExt(src, targ Vertex, color int) *Extension {
@@ -33,6 +44,13 @@ func (e *Extension) Translate(orgLen int, vord []int) *Extension {
 	if targIdx < len(vord) {
 		targIdx = vord[targIdx]
 	}
+	var more []Extension
+	if len(e.More) > 0 {
+		more = make([]Extension, 0, len(e.More))
+		for i := range e.More {
+			more = append(more, *e.More[i].Translate(orgLen, vord))
+		}
+	}
 	return &Extension{
 		Source: Vertex{
 			Idx:   srcIdx,
@@ -42,7 +60,10 @@ func (e *Extension) Translate(orgLen int, vord []int) *Extension {
 			Idx:   targIdx,
 			Color: e.Target.Color,
 		},
-		Color: e.Color,
+		Color:   e.Color,
+		Time:    e.Time,
+		Relabel: e.Relabel,
+		More:    more,
 	}
 }
 
@@ -53,11 +74,26 @@ func (e *Extension) Equals(o types.Equatable) bool {
 			e.Source.Color == x.Source.Color &&
 			e.Target.Idx == x.Target.Idx &&
 			e.Target.Color == x.Target.Color &&
-			e.Color == x.Color
+			e.Color == x.Color &&
+			e.Time == x.Time &&
+			e.Relabel == x.Relabel &&
+			e.equalMore(x)
 	}
 	return false
 }
 
+func (e *Extension) equalMore(x *Extension) bool {
+	if len(e.More) != len(x.More) {
+		return false
+	}
+	for i := range e.More {
+		if !e.More[i].Equals(&x.More[i]) {
+			return false
+		}
+	}
+	return true
+}
+
 func (e *Extension) Less(o types.Sortable) bool {
 	switch x := o.(type) {
 	case *Extension:
@@ -83,16 +119,48 @@ func (e *Extension) Less(o types.Sortable) bool {
 		}
 		if e.Color < x.Color {
 			return true
+		} else if e.Color > x.Color {
+			return false
+		}
+		if e.Time < x.Time {
+			return true
+		} else if e.Time > x.Time {
+			return false
+		}
+		if e.Relabel != x.Relabel {
+			return !e.Relabel && x.Relabel
+		}
+		if len(e.More) != len(x.More) {
+			return len(e.More) < len(x.More)
+		}
+		for i := range e.More {
+			if e.More[i].Less(&x.More[i]) {
+				return true
+			} else if x.More[i].Less(&e.More[i]) {
+				return false
+			}
 		}
-		return false
 	}
 	return false
 }
 
 func (e *Extension) Hash() int {
-	return e.Source.Idx +
+	h := e.Source.Idx +
 		2*e.Source.Color +
 		3*e.Target.Idx +
 		5*e.Target.Color +
-		7*e.Color
+		7*e.Color +
+		11*e.Time +
+		13*relabelHash(e.Relabel)
+	for i := range e.More {
+		h = 17*h + e.More[i].Hash()
+	}
+	return h
+}
+
+func relabelHash(relabel bool) int {
+	if relabel {
+		return 1
+	}
+	return 0
 }
@@ -37,6 +37,9 @@ func (a *SubGraph) equals(b *SubGraph) bool {
 		if a.E[i].Color != b.E[i].Color {
 			return false
 		}
+		if a.E[i].Time != b.E[i].Time {
+			return false
+		}
 	}
 	return true
 }
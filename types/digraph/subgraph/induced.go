@@ -0,0 +1,67 @@
+package subgraph
+
+import ()
+
+import (
+	"github.com/timtadh/regrax/types/digraph/digraph"
+)
+
+// inducedAt checks that every edge of G between the vertices in ids (where
+// ids[idx] is the vertex in G of vertex idx in sg) is the image of an edge
+// in sg. Such an embedding is an induced embedding: no extra edges exist
+// between the mapped vertices.
+func (sg *SubGraph) inducedAt(G *digraph.Digraph, ids []int) bool {
+	idxs := make(map[int]int, len(ids))
+	for idx, id := range ids {
+		idxs[id] = idx
+	}
+	type edge struct {
+		src, targ, color int
+	}
+	// parallel edges of the same color in G are one edge for matching
+	pattern := make(map[edge]bool, len(sg.E))
+	for i := range sg.E {
+		e := &sg.E[i]
+		pattern[edge{e.Src, e.Targ, e.Color}] = true
+	}
+	for idx, id := range ids {
+		for _, x := range G.Kids[id] {
+			ke := &G.E[x]
+			targ, has := idxs[ke.Targ]
+			if !has {
+				continue
+			}
+			if !pattern[edge{idx, targ, ke.Color}] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Induced returns true if the embedding is an induced embedding in G.
+func (emb *Embedding) Induced(G *digraph.Digraph) bool {
+	return emb.SG.inducedAt(G, emb.Ids)
+}
+
+// ExistsInduced is Exists with induced semantics.
+func (emb *Embedding) ExistsInduced(G *digraph.Digraph) bool {
+	return emb.Exists(G) && emb.Induced(G)
+}
+
+// FilterInduced drops the embeddings from the iterator which are not
+// induced embeddings in G.
+func FilterInduced(it EmbIterator, G *digraph.Digraph) (ei EmbIterator) {
+	ei = func(stop bool) (emb *Embedding, _ EmbIterator) {
+		if it == nil {
+			return nil, nil
+		}
+		for emb, it = it(stop); it != nil; emb, it = it(stop) {
+			if emb.Induced(G) {
+				return emb, ei
+			}
+		}
+		return nil, nil
+	}
+	return ei
+}
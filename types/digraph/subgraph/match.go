@@ -9,10 +9,13 @@ import (
 	"github.com/timtadh/regrax/types/digraph/digraph"
 )
 
-func (sg *SubGraph) EstimateMatch(indices *digraph.Indices) (match float64, csg *SubGraph, err error) {
+// EstimateMatch finds the largest connected subgraph of sg (found by
+// removing edges) which is embedded in the graph. If induced is set only
+// induced embeddings are considered.
+func (sg *SubGraph) EstimateMatch(indices *digraph.Indices, induced bool) (match float64, csg *SubGraph, err error) {
 	csg = sg
 	for len(csg.E) >= 0 {
-		found, chain, maxEid, _ := csg.Embedded(indices)
+		found, chain, maxEid, _ := csg.Embedded(indices, induced)
 		if false {
 			errors.Logf("INFO", "found: %v %v %v %v", found, chain, maxEid, nil)
 		}
@@ -46,7 +49,7 @@ func (sg *SubGraph) EstimateMatch(indices *digraph.Indices) (match float64, csg
 	return 0, EmptySubGraph(), nil
 }
 
-func (sg *SubGraph) Embedded(indices *digraph.Indices) (found bool, edgeChain []int, largestEid int, longest *IdNode) {
+func (sg *SubGraph) Embedded(indices *digraph.Indices, induced bool) (found bool, edgeChain []int, largestEid int, longest *IdNode) {
 	type entry struct {
 		ids *IdNode
 		eid int
@@ -69,6 +72,11 @@ func (sg *SubGraph) Embedded(indices *digraph.Indices) (found bool, edgeChain []
 		for len(stack) > 0 {
 			var i entry
 			i, stack = pop(stack)
+			if induced && i.eid >= len(chain) && !sg.inducedAt(indices.G, i.ids.list(len(sg.V))) {
+				// every edge matched but there are extra edges between the
+				// matched vertices
+				continue
+			}
 			if i.eid > largestEid {
 				edgeChain = chain
 				longest = i.ids
@@ -98,7 +106,7 @@ func (sg *SubGraph) VisualizeEmbedding(indices *digraph.Indices, labels *digraph
 			}
 			panic(exc.Errorf("unreachable").Exception())
 		}
-		found, edgeChain, eid, ids := sg.Embedded(indices)
+		found, edgeChain, eid, ids := sg.Embedded(indices, false)
 		if !found && len(sg.V) > 0 {
 			exc.Throwf("%v not found in graph!", sg)
 		}
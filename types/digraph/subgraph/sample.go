@@ -0,0 +1,51 @@
+package subgraph
+
+import (
+	"math/rand"
+)
+
+import (
+	"github.com/timtadh/regrax/types/digraph/digraph"
+)
+
+// SearchAt returns a function which looks for a single embedding of sg that
+// maps the vertex idx of sg to the vertex id of the graph. The partial
+// embeddings are extended in a random order so repeated searches give
+// different embeddings. A search gives up after extending budget partial
+// embeddings, in which case exhausted is false and emb is nil.
+func (sg *SubGraph) SearchAt(indices *digraph.Indices, idx, budget int) func(id int) (emb *Embedding, exhausted bool) {
+	chain := sg.edgeChain(indices, nil, idx)
+	temporal := sg.temporalSearch(indices)
+	type entry struct {
+		ids *IdNode
+		eid int
+	}
+	return func(id int) (*Embedding, bool) {
+		if indices.G.V[id].Color != indices.Base(sg.V[idx].Color) || !indices.HasLabels(id, sg.V[idx].Color) {
+			return nil, true
+		}
+		stack := make([]entry, 0, len(chain)*2)
+		stack = append(stack, entry{&IdNode{VrtEmb: VrtEmb{Id: id, Idx: idx}}, 0})
+		for steps := 0; len(stack) > 0; steps++ {
+			if steps >= budget {
+				return nil, false
+			}
+			i := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if temporal && i.eid > 0 && !sg.temporalEdges(indices, i.ids.list(len(sg.V)), chain[:i.eid]) {
+				continue
+			}
+			if i.eid >= len(chain) {
+				return &Embedding{SG: sg, Ids: i.ids.list(len(sg.V))}, true
+			}
+			exts := make([]*IdNode, 0, 10)
+			sg.extendEmbedding(indices, i.ids, &sg.E[chain[i.eid]], nil, func(ext *IdNode) {
+				exts = append(exts, ext)
+			})
+			for _, j := range rand.Perm(len(exts)) {
+				stack = append(stack, entry{exts[j], i.eid + 1})
+			}
+		}
+		return nil, true
+	}
+}
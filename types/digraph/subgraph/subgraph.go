@@ -40,6 +40,9 @@ type Vertex struct {
 
 type Edge struct {
 	Src, Targ, Color int
+	// Time is the position (from 1) of the edge in the temporal order of
+	// the pattern. It is 0 unless mining in the temporal mode.
+	Time int
 }
 
 func EmptySubGraph() *SubGraph {
@@ -72,6 +75,10 @@ func (E Edges) Iterate() (ei bliss.EdgeIterator) {
 		src = E[i].Src
 		targ = E[i].Targ
 		color = E[i].Color
+		if E[i].Time > 0 {
+			// the temporal order is part of the identity of the pattern
+			color = color*(len(E)+1) + E[i].Time
+		}
 		i++
 		return src, targ, color, ei
 	}
@@ -90,6 +97,7 @@ func LoadSubGraph(label []byte) (*SubGraph, error) {
 func ParsePretty(str string, labels *digraph.Labels) (*SubGraph, error) {
 	size := regexp.MustCompile(`^\{([0-9]+):([0-9]+)\}`)
 	edge := regexp.MustCompile(`^\[([0-9]+)->([0-9]+):`)
+	time := regexp.MustCompile(`^@([0-9]+)`)
 	matches := size.FindStringSubmatch(str)
 	E, err := strconv.ParseInt(matches[1], 10, 64)
 	if err != nil {
@@ -161,11 +169,27 @@ func ParsePretty(str string, labels *digraph.Labels) (*SubGraph, error) {
 		edges[i].Src = int(src)
 		edges[i].Targ = int(targ)
 		edges[i].Color = labels.Color(label)
+		if matches := time.FindStringSubmatch(str[idx:]); matches != nil {
+			t, err := strconv.ParseInt(matches[1], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			edges[i].Time = int(t)
+			idx += len(matches[0])
+		}
 	}
 	sg := &Builder{V: vertices, E: edges}
 	return sg.Build(), nil
 }
 
+// timeSuffix shows the position of the edge in the temporal order.
+func (e *Edge) timeSuffix() string {
+	if e.Time <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("@%d", e.Time)
+}
+
 func (sg *SubGraph) Builder() *Builder {
 	return Build(len(sg.V), len(sg.E)).From(sg)
 }
@@ -185,10 +209,11 @@ func (sg *SubGraph) UnmarshalBinary(bytes []byte) error {
 	if len(bytes) < 8 {
 		return errors.Errorf("bytes was too small %v < 8", len(bytes))
 	}
-	lenE := int(binary.BigEndian.Uint32(bytes[0:4]))
+	lenE, timed := splitEdgeCount(binary.BigEndian.Uint32(bytes[0:4]))
+	edgeSize := edgeBytes(timed)
 	lenV := int(binary.BigEndian.Uint32(bytes[4:8]))
 	off := 8
-	expected := 8 + lenV*4 + lenE*12
+	expected := 8 + lenV*4 + lenE*edgeSize
 	if len(bytes) < expected {
 		return errors.Errorf("bytes was too small %v < %v", len(bytes), expected)
 	}
@@ -205,7 +230,7 @@ func (sg *SubGraph) UnmarshalBinary(bytes []byte) error {
 	}
 	off += lenV * 4
 	for i := 0; i < lenE; i++ {
-		s := off + i*12
+		s := off + i*edgeSize
 		e := s + 4
 		src := int(binary.BigEndian.Uint32(bytes[s:e]))
 		s += 4
@@ -214,11 +239,20 @@ func (sg *SubGraph) UnmarshalBinary(bytes []byte) error {
 		s += 4
 		e += 4
 		color := int(binary.BigEndian.Uint32(bytes[s:e]))
+		time := 0
+		if timed {
+			s += 4
+			e += 4
+			time = int(binary.BigEndian.Uint32(bytes[s:e]))
+		}
 		sg.E[i].Src = src
 		sg.E[i].Targ = targ
 		sg.E[i].Color = color
+		sg.E[i].Time = time
 		sg.Adj[src] = append(sg.Adj[src], i)
-		sg.Adj[targ] = append(sg.Adj[targ], i)
+		if targ != src {
+			sg.Adj[targ] = append(sg.Adj[targ], i)
+		}
 	}
 	sg.labelCache = bytes
 	return nil
@@ -228,9 +262,11 @@ func (sg *SubGraph) Label() []byte {
 	if sg.labelCache != nil {
 		return sg.labelCache
 	}
-	size := 8 + len(sg.V)*4 + len(sg.E)*12
+	timed := sg.timed()
+	edgeSize := edgeBytes(timed)
+	size := 8 + len(sg.V)*4 + len(sg.E)*edgeSize
 	label := make([]byte, size)
-	binary.BigEndian.PutUint32(label[0:4], uint32(len(sg.E)))
+	binary.BigEndian.PutUint32(label[0:4], edgeCount(len(sg.E), timed))
 	binary.BigEndian.PutUint32(label[4:8], uint32(len(sg.V)))
 	off := 8
 	for i, v := range sg.V {
@@ -240,7 +276,7 @@ func (sg *SubGraph) Label() []byte {
 	}
 	off += len(sg.V) * 4
 	for i, edge := range sg.E {
-		s := off + i*12
+		s := off + i*edgeSize
 		e := s + 4
 		binary.BigEndian.PutUint32(label[s:e], uint32(edge.Src))
 		s += 4
@@ -249,6 +285,11 @@ func (sg *SubGraph) Label() []byte {
 		s += 4
 		e += 4
 		binary.BigEndian.PutUint32(label[s:e], uint32(edge.Color))
+		if timed {
+			s += 4
+			e += 4
+			binary.BigEndian.PutUint32(label[s:e], uint32(edge.Time))
+		}
 	}
 	sg.labelCache = label
 	return label
@@ -265,10 +306,11 @@ func (sg *SubGraph) String() string {
 	}
 	for _, e := range sg.E {
 		E = append(E, fmt.Sprintf(
-			"[%v->%v:%v]",
+			"[%v->%v:%v]%v",
 			e.Src,
 			e.Targ,
 			e.Color,
+			e.timeSuffix(),
 		))
 	}
 	return fmt.Sprintf("{%v:%v}%v%v", len(sg.E), len(sg.V), strings.Join(V, ""), strings.Join(E, ""))
@@ -285,10 +327,11 @@ func (sg *SubGraph) Pretty(labels *digraph.Labels) string {
 	}
 	for _, e := range sg.E {
 		E = append(E, fmt.Sprintf(
-			"[%v->%v:%v]",
+			"[%v->%v:%v]%v",
 			e.Src,
 			e.Targ,
 			labels.Label(e.Color),
+			e.timeSuffix(),
 		))
 	}
 	return fmt.Sprintf("{%v:%v}%v%v", len(sg.E), len(sg.V), strings.Join(V, ""), strings.Join(E, ""))
@@ -315,10 +358,11 @@ func (sg *SubGraph) Dotty(labels *digraph.Labels, highlightVertices, highlightEd
 			highlight = " color=red"
 		}
 		E = append(E, fmt.Sprintf(
-			"n%v->n%v [label=\"%v\"%v]",
+			"n%v->n%v [label=\"%v%v\"%v]",
 			e.Src,
 			e.Targ,
 			labels.Label(e.Color),
+			e.timeSuffix(),
 			highlight,
 		))
 	}
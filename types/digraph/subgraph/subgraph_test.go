@@ -9,7 +9,9 @@ import (
 	"github.com/timtadh/goiso"
 )
 
-import ()
+import (
+	"github.com/timtadh/regrax/types/digraph/digraph"
+)
 
 func graph(t *testing.T) (*goiso.Graph, *goiso.SubGraph, *SubGraph, *Indices) {
 	Graph := goiso.NewGraph(10, 10)
@@ -301,3 +303,133 @@ func TestBuilderConnected(t *testing.T) {
 	_ = b.AddVertex(2)
 	x.False(b.Connected())
 }
+
+// loopGraph has two copies of a vertex (color 0) with a self-loop (color 2)
+// and two differently colored parallel edges (colors 3 and 4) to a vertex
+// (color 1). The second copy repeats the color 3 edge.
+func loopGraph() *digraph.Indices {
+	b := digraph.Build(4, 7)
+	for i := 0; i < 2; i++ {
+		x := b.AddVertex(0)
+		y := b.AddVertex(1)
+		b.AddEdge(x, x, 2)
+		b.AddEdge(x, y, 3)
+		b.AddEdge(x, y, 4)
+		if i == 1 {
+			b.AddEdge(x, y, 3)
+		}
+	}
+	return digraph.NewIndices(b, 1)
+}
+
+func TestSelfLoopAdjacency(t *testing.T) {
+	x := assert.New(t)
+	indices := loopGraph()
+	G := indices.G
+	x.Equal([]int{0, 1, 2}, G.Adj[0])
+	x.Equal([]int{0, 1, 2}, G.Kids[0])
+	x.Equal([]int{0}, G.Parents[0])
+	x.Equal([]int{1, 2}, G.Parents[1])
+	x.Equal([]int{3}, indices.SrcIndex[digraph.IdColorColor{Id: 2, EdgeColor: 3, VertexColor: 1}])
+	x.Equal(2, indices.EdgeCounts[digraph.Colors{SrcColor: 0, TargColor: 1, EdgeColor: 3}])
+	sg := Build(1, 1).Ctx(func(b *Builder) {
+		v := b.AddVertex(0)
+		b.AddEdge(v, v, 2)
+	}).Build()
+	x.Equal([][]int{{0}}, sg.Adj)
+	x.Equal([]int{1}, sg.InDeg)
+	x.Equal([]int{1}, sg.OutDeg)
+}
+
+func TestSelfLoopParallelCanonical(t *testing.T) {
+	x := assert.New(t)
+	a := Build(2, 3).Ctx(func(b *Builder) {
+		u := b.AddVertex(0)
+		v := b.AddVertex(1)
+		b.AddEdge(u, u, 2)
+		b.AddEdge(u, v, 3)
+		b.AddEdge(u, v, 4)
+	}).Build()
+	b := Build(2, 3).Ctx(func(b *Builder) {
+		v := b.AddVertex(1)
+		u := b.AddVertex(0)
+		b.AddEdge(u, v, 4)
+		b.AddEdge(u, v, 3)
+		b.AddEdge(u, u, 2)
+	}).Build()
+	x.Equal(a.Label(), b.Label())
+	// the loop on the other vertex is a different pattern
+	c := Build(2, 3).Ctx(func(b *Builder) {
+		u := b.AddVertex(0)
+		v := b.AddVertex(1)
+		b.AddEdge(v, v, 2)
+		b.AddEdge(u, v, 3)
+		b.AddEdge(u, v, 4)
+	}).Build()
+	x.NotEqual(a.Label(), c.Label())
+	// as is a single edge with both colors
+	d := Build(2, 2).Ctx(func(b *Builder) {
+		u := b.AddVertex(0)
+		v := b.AddVertex(1)
+		b.AddEdge(u, u, 2)
+		b.AddEdge(u, v, 3)
+	}).Build()
+	x.NotEqual(a.Label(), d.Label())
+	x.Equal(a.String(), Build(2, 3).From(a).Build().String())
+}
+
+func TestSelfLoopParallelEmbeddings(t *testing.T) {
+	x := assert.New(t)
+	indices := loopGraph()
+	sg := Build(2, 3).Ctx(func(b *Builder) {
+		u := b.AddVertex(0)
+		v := b.AddVertex(1)
+		b.AddEdge(u, u, 2)
+		b.AddEdge(u, v, 3)
+		b.AddEdge(u, v, 4)
+	}).Build()
+	embs, err := sg.Embeddings(indices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x.Equal(2, len(embs))
+	for _, emb := range embs {
+		x.True(emb.Induced(indices.G))
+	}
+	// without the loop the embeddings are not induced
+	noLoop := Build(2, 2).Ctx(func(b *Builder) {
+		u := b.AddVertex(0)
+		v := b.AddVertex(1)
+		b.AddEdge(u, v, 3)
+		b.AddEdge(u, v, 4)
+	}).Build()
+	embs, err = noLoop.Embeddings(indices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x.Equal(2, len(embs))
+	for _, emb := range embs {
+		x.False(emb.Induced(indices.G))
+	}
+}
+
+func TestSelfLoopRemoveEdge(t *testing.T) {
+	x := assert.New(t)
+	b := Build(2, 3)
+	u := b.AddVertex(0)
+	v := b.AddVertex(1)
+	b.AddEdge(u, v, 3)
+	b.AddEdge(u, u, 2)
+	b.AddEdge(u, v, 4)
+	if err := b.RemoveEdge(1); err != nil {
+		t.Fatal(err)
+	}
+	x.Equal(2, len(b.V))
+	x.Equal(2, len(b.E))
+	x.True(b.Connected())
+	if err := b.RemoveEdge(1); err != nil {
+		t.Fatal(err)
+	}
+	x.Equal(2, len(b.V))
+	x.Equal(1, len(b.E))
+}
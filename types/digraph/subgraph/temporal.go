@@ -0,0 +1,185 @@
+package subgraph
+
+import (
+	"sort"
+)
+
+import (
+	"github.com/timtadh/regrax/types/digraph/digraph"
+)
+
+type int64s []int64
+
+func (s int64s) Len() int           { return len(s) }
+func (s int64s) Less(i, j int) bool { return s[i] < s[j] }
+func (s int64s) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// Temporal is true if the edges of sg have a temporal order.
+func (sg *SubGraph) Temporal() bool {
+	for i := range sg.E {
+		if sg.E[i].Time <= 0 {
+			return false
+		}
+	}
+	return len(sg.E) > 0
+}
+
+// timedFlag is set in the edge count of the labels of patterns with a
+// temporal order. Only their edges carry the (4 byte) time so the labels of
+// other patterns keep their 12 byte edges.
+const timedFlag = 1 << 31
+
+// timed is true if some edge of sg has a time.
+func (sg *SubGraph) timed() bool {
+	for i := range sg.E {
+		if sg.E[i].Time > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// edgeBytes is the size of an edge in a label.
+func edgeBytes(timed bool) int {
+	if timed {
+		return 16
+	}
+	return 12
+}
+
+func edgeCount(lenE int, timed bool) uint32 {
+	if timed {
+		return uint32(lenE) | timedFlag
+	}
+	return uint32(lenE)
+}
+
+func splitEdgeCount(count uint32) (lenE int, timed bool) {
+	return int(count &^ timedFlag), count&timedFlag != 0
+}
+
+// edgeTimes returns the sorted timestamps of the edges of G from src to targ
+// with the given color.
+func edgeTimes(indices *digraph.Indices, src, targ, color int) []int64 {
+	times := make(int64s, 0, 1)
+	for _, e := range indices.G.Kids[src] {
+		ke := &indices.G.E[e]
+		if ke.Targ == targ && ke.Color == color {
+			times = append(times, indices.EdgeTime[e])
+		}
+	}
+	sort.Sort(times)
+	return times
+}
+
+// orderedTimes checks that a time can be chosen from each of the (sorted)
+// lists such that the times strictly increase and, if there is a time
+// window, the last one happens at most the window after the first. For each
+// start time the earliest possible next time is always the best choice so
+// the check is greedy.
+func orderedTimes(indices *digraph.Indices, times [][]int64) bool {
+	if len(times) == 0 {
+		return true
+	}
+outer:
+	for _, start := range times[0] {
+		prev := start
+		for _, t := range times[1:] {
+			j := sort.Search(len(t), func(j int) bool { return t[j] > prev })
+			if j >= len(t) {
+				return false
+			}
+			prev = t[j]
+			if indices.TimeWindow > 0 && prev-start > indices.TimeWindow {
+				continue outer
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// orderedEdgeTimes gives the timestamps of the edges of G each of the edges
+// (indices into sg.E) may be mapped to, in the temporal order of sg. It is
+// false if an edge has no edge of G to map to.
+func (sg *SubGraph) orderedEdgeTimes(indices *digraph.Indices, ids []int, edges []int) ([][]int64, bool) {
+	// the times are the positions 1..len(sg.E)
+	at := make([]int, len(sg.E)+1)
+	for _, x := range edges {
+		at[sg.E[x].Time] = x + 1
+	}
+	times := make([][]int64, 0, len(edges))
+	for _, x := range at {
+		if x == 0 {
+			continue
+		}
+		e := &sg.E[x-1]
+		t := edgeTimes(indices, ids[e.Src], ids[e.Targ], e.Color)
+		if len(t) == 0 {
+			return nil, false
+		}
+		times = append(times, t)
+	}
+	return times, true
+}
+
+// temporalEdges checks that the edges (indices into sg.E) can be mapped to
+// edges of G (where ids[idx] is the vertex in G of vertex idx in sg) whose
+// timestamps respect the temporal order of sg. Every subset of the edges of
+// an embedding respecting time does too so partial embeddings are pruned
+// with it.
+func (sg *SubGraph) temporalEdges(indices *digraph.Indices, ids []int, edges []int) bool {
+	times, ok := sg.orderedEdgeTimes(indices, ids, edges)
+	return ok && orderedTimes(indices, times)
+}
+
+// temporalAt checks every edge of sg (see temporalEdges).
+func (sg *SubGraph) temporalAt(indices *digraph.Indices, ids []int) bool {
+	if !sg.Temporal() {
+		return true
+	}
+	edges := make([]int, len(sg.E))
+	for i := range edges {
+		edges[i] = i
+	}
+	return sg.temporalEdges(indices, ids, edges)
+}
+
+// temporalSearch is true if the embedding search of sg checks time.
+func (sg *SubGraph) temporalSearch(indices *digraph.Indices) bool {
+	return indices.EdgeTime != nil && sg.Temporal()
+}
+
+// TimedExtensions calls do with the extension placed at each position of the
+// temporal order of the pattern (before, between and after its edges) at
+// which the embedding, extended by the edges of G from src to targ, still
+// respects time.
+func (emb *Embedding) TimedExtensions(indices *digraph.Indices, ext *Extension, src, targ int, do func(*Extension)) {
+	sg := emb.SG
+	edges := make([]int, len(sg.E))
+	for i := range edges {
+		edges[i] = i
+	}
+	times, ok := sg.orderedEdgeTimes(indices, emb.Ids, edges)
+	added := edgeTimes(indices, src, targ, ext.Color)
+	if !ok || len(added) == 0 {
+		return
+	}
+	for t := 1; t <= len(sg.E)+1; t++ {
+		with := make([][]int64, 0, len(times)+1)
+		with = append(with, times[:t-1]...)
+		with = append(with, added)
+		with = append(with, times[t-1:]...)
+		if orderedTimes(indices, with) {
+			x := *ext
+			x.Time = t
+			do(&x)
+		}
+	}
+}
+
+// RespectsTime returns true if the embedding respects the temporal order of
+// its pattern (see temporalAt).
+func (emb *Embedding) RespectsTime(indices *digraph.Indices) bool {
+	return emb.SG.temporalAt(indices, emb.Ids)
+}
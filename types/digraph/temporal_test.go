@@ -0,0 +1,147 @@
+package digraph
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/types/digraph/digraph"
+	"github.com/timtadh/regrax/types/digraph/subgraph"
+)
+
+// temporalDigraph has three paths a -> b -> c. The edges of the first two
+// happen in the order of the path (at the given times), the edges of the
+// last one in the reverse order.
+func temporalDigraph(t testing.TB, window int64, times ...int64) *Digraph {
+	labels := digraph.NewLabels()
+	b := digraph.Build(9, 6)
+	dt, err := NewDigraph(&config.Config{Support: 2}, &Config{
+		MaxEdges:            2,
+		Mode:                MNI | Temporal | ExtFromEmb | Caching,
+		EmbSearchStartPoint: subgraph.RandomStart,
+		TimeWindow:          window,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	times = append(times, 5, 3)
+	for c := 0; c < 3; c++ {
+		x := b.AddVertex(labels.Color("a"))
+		y := b.AddVertex(labels.Color("b"))
+		z := b.AddVertex(labels.Color("c"))
+		b.AddEdge(x, y, labels.Color("e"))
+		b.AddEdge(y, z, labels.Color("e"))
+	}
+	dt.EdgeTime = times
+	err = dt.Init(b, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dt
+}
+
+// paths gives the patterns with two edges.
+func paths(t testing.TB, dt *Digraph) []*EmbListNode {
+	found := make([]*EmbListNode, 0, 1)
+	for _, n := range patternNodes(t, dt) {
+		if len(n.Pat.E) == 2 {
+			found = append(found, n)
+		}
+	}
+	return found
+}
+
+func TestTemporalPatterns(t *testing.T) {
+	x := assert.New(t)
+	dt := temporalDigraph(t, 0, 1, 2, 1, 10)
+	defer dt.Close()
+	found := paths(t, dt)
+	// only the path in time order is frequent
+	if !x.Equal(1, len(found)) {
+		return
+	}
+	n := found[0]
+	x.Equal(2, n.Support())
+	for _, e := range n.Pat.E {
+		if n.Pat.V[e.Src].Color == dt.Labels.Color("a") {
+			x.Equal(1, e.Time)
+		} else {
+			x.Equal(2, e.Time)
+		}
+	}
+	embs, err := n.Embeddings()
+	x.Nil(err)
+	for _, emb := range embs {
+		x.True(emb.RespectsTime(dt.Indices))
+	}
+}
+
+func TestTemporalWindow(t *testing.T) {
+	x := assert.New(t)
+	// the second path takes longer than the window
+	dt := temporalDigraph(t, 5, 1, 2, 1, 10)
+	defer dt.Close()
+	x.Equal(0, len(paths(t, dt)))
+}
+
+func TestTimedExtensions(t *testing.T) {
+	x := assert.New(t)
+	dt := temporalDigraph(t, 0, 1, 2, 1, 10)
+	defer dt.Close()
+	n := edgeNodes(t, dt)["{1:2}(a)(b)[0->1:e]@1"]
+	if !x.NotNil(n, "%v", edgeNodes(t, dt)) {
+		return
+	}
+	embs, err := n.Embeddings()
+	x.Nil(err)
+	for _, emb := range embs {
+		// the b -> c edge of the same path
+		b := emb.Ids[1]
+		if n.Pat.V[1].Color != dt.Labels.Color("b") {
+			b = emb.Ids[0]
+		}
+		bIdx := 0
+		for idx, id := range emb.Ids {
+			if id == b {
+				bIdx = idx
+			}
+		}
+		e := &dt.G.E[dt.G.Kids[b][0]]
+		ext := subgraph.NewExt(
+			subgraph.Vertex{Idx: bIdx, Color: n.Pat.V[bIdx].Color},
+			subgraph.Vertex{Idx: 2, Color: dt.Labels.Color("c")},
+			e.Color)
+		positions := make([]int, 0, 2)
+		emb.TimedExtensions(dt.Indices, ext, e.Src, e.Targ, func(x *subgraph.Extension) {
+			positions = append(positions, x.Time)
+		})
+		if dt.Indices.EdgeTime[dt.G.Kids[b][0]] == 3 {
+			// the last path: b -> c happens before a -> b
+			x.Equal([]int{1}, positions)
+		} else {
+			x.Equal([]int{2}, positions)
+		}
+	}
+}
+
+func TestTemporalLabels(t *testing.T) {
+	x := assert.New(t)
+	b := subgraph.Build(2, 1)
+	b.AddEdge(b.AddVertex(0), b.AddVertex(1), 2)
+	plain := b.Build()
+	// patterns without a temporal order keep 12 byte edges
+	x.Equal(8+2*4+12, len(plain.Label()))
+	b.E[0].Time = 1
+	timed := b.Build()
+	x.Equal(8+2*4+16, len(timed.Label()))
+	for _, sg := range []*subgraph.SubGraph{plain, timed} {
+		loaded, err := subgraph.LoadSubGraph(sg.Label())
+		x.Nil(err)
+		x.Equal(sg.E, loaded.E)
+		emb := &subgraph.Embedding{SG: sg, Ids: []int{4, 5}}
+		var loadedEmb subgraph.Embedding
+		x.Nil(loadedEmb.UnmarshalBinary(emb.Serialize()))
+		x.Equal(emb.Ids, loadedEmb.Ids)
+		x.Equal(sg.E, loadedEmb.SG.E)
+	}
+}
@@ -0,0 +1,139 @@
+package digraph
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/types/digraph/digraph"
+	"github.com/timtadh/regrax/types/digraph/subgraph"
+)
+
+// patternSupports explores the whole lattice from the root and returns the
+// support of every pattern (by its pretty label).
+func patternSupports(t testing.TB, dt *Digraph) map[string]int {
+	supports := make(map[string]int)
+	queue := []Node{RootEmbListNode(dt)}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		kids, err := n.Children()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, k := range kids {
+			kid := k.(*EmbListNode)
+			label := kid.Pat.Pretty(dt.Labels)
+			if _, has := supports[label]; has {
+				continue
+			}
+			supports[label] = kid.Support()
+			queue = append(queue, kid)
+		}
+	}
+	return supports
+}
+
+// patternNodes explores the whole lattice from the root and returns every
+// pattern node by its pretty label.
+func patternNodes(t testing.TB, dt *Digraph) map[string]*EmbListNode {
+	nodes := make(map[string]*EmbListNode)
+	queue := []Node{RootEmbListNode(dt)}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		kids, err := n.Children()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, k := range kids {
+			kid := k.(*EmbListNode)
+			label := kid.Pat.Pretty(dt.Labels)
+			if _, has := nodes[label]; has {
+				continue
+			}
+			nodes[label] = kid
+			queue = append(queue, kid)
+		}
+	}
+	return nodes
+}
+
+// txDigraph has three transactions: the first has two a -> b edges and
+// three c vertices, the second one a -> b edge and the third an a vertex.
+func txDigraph(t testing.TB, mode Mode) *Digraph {
+	labels := digraph.NewLabels()
+	b := digraph.Build(10, 3)
+	tx := make([]int, 0, 10)
+	edge := func(n int) {
+		x := b.AddVertex(labels.Color("a"))
+		y := b.AddVertex(labels.Color("b"))
+		b.AddEdge(x, y, labels.Color("e"))
+		tx = append(tx, n, n)
+	}
+	edge(0)
+	edge(0)
+	for i := 0; i < 3; i++ {
+		b.AddVertex(labels.Color("c"))
+		tx = append(tx, 0)
+	}
+	edge(1)
+	b.AddVertex(labels.Color("a"))
+	tx = append(tx, 2)
+	dt, err := NewDigraph(&config.Config{Support: 2}, &Config{
+		MaxEdges:            3,
+		Mode:                mode | ExtFromEmb | Caching,
+		EmbSearchStartPoint: subgraph.RandomStart,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode&Transactions == Transactions {
+		dt.VertexTx = tx
+	}
+	err = dt.Init(b, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dt
+}
+
+func TestTransactionSupport(t *testing.T) {
+	x := assert.New(t)
+	dt := txDigraph(t, MNI|Transactions)
+	defer dt.Close()
+	supports := patternSupports(t, dt)
+	x.Equal(3, len(supports), "%v", supports)
+	x.Equal(3, supports["{0:1}(a)"])
+	x.Equal(2, supports["{0:1}(b)"])
+	x.Equal(2, supports["{1:2}(a)(b)[0->1:e]"])
+	// the c vertices are all in one transaction
+	x.Equal(0, supports["{0:1}(c)"])
+}
+
+func TestTransactionSupportMNI(t *testing.T) {
+	x := assert.New(t)
+	dt := txDigraph(t, MNI)
+	defer dt.Close()
+	supports := patternSupports(t, dt)
+	x.Equal(4, len(supports), "%v", supports)
+	x.Equal(4, supports["{0:1}(a)"])
+	x.Equal(3, supports["{0:1}(c)"])
+	x.Equal(3, supports["{1:2}(a)(b)[0->1:e]"])
+}
+
+func TestTransactionSupportNeedsTransactions(t *testing.T) {
+	x := assert.New(t)
+	dt, err := NewDigraph(&config.Config{Support: 2}, &Config{
+		Mode:                MNI | Transactions | ExtFromEmb,
+		EmbSearchStartPoint: subgraph.RandomStart,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dt.Close()
+	x.Error(dt.Init(digraph.Build(1, 1), digraph.NewLabels()))
+	dt.VertexTx = []int{}
+	dt.Mode |= Approximate
+	x.Error(dt.Init(digraph.Build(1, 1), digraph.NewLabels()))
+}
@@ -0,0 +1,90 @@
+package digraph
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+import (
+	"github.com/timtadh/data-structures/hashtable"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/types/digraph/digraph"
+	"github.com/timtadh/regrax/types/digraph/subgraph"
+)
+
+// cappedDigraph has 10 a -> b edges and keeps 3 embeddings per pattern.
+func cappedDigraph(t testing.TB) *Digraph {
+	labels := digraph.NewLabels()
+	b := digraph.Build(20, 10)
+	for i := 0; i < 10; i++ {
+		b.AddEdge(b.AddVertex(labels.Color("a")), b.AddVertex(labels.Color("b")), labels.Color("e"))
+	}
+	dt, err := NewDigraph(&config.Config{Support: 2}, &Config{
+		MaxEdges:            1,
+		MaxEmbeddings:       3,
+		Mode:                MNI | ExtFromEmb | Caching,
+		EmbSearchStartPoint: subgraph.RandomStart,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = dt.Init(b, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dt
+}
+
+func TestPutCapped(t *testing.T) {
+	x := assert.New(t)
+	dt := &Digraph{Config: Config{MaxEmbeddings: 2}}
+	set := hashtable.NewLinearHash()
+	for id := 0; id < 5; id++ {
+		putCapped(dt, set, id, &subgraph.Embedding{Ids: []int{id}})
+		putCapped(dt, set, id, &subgraph.Embedding{Ids: []int{id}})
+	}
+	x.Equal(5, set.Size())
+	kept := 0
+	for i, next := set.Values()(); next != nil; i, next = next() {
+		if _, ok := i.(*subgraph.Embedding); ok {
+			kept++
+		}
+	}
+	x.Equal(2, kept)
+}
+
+func TestMaxEmbeddings(t *testing.T) {
+	x := assert.New(t)
+	dt := cappedDigraph(t)
+	defer dt.Close()
+	n := edgeNodes(t, dt)["{1:2}(a)(b)[0->1:e]"]
+	if !x.NotNil(n) {
+		return
+	}
+	x.Equal(10, n.Support())
+	x.True(n.Truncated())
+	x.Equal(10, n.EmbeddingCount())
+	embs, err := n.Embeddings()
+	x.Nil(err)
+	x.Equal(3, len(embs))
+}
+
+func TestMaxEmbeddingsCached(t *testing.T) {
+	x := assert.New(t)
+	dt := cappedDigraph(t)
+	defer dt.Close()
+	n := edgeNodes(t, dt)["{1:2}(a)(b)[0->1:e]"]
+	if !x.NotNil(n) {
+		return
+	}
+	// the counts in memory are lost when the run is reloaded from its cache
+	dt.truncated = make(map[string]int)
+	has, support, _, embs, _, _, err := loadCachedExtsEmbs(dt, n.Pat)
+	x.Nil(err)
+	x.True(has)
+	x.Equal(10, support)
+	x.Equal(3, len(embs))
+	x.True(n.Truncated())
+	x.Equal(10, n.EmbeddingCount())
+}
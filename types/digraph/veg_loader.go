@@ -47,8 +47,16 @@ func (v *VegLoader) Load(input lattice.Input) (dt lattice.DataType, err error) {
 	return v.LoadWithLabels(input, digraph.NewLabels())
 }
 
+func (v *VegLoader) LoadDir(inputs []lattice.NamedInput) (dt lattice.DataType, err error) {
+	return v.loadWithLabels(inputs, digraph.NewLabels())
+}
+
 func (v *VegLoader) LoadWithLabels(input lattice.Input, labels *digraph.Labels) (lattice.DataType, error) {
-	G, err := v.loadDigraph(input, labels)
+	return v.loadWithLabels([]lattice.NamedInput{{Input: input}}, labels)
+}
+
+func (v *VegLoader) loadWithLabels(inputs []lattice.NamedInput, labels *digraph.Labels) (lattice.DataType, error) {
+	G, err := v.loadDigraph(inputs, labels)
 	if err != nil {
 		return nil, err
 	}
@@ -59,38 +67,46 @@ func (v *VegLoader) LoadWithLabels(input lattice.Input, labels *digraph.Labels)
 	return v.dt, nil
 }
 
-func (v *VegLoader) loadDigraph(input lattice.Input, labels *digraph.Labels) (*digraph.Builder, error) {
+func (v *VegLoader) loadDigraph(inputs []lattice.NamedInput, labels *digraph.Labels) (*digraph.Builder, error) {
 	var errs ErrorList
-	V, E, err := graphSize(input)
-	if err != nil {
-		return nil, err
+	V, E := 0, 0
+	for _, input := range inputs {
+		fV, fE, err := graphSize(input.Input)
+		if err != nil {
+			return nil, err
+		}
+		V += fV
+		E += fE
 	}
 	errors.Logf("DEBUG", "Got graph size %v %v", V, E)
 	G := digraph.Build(V, E)
-	b := newBaseLoader(v.dt, G)
-
-	in, closer := input()
-	defer closer()
-	err = processLines(in, func(line []byte) {
-		if len(line) == 0 || !bytes.Contains(line, []byte("\t")) {
-			return
-		}
-		line_type, data := parseLine(line)
-		switch line_type {
-		case "vertex":
-			if err := v.loadVertex(labels, b, data); err != nil {
-				errs = append(errs, err)
+	b := newBaseLoader(v.dt, G, labels)
+
+	for ns, input := range inputs {
+		b.startFile(ns, input)
+		in, closer := input.Input()
+		err := processLines(in, func(line []byte) {
+			if len(line) == 0 || !bytes.Contains(line, []byte("\t")) {
+				return
 			}
-		case "edge":
-			if err := v.loadEdge(labels, b, data); err != nil {
-				errs = append(errs, err)
+			line_type, data := parseLine(line)
+			switch line_type {
+			case "vertex":
+				if err := v.loadVertex(labels, b, data); err != nil {
+					errs = append(errs, err)
+				}
+			case "edge":
+				if err := v.loadEdge(labels, b, data); err != nil {
+					errs = append(errs, err)
+				}
+			default:
+				errs = append(errs, errors.Errorf("Unknown line type %v", line_type))
 			}
-		default:
-			errs = append(errs, errors.Errorf("Unknown line type %v", line_type))
+		})
+		closer()
+		if err != nil {
+			return nil, err
 		}
-	})
-	if err != nil {
-		return nil, err
 	}
 	if len(errs) == 0 {
 		return G, nil
@@ -128,7 +144,7 @@ func (v *VegLoader) loadEdge(labels *digraph.Labels, b *baseLoader, data []byte)
 	src := int32(_src)
 	targ := int32(_targ)
 	label := strings.TrimSpace(obj["label"].(string))
-	return b.addEdge(src, targ, labels.Color(label), label)
+	return b.addEdge(src, targ, labels.Color(label), label, obj)
 }
 
 func processLines(in io.Reader, process func([]byte)) error {
@@ -0,0 +1,113 @@
+package digraph
+
+import (
+	"math"
+)
+
+import (
+	"github.com/timtadh/regrax/types/digraph/subgraph"
+)
+
+// the weighted support counting modes
+const Weighted = WeightedSum | WeightedMin | WeightedMNI
+
+// WeightedSupport returns the weighted support of the pattern if it was
+// counted with one of the weighted modes.
+func (dt *Digraph) WeightedSupport(pattern *subgraph.SubGraph) (float64, bool) {
+	dt.lock.RLock()
+	defer dt.lock.RUnlock()
+	w, has := dt.weighted[string(pattern.Label())]
+	return w, has
+}
+
+// edgeWeight is the weight of the heaviest edge from src to targ with the
+// given color.
+func (dt *Digraph) edgeWeight(src, targ, color int) float64 {
+	w := 0.0
+	for _, e := range dt.G.Kids[src] {
+		ke := &dt.G.E[e]
+		if ke.Targ == targ && ke.Color == color && dt.EdgeWeight[e] > w {
+			w = dt.EdgeWeight[e]
+		}
+	}
+	return w
+}
+
+// embeddingWeight is the sum (or the min if min is set) of the weights of the
+// vertices and edges the embedding maps to.
+func (dt *Digraph) embeddingWeight(emb *subgraph.Embedding, min bool) float64 {
+	total := 0.0
+	least := math.Inf(1)
+	add := func(w float64) {
+		total += w
+		if w < least {
+			least = w
+		}
+	}
+	for _, id := range emb.Ids {
+		add(dt.VertexWeight[id])
+	}
+	for i := range emb.SG.E {
+		e := &emb.SG.E[i]
+		add(dt.edgeWeight(emb.Ids[e.Src], emb.Ids[e.Targ], e.Color))
+	}
+	if min {
+		return least
+	}
+	return total
+}
+
+// weightedSupport computes the weighted support of a pattern from all of its
+// embeddings. It returns the support and the embeddings which make it up.
+func weightedSupport(dt *Digraph, pattern *subgraph.SubGraph, embs []*subgraph.Embedding) (float64, []*subgraph.Embedding) {
+	if dt.Mode&WeightedMNI == WeightedMNI {
+		return weightedMNI(dt, pattern, embs)
+	}
+	min := dt.Mode&WeightedMin == WeightedMin
+	total := 0.0
+	for _, emb := range embs {
+		total += dt.embeddingWeight(emb, min)
+	}
+	return total, embs
+}
+
+// weightedMNI is the minimum image support where each distinct image of a
+// pattern vertex counts the (min) weight of the heaviest embedding through
+// it. Every embedding of a child restricts to an embedding of its parent
+// which weighs at least as much so the measure is anti-monotone.
+func weightedMNI(dt *Digraph, pattern *subgraph.SubGraph, embs []*subgraph.Embedding) (float64, []*subgraph.Embedding) {
+	if len(embs) == 0 {
+		return 0, embs
+	}
+	weights := make(map[*subgraph.Embedding]float64, len(embs))
+	best := make([]map[int]*subgraph.Embedding, len(pattern.V))
+	for idx := range best {
+		best[idx] = make(map[int]*subgraph.Embedding)
+	}
+	for _, emb := range embs {
+		w := dt.embeddingWeight(emb, true)
+		weights[emb] = w
+		for idx, id := range emb.Ids {
+			if b, has := best[idx][id]; !has || weights[b] < w {
+				best[idx][id] = emb
+			}
+		}
+	}
+	support := -1.0
+	arg := 0
+	for idx := range best {
+		total := 0.0
+		for _, emb := range best[idx] {
+			total += weights[emb]
+		}
+		if support < 0 || total < support {
+			support = total
+			arg = idx
+		}
+	}
+	supported := make([]*subgraph.Embedding, 0, len(best[arg]))
+	for _, emb := range best[arg] {
+		supported = append(supported, emb)
+	}
+	return support, supported
+}
@@ -0,0 +1,160 @@
+package digraph
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/types/digraph/digraph"
+	"github.com/timtadh/regrax/types/digraph/subgraph"
+)
+
+// weightedDigraph has two a -> b edges. The first has the vertex weights 1
+// and 2 and the edge weight 5, the second the vertex weights 3 and 4 and the
+// edge weight 1.
+func weightedDigraph(t testing.TB, mode Mode) *Digraph {
+	labels := digraph.NewLabels()
+	b := digraph.Build(4, 2)
+	for c := 0; c < 2; c++ {
+		b.AddEdge(b.AddVertex(labels.Color("a")), b.AddVertex(labels.Color("b")), labels.Color("e"))
+	}
+	dt, err := NewDigraph(&config.Config{Support: 1}, &Config{
+		MaxEdges:            1,
+		Mode:                mode | ExtFromEmb | Caching,
+		EmbSearchStartPoint: subgraph.RandomStart,
+		WeightAttr:          "weight",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dt.VertexWeight = []float64{1, 2, 3, 4}
+	dt.EdgeWeight = []float64{5, 1}
+	err = dt.Init(b, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dt
+}
+
+func TestWeightedSupport(t *testing.T) {
+	for _, c := range []struct {
+		mode   Mode
+		weight float64
+	}{
+		{WeightedSum, (1 + 2 + 5) + (3 + 4 + 1)},
+		{WeightedMin, 1 + 1},
+		{WeightedMNI, 1 + 1},
+	} {
+		x := assert.New(t)
+		dt := weightedDigraph(t, c.mode)
+		n := edgeNodes(t, dt)["{1:2}(a)(b)[0->1:e]"]
+		if x.NotNil(n, "%v", c.mode) {
+			w, has := dt.WeightedSupport(n.Pat)
+			x.True(has)
+			x.Equal(c.weight, w, "%v", c.mode)
+			x.Equal(int(c.weight), n.Support(), "%v", c.mode)
+		}
+		dt.Close()
+	}
+}
+
+func TestWeightedSupportAboveCount(t *testing.T) {
+	// two a -> b edges and a c vertex. every vertex and edge weighs 5
+	// except c which weighs 9. the support is above the count of each
+	// color and edge but not above their weights.
+	for _, c := range []struct {
+		mode Mode
+		edge int
+	}{
+		{WeightedSum, 2 * (5 + 5 + 5)},
+		{WeightedMin, 5 + 5},
+		{WeightedMNI, 5 + 5},
+	} {
+		x := assert.New(t)
+		labels := digraph.NewLabels()
+		b := digraph.Build(5, 2)
+		for i := 0; i < 2; i++ {
+			b.AddEdge(b.AddVertex(labels.Color("a")), b.AddVertex(labels.Color("b")), labels.Color("e"))
+		}
+		b.AddVertex(labels.Color("c"))
+		dt, err := NewDigraph(&config.Config{Support: 8}, &Config{
+			MaxEdges:            1,
+			Mode:                c.mode | ExtFromEmb | Caching,
+			EmbSearchStartPoint: subgraph.RandomStart,
+			WeightAttr:          "weight",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		dt.VertexWeight = []float64{5, 5, 5, 5, 9}
+		dt.EdgeWeight = []float64{5, 5}
+		err = dt.Init(b, labels)
+		if err != nil {
+			t.Fatal(err)
+		}
+		supports := patternSupports(t, dt)
+		x.Equal(map[string]int{
+			"{0:1}(a)":            10,
+			"{0:1}(b)":            10,
+			"{0:1}(c)":            9,
+			"{1:2}(a)(b)[0->1:e]": c.edge,
+		}, supports, "%v", c.mode)
+		dt.Close()
+	}
+}
+
+func TestWeightedMNIHeaviestEmbedding(t *testing.T) {
+	x := assert.New(t)
+	// a -> b and a -> b' share the a vertex. the image of a counts the
+	// heavier of the two embeddings through it.
+	labels := digraph.NewLabels()
+	b := digraph.Build(3, 2)
+	a := b.AddVertex(labels.Color("a"))
+	b.AddEdge(a, b.AddVertex(labels.Color("b")), labels.Color("e"))
+	b.AddEdge(a, b.AddVertex(labels.Color("b")), labels.Color("e"))
+	dt, err := NewDigraph(&config.Config{Support: 1}, &Config{
+		MaxEdges:            1,
+		Mode:                WeightedMNI | ExtFromEmb | Caching,
+		EmbSearchStartPoint: subgraph.RandomStart,
+		WeightAttr:          "weight",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dt.Close()
+	dt.VertexWeight = []float64{9, 2, 7}
+	dt.EdgeWeight = []float64{8, 6}
+	err = dt.Init(b, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := edgeNodes(t, dt)["{1:2}(a)(b)[0->1:e]"]
+	if !x.NotNil(n) {
+		return
+	}
+	// the a image counts max(min(9, 2, 8), min(9, 7, 6)) = 6 and the b
+	// images count 2 + 6 = 8
+	w, _ := dt.WeightedSupport(n.Pat)
+	x.Equal(6.0, w)
+}
+
+func TestWeightedNeedsAntiMonotoneForExtensionPruning(t *testing.T) {
+	x := assert.New(t)
+	for _, mode := range []Mode{WeightedSum, WeightedMin} {
+		_, err := NewDigraph(&config.Config{Support: 1}, &Config{
+			Mode:                mode | ExtFromEmb | ExtensionPruning,
+			EmbSearchStartPoint: subgraph.RandomStart,
+			WeightAttr:          "weight",
+		})
+		x.Error(err, "%v", mode)
+	}
+	dt, err := NewDigraph(&config.Config{Support: 1}, &Config{
+		Mode:                WeightedMNI | ExtFromEmb | ExtensionPruning,
+		EmbSearchStartPoint: subgraph.RandomStart,
+		WeightAttr:          "weight",
+	})
+	x.Nil(err)
+	if dt != nil {
+		dt.Close()
+	}
+}
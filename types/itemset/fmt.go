@@ -6,6 +6,10 @@ import (
 	"strings"
 )
 
+import (
+	"github.com/timtadh/data-structures/types"
+)
+
 import (
 	"github.com/timtadh/regrax/lattice"
 )
@@ -26,7 +30,7 @@ func (f *Formatter) PatternName(node lattice.Node) string {
 	n := node.(*Node)
 	items := make([]string, 0, n.pat.Items.Size())
 	for i, next := n.pat.Items.Items()(); next != nil; i, next = next() {
-		items = append(items, fmt.Sprintf("%v", i))
+		items = append(items, n.dt.ItemName(int32(i.(types.Int32))))
 	}
 	return fmt.Sprintf("%s", strings.Join(items, " "))
 }
@@ -39,7 +43,7 @@ func (f *Formatter) Embeddings(node lattice.Node) ([]string, error) {
 	n := node.(*Node)
 	txs := make([]string, 0, len(n.txs))
 	for _, tx := range n.txs {
-		txs = append(txs, fmt.Sprintf("%v", tx))
+		txs = append(txs, n.dt.TxName(tx))
 	}
 	return txs, nil
 }
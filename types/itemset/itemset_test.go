@@ -49,10 +49,10 @@ func iterItems(items [][]int32) itemsIter {
 }
 
 func startingPoints(t *assert.Assertions) ([]*Node, *ItemSets, int) {
-	c := &config.Config{}
-	i, err := NewItemSets(c, NewIntLoader)
+	l, err := NewIntLoader(&config.Config{Support: 3}, 0, 10)
 	t.Nil(err)
-	N, err := i.Loader().(*IntLoader).startingPoints(iterItems(items), 3)
+	il := l.(*IntLoader)
+	N, err := il.startingPoints(iterItems(items))
 	t.Nil(err)
 	nodes := make([]*Node, 0, len(N))
 	for _, node := range N {
@@ -60,19 +60,21 @@ func startingPoints(t *assert.Assertions) ([]*Node, *ItemSets, int) {
 		nodes = append(nodes, n)
 		t.True(len(n.txs) >= 3, "len(n.txs) %d < 3", len(n.txs))
 	}
-	return nodes, i, 3
+	return nodes, il.sets, 3
 }
 
 func TestLoad(x *testing.T) {
 	t := assert.New(x)
-	startingPoints(t)
+	_, dt, _ := startingPoints(t)
+	dt.Close()
 }
 
 func TestKids_1(x *testing.T) {
 	t := assert.New(x)
-	nodes, dt, sup := startingPoints(t)
+	nodes, dt, _ := startingPoints(t)
+	defer dt.Close()
 	n1 := nodes[0]
-	kids, err := n1.Children(sup, dt)
+	kids, err := n1.Children()
 	t.Nil(err)
 	expected := set.FromSlice([]types.Hashable{
 		set.FromSlice([]types.Hashable{types.Int32(1), types.Int32(2)}),
@@ -83,28 +85,30 @@ func TestKids_1(x *testing.T) {
 	})
 	var next *Node = nil
 	for _, kid := range kids {
-		if kid.(*Node).items.Has(types.Int32(2)) {
+		if kid.(*Node).pat.Items.Has(types.Int32(2)) {
 			next = kid.(*Node)
 		}
-		has := expected.Has(kid.(*Node).items)
-		t.True(has, "%v not in %v", kid.(*Node).items, expected)
+		has := expected.Has(kid.(*Node).pat.Items)
+		t.True(has, "%v not in %v", kid.(*Node).pat.Items, expected)
 	}
-	kids, err = next.Children(sup, dt)
+	kids, err = next.Children()
 	t.Nil(err)
 	t.True(len(kids) == 1, "len(kids) %d != 1", len(kids))
-	t.True(kids[0].(*Node).items.Equals(
+	t.True(kids[0].(*Node).pat.Items.Equals(
 		set.FromSlice([]types.Hashable{types.Int32(1), types.Int32(2),
 			types.Int32(3)})))
 }
 
 func TestParents_123(x *testing.T) {
 	t := assert.New(x)
-	_, dt, sup := startingPoints(t)
+	_, dt, _ := startingPoints(t)
+	defer dt.Close()
 	n123 := &Node{
-		items: set.FromSlice([]types.Hashable{types.Int32(1), types.Int32(2), types.Int32(3)}),
-		txs:   []int32{1, 2, 3},
+		pat: Pattern{set.FromSlice([]types.Hashable{types.Int32(1), types.Int32(2), types.Int32(3)})},
+		dt:  dt,
+		txs: []int32{1, 2, 3},
 	}
-	parents, err := n123.Parents(sup, dt)
+	parents, err := n123.Parents()
 	t.Nil(err, "%v", err)
 	expected := set.FromSlice([]types.Hashable{
 		set.FromSlice([]types.Hashable{types.Int32(1), types.Int32(2)}),
@@ -112,7 +116,7 @@ func TestParents_123(x *testing.T) {
 		set.FromSlice([]types.Hashable{types.Int32(2), types.Int32(3)}),
 	})
 	for _, p := range parents {
-		has := expected.Has(p.(*Node).items)
-		t.True(has, "%v not in %v", p.(*Node).items, expected)
+		has := expected.Has(p.(*Node).pat.Items)
+		t.True(has, "%v not in %v", p.(*Node).pat.Items, expected)
 	}
 }
@@ -0,0 +1,121 @@
+package itemset
+
+import (
+	"bufio"
+	"strings"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/lattice"
+)
+
+// LabeledLoader loads transactions of string items. Each item name is
+// assigned an int in the order it is first seen and the dictionary is kept
+// in ItemSets.ItemNames so patterns can be printed with the original names.
+type LabeledLoader struct {
+	IntLoader
+	Separator  string // "" splits on any whitespace
+	TxIdColumn int    // column holding the transaction id (-1 for none)
+	itemIds    map[string]int32
+}
+
+func NewLabeledLoader(config *config.Config, min, max int, sep string, txIdCol int) (lattice.Loader, error) {
+	sets, err := NewItemSets(config, min, max)
+	if err != nil {
+		return nil, err
+	}
+	l := &LabeledLoader{
+		IntLoader:  IntLoader{sets: sets},
+		Separator:  sep,
+		TxIdColumn: txIdCol,
+		itemIds:    make(map[string]int32),
+	}
+	return l, nil
+}
+
+func (l *LabeledLoader) split(line string) []string {
+	if l.Separator == "" {
+		return strings.Fields(line)
+	}
+	cols := strings.Split(line, l.Separator)
+	for i := range cols {
+		cols[i] = strings.TrimSpace(cols[i])
+	}
+	return cols
+}
+
+func (l *LabeledLoader) itemId(name string) int32 {
+	if id, has := l.itemIds[name]; has {
+		return id
+	}
+	id := int32(len(l.sets.ItemNames))
+	l.itemIds[name] = id
+	l.sets.ItemNames = append(l.sets.ItemNames, name)
+	return id
+}
+
+// items may be called several times on the same input. The dictionaries
+// are only extended the first time an item (or transaction) is seen so the
+// ids are stable across passes.
+func (l *LabeledLoader) items(input lattice.Input) func(do func(tx, item int32) error) error {
+	return func(do func(tx, item int32) error) error {
+		in, closer := input()
+		defer closer()
+		scanner := bufio.NewScanner(in)
+		tx := int32(0)
+		for scanner.Scan() {
+			if tx%1000 == 0 {
+				errors.Logf("INFO", "line %d", tx)
+			}
+			line := scanner.Text()
+			if strings.TrimSpace(line) == "" {
+				// an empty transaction, as in the int loader
+				if l.TxIdColumn >= 0 && int(tx) == len(l.sets.TxNames) {
+					l.sets.TxNames = append(l.sets.TxNames, "")
+				}
+				tx += 1
+				continue
+			}
+			cols := l.split(line)
+			if l.TxIdColumn >= 0 {
+				if l.TxIdColumn >= len(cols) {
+					return errors.Errorf("input line %d has no transaction id column %d", tx, l.TxIdColumn)
+				}
+				if int(tx) == len(l.sets.TxNames) {
+					l.sets.TxNames = append(l.sets.TxNames, cols[l.TxIdColumn])
+				}
+			}
+			for i, col := range cols {
+				if col == "" || i == l.TxIdColumn {
+					continue
+				}
+				err := do(tx, l.itemId(col))
+				if err != nil {
+					errors.Logf("ERROR", "%v", err)
+					return err
+				}
+			}
+			tx += 1
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		l.sets.Transactions = int(tx)
+		return nil
+	}
+}
+
+func (l *LabeledLoader) Load(input lattice.Input) (lattice.DataType, error) {
+	start, err := l.startingPoints(l.items(input))
+	if err != nil {
+		return nil, err
+	}
+	l.sets.empty = &Node{Pattern{int32sToSet([]int32{})}, l.sets, []int32{}}
+	l.sets.FrequentItems = start
+	return l.sets, nil
+}
@@ -36,6 +36,9 @@ type ItemSets struct {
 	CanonKidCount      ints_int.MultiMap
 	Embeddings         ints_ints.MultiMap
 	FrequentItems      []lattice.Node
+	ItemNames          []string // item -> name (nil when items are ints)
+	TxNames            []string // tx -> id (nil when txs are line numbers)
+	Transactions       int
 	empty              lattice.Node
 	config             *config.Config
 }
@@ -139,6 +142,20 @@ func (i *ItemSets) TooLarge(node lattice.Node) bool {
 	return items > i.MaxItems
 }
 
+func (i *ItemSets) ItemName(item int32) string {
+	if i.ItemNames != nil && int(item) < len(i.ItemNames) {
+		return i.ItemNames[item]
+	}
+	return strconv.Itoa(int(item))
+}
+
+func (i *ItemSets) TxName(tx int32) string {
+	if i.TxNames != nil && int(tx) < len(i.TxNames) {
+		return i.TxNames[tx]
+	}
+	return strconv.Itoa(int(tx))
+}
+
 func (i *ItemSets) Close() error {
 	i.config.AsyncTasks.Wait()
 	i.Parents.Close()
@@ -167,8 +184,11 @@ func NewIntLoader(config *config.Config, min, max int) (lattice.Loader, error) {
 }
 
 func (l *IntLoader) max(items itemsIter) (max_tx, max_item int32, err error) {
+	max_tx = -1
 	err = items(func(tx, item int32) error {
-		max_tx++
+		if tx > max_tx {
+			max_tx = tx
+		}
 		if item > max_item {
 			max_item = item
 		}
@@ -193,6 +213,12 @@ func (l *IntLoader) indices(items itemsIter) (idx, inv index, err error) {
 	if err != nil {
 		return nil, nil, err
 	}
+	// empty transactions count too. the readers set the number of lines
+	// (trailing blank ones included), otherwise the last tx with an item
+	// ends the transactions.
+	if int(max_tx)+1 > l.sets.Transactions {
+		l.sets.Transactions = int(max_tx) + 1
+	}
 	errors.Logf("DEBUG", "max tx : %v, max item : %v", max_tx, max_item)
 	idx = make(index, max_tx+1)
 	inv = make(index, max_item+1)
@@ -240,6 +266,7 @@ func (l *IntLoader) items(input lattice.Input) func(do func(tx, item int32) erro
 		if err := scanner.Err(); err != nil {
 			return err
 		}
+		l.sets.Transactions = int(tx)
 		return nil
 	}
 }
@@ -0,0 +1,111 @@
+package itemset
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+import (
+	"bytes"
+	"io"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+)
+
+func stringInput(s string) func() (io.Reader, func()) {
+	return func() (io.Reader, func()) {
+		return bytes.NewBufferString(s), func() {}
+	}
+}
+
+func txItems(t *testing.T, items itemsIter) map[int32][]int32 {
+	txs := make(map[int32][]int32)
+	err := items(func(tx, item int32) error {
+		txs[tx] = append(txs[tx], item)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return txs
+}
+
+func TestIntLoaderBlankLines(t *testing.T) {
+	x := assert.New(t)
+	l, err := NewIntLoader(&config.Config{Support: 1}, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	il := l.(*IntLoader)
+	defer il.sets.Close()
+	txs := txItems(t, il.items(stringInput("1 2\n\n3\n")))
+	x.Equal(map[int32][]int32{0: {1, 2}, 2: {3}}, txs)
+}
+
+func TestLabeledLoaderBlankLines(t *testing.T) {
+	x := assert.New(t)
+	l, err := NewLabeledLoader(&config.Config{Support: 1}, 0, 10, "", -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ll := l.(*LabeledLoader)
+	defer ll.sets.Close()
+	txs := txItems(t, ll.items(stringInput("a b\n\nc\n  \nb\n")))
+	x.Equal(map[int32][]int32{0: {0, 1}, 2: {2}, 4: {1}}, txs)
+	x.Equal([]string{"a", "b", "c"}, ll.sets.ItemNames)
+}
+
+func TestLabeledLoaderTxIds(t *testing.T) {
+	x := assert.New(t)
+	l, err := NewLabeledLoader(&config.Config{Support: 1}, 0, 10, ",", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ll := l.(*LabeledLoader)
+	defer ll.sets.Close()
+	items := ll.items(stringInput("t1, bread, milk\n\nt3, milk\n"))
+	txs := txItems(t, items)
+	// a second pass must not extend the dictionaries
+	txs = txItems(t, items)
+	x.Equal(map[int32][]int32{0: {0, 1}, 2: {1}}, txs)
+	x.Equal([]string{"bread", "milk"}, ll.sets.ItemNames)
+	x.Equal([]string{"t1", "", "t3"}, ll.sets.TxNames)
+	x.Equal("t3", ll.sets.TxName(2))
+	x.Equal("milk", ll.sets.ItemName(1))
+}
+
+func TestLabeledLoaderMissingTxId(t *testing.T) {
+	x := assert.New(t)
+	l, err := NewLabeledLoader(&config.Config{Support: 1}, 0, 10, ",", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ll := l.(*LabeledLoader)
+	defer ll.sets.Close()
+	err = ll.items(stringInput("t1, bread\n"))(func(tx, item int32) error {
+		return nil
+	})
+	x.Error(err)
+}
+
+func TestLoaderTrailingBlankLines(t *testing.T) {
+	x := assert.New(t)
+	l, err := NewIntLoader(&config.Config{Support: 1}, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	il := l.(*IntLoader)
+	defer il.sets.Close()
+	_, err = il.startingPoints(il.items(stringInput("1 2\n\n3\n\n\n")))
+	x.Nil(err)
+	x.Equal(5, il.sets.Transactions)
+	l, err = NewLabeledLoader(&config.Config{Support: 1}, 0, 10, "", -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ll := l.(*LabeledLoader)
+	defer ll.sets.Close()
+	_, err = ll.startingPoints(ll.items(stringInput("\na b\n\n")))
+	x.Nil(err)
+	x.Equal(3, ll.sets.Transactions)
+}
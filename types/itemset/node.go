@@ -15,6 +15,7 @@ import (
 
 import (
 	"github.com/timtadh/regrax/lattice"
+	"github.com/timtadh/regrax/progress"
 	"github.com/timtadh/regrax/stores/ints_int"
 	"github.com/timtadh/regrax/stores/ints_ints"
 )
@@ -75,6 +76,16 @@ func (n *Node) Pattern() lattice.Pattern {
 	return &n.pat
 }
 
+// Support is the number of transactions containing the itemset.
+func (n *Node) Support() int {
+	return len(n.txs)
+}
+
+// ItemNames are the names of the items in the itemset.
+func (n *Node) ItemNames() []string {
+	return n.dt.itemNames(setToInt32s(n.pat.Items))
+}
+
 func (n *Node) Save() error {
 	key := setToInt32s(n.pat.Items)
 	if has, err := n.dt.Embeddings.Has(key); err != nil {
@@ -175,8 +186,11 @@ func (n *Node) kids(counts ints_int.MultiMap, kids ints_ints.MultiMap, candidate
 	if has, err := counts.Has(i); err != nil {
 		return nil, err
 	} else if has {
+		progress.CacheHits.Inc()
 		return n.cached(kids, i)
 	}
+	progress.CacheMisses.Inc()
+	progress.Expanded.Inc()
 	exts := candidates()
 	nodes, err := n.nodesFromCandidateKids(exts)
 	if err != nil {
@@ -0,0 +1,191 @@
+package itemset
+
+import (
+	"fmt"
+	"strings"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+)
+
+import (
+	"github.com/timtadh/regrax/lattice"
+)
+
+// An association rule Antecedent -> Consequent derived from a frequent
+// itemset (the union of the two sides).
+type Rule struct {
+	Antecedent []string
+	Consequent []string
+	Support    int
+	Confidence float64
+	Lift       float64
+}
+
+func (r *Rule) String() string {
+	return fmt.Sprintf("%s -> %s", strings.Join(r.Antecedent, " "), strings.Join(r.Consequent, " "))
+}
+
+func (i *ItemSets) itemNames(items []int32) []string {
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		names = append(names, i.ItemName(item))
+	}
+	return names
+}
+
+// support of an arbitrary set of frequent items. Uses the embeddings store
+// when the set has already been mined and falls back to intersecting the
+// inverted index.
+func (i *ItemSets) support(items []int32) (int, error) {
+	if n, err := TryLoadNode(items, i); err != nil {
+		return 0, err
+	} else if n != nil {
+		return len(n.txs), nil
+	}
+	var txs map[int32]bool
+	for _, item := range items {
+		if int(item) >= len(i.InvertedIndex) {
+			return 0, nil
+		}
+		next := make(map[int32]bool)
+		for _, tx := range i.InvertedIndex[item] {
+			if txs == nil || txs[tx] {
+				next[tx] = true
+			}
+		}
+		txs = next
+	}
+	return len(txs), nil
+}
+
+// Rules derives the rules X -> Y such that X union Y is the itemset of the
+// node, X, Y are non-empty and disjoint, the confidence is >= minConf and the
+// lift is >= minLift. The consequents are grown level-wise (as in apriori):
+// moving an item from X to Y can only lower the confidence so a consequent is
+// only extended when its rule met minConf.
+func Rules(node lattice.Node, minConf, minLift float64) ([]*Rule, error) {
+	n, ok := node.(*Node)
+	if !ok {
+		return nil, errors.Errorf("rules can only be computed for itemsets")
+	}
+	items := setToInt32s(n.pat.Items)
+	if len(items) < 2 {
+		return nil, nil
+	}
+	total := n.dt.Transactions
+	support := len(n.txs)
+	if total == 0 || support == 0 {
+		return nil, nil
+	}
+	rules := make([]*Rule, 0, 10)
+	consequents := make([][]int32, 0, len(items))
+	for _, item := range items {
+		consequents = append(consequents, []int32{item})
+	}
+	for len(consequents) > 0 && len(consequents[0]) < len(items) {
+		confident := make([][]int32, 0, len(consequents))
+		for _, y := range consequents {
+			x := difference(items, y)
+			xSupport, err := n.dt.support(x)
+			if err != nil {
+				return nil, err
+			}
+			ySupport, err := n.dt.support(y)
+			if err != nil {
+				return nil, err
+			}
+			if xSupport == 0 || ySupport == 0 {
+				continue
+			}
+			conf := float64(support) / float64(xSupport)
+			if conf < minConf {
+				continue
+			}
+			confident = append(confident, y)
+			lift := conf / (float64(ySupport) / float64(total))
+			if lift < minLift {
+				continue
+			}
+			rules = append(rules, &Rule{
+				Antecedent: n.dt.itemNames(x),
+				Consequent: n.dt.itemNames(y),
+				Support:    support,
+				Confidence: conf,
+				Lift:       lift,
+			})
+		}
+		consequents = joinConsequents(confident)
+	}
+	return rules, nil
+}
+
+// difference of the sorted items and the sorted subset.
+func difference(items, subset []int32) []int32 {
+	diff := make([]int32, 0, len(items)-len(subset))
+	j := 0
+	for _, item := range items {
+		if j < len(subset) && subset[j] == item {
+			j++
+		} else {
+			diff = append(diff, item)
+		}
+	}
+	return diff
+}
+
+// joinConsequents makes the consequents of the next level from the sorted
+// consequents of this level which met the confidence: two which differ only
+// in their last item are joined and the join is kept only if every one of
+// its subsets of this level is among them.
+func joinConsequents(level [][]int32) [][]int32 {
+	have := make(map[string]bool, len(level))
+	for _, y := range level {
+		have[fmt.Sprint(y)] = true
+	}
+	next := make([][]int32, 0, len(level))
+	for i, a := range level {
+		for _, b := range level[i+1:] {
+			k := len(a) - 1
+			if !equalInt32s(a[:k], b[:k]) {
+				continue
+			}
+			lo, hi := a[k], b[k]
+			if hi < lo {
+				lo, hi = hi, lo
+			}
+			y := make([]int32, 0, len(a)+1)
+			y = append(y, a[:k]...)
+			y = append(y, lo, hi)
+			if allSubsets(y, have) {
+				next = append(next, y)
+			}
+		}
+	}
+	return next
+}
+
+func allSubsets(y []int32, have map[string]bool) bool {
+	for i := range y {
+		sub := make([]int32, 0, len(y)-1)
+		sub = append(sub, y[:i]...)
+		sub = append(sub, y[i+1:]...)
+		if !have[fmt.Sprint(sub)] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalInt32s(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
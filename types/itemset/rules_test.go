@@ -0,0 +1,82 @@
+package itemset
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+import (
+	"github.com/timtadh/regrax/config"
+)
+
+// six transactions (the fifth is empty) in which 1 2 3 occurs twice.
+func rulesNode(t *testing.T) *Node {
+	l, err := NewIntLoader(&config.Config{Support: 1}, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	il := l.(*IntLoader)
+	_, err = il.startingPoints(iterItems([][]int32{
+		{1, 2, 3},
+		{1, 2, 3},
+		{1, 2},
+		{3, 4},
+		{},
+		{1, 4},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Node{Pattern{int32sToSet([]int32{1, 2, 3})}, il.sets, []int32{0, 1}}
+}
+
+func ruleStrings(rules []*Rule) []string {
+	s := make([]string, 0, len(rules))
+	for _, r := range rules {
+		s = append(s, r.String())
+	}
+	return s
+}
+
+func TestRulesTransactions(t *testing.T) {
+	x := assert.New(t)
+	n := rulesNode(t)
+	defer n.dt.Close()
+	x.Equal(6, n.dt.Transactions)
+}
+
+func TestRules(t *testing.T) {
+	x := assert.New(t)
+	n := rulesNode(t)
+	defer n.dt.Close()
+	rules, err := Rules(n, 0, 0)
+	x.Nil(err)
+	x.Equal([]string{
+		"2 3 -> 1",
+		"1 3 -> 2",
+		"1 2 -> 3",
+		"3 -> 1 2",
+		"2 -> 1 3",
+		"1 -> 2 3",
+	}, ruleStrings(rules))
+	x.Equal(2, rules[0].Support)
+	x.Equal(1.0, rules[0].Confidence)
+	x.InDelta(6.0/4.0, rules[0].Lift, 1e-9)
+	x.InDelta(2.0/3.0, rules[4].Confidence, 1e-9)
+	x.InDelta(2.0, rules[4].Lift, 1e-9)
+	x.InDelta(.5, rules[5].Confidence, 1e-9)
+}
+
+func TestRulesPruneConsequents(t *testing.T) {
+	x := assert.New(t)
+	n := rulesNode(t)
+	defer n.dt.Close()
+	rules, err := Rules(n, .6, 0)
+	x.Nil(err)
+	x.Equal([]string{"2 3 -> 1", "1 3 -> 2", "1 2 -> 3", "3 -> 1 2", "2 -> 1 3"}, ruleStrings(rules))
+	// 1 2 -> 3 misses the confidence so no consequent containing 3 is tried
+	rules, err = Rules(n, .9, 0)
+	x.Nil(err)
+	x.Equal([]string{"2 3 -> 1", "1 3 -> 2"}, ruleStrings(rules))
+	rules, err = Rules(n, 0, 1.6)
+	x.Nil(err)
+	x.Equal([]string{"1 3 -> 2", "2 -> 1 3"}, ruleStrings(rules))
+}
@@ -0,0 +1,6 @@
+/*
+The sequence datatype. A pattern is an ordered sequence of items which is
+supported by every input sequence containing it as a (not necessarily
+contiguous) subsequence.
+*/
+package sequence
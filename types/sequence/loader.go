@@ -0,0 +1,223 @@
+package sequence
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/lattice"
+	"github.com/timtadh/regrax/stores/ints_int"
+	"github.com/timtadh/regrax/stores/ints_ints"
+)
+
+type Sequences struct {
+	MinLength, MaxLength int
+	Seqs                 [][]int32 // tx -> the sequence
+	InvertedIndex        [][]int32 // item -> txs containing it
+	ItemNames            []string
+	Parents              ints_ints.MultiMap
+	ParentCount          ints_int.MultiMap
+	Children             ints_ints.MultiMap
+	ChildCount           ints_int.MultiMap
+	CanonKids            ints_ints.MultiMap
+	CanonKidCount        ints_int.MultiMap
+	Embeddings           ints_ints.MultiMap
+	FrequentItems        []lattice.Node
+	empty                lattice.Node
+	config               *config.Config
+}
+
+func NewSequences(config *config.Config, min, max int) (s *Sequences, err error) {
+	parents, err := config.IntsIntsMultiMap("sequences-parents")
+	if err != nil {
+		return nil, err
+	}
+	parentCount, err := config.IntsIntMultiMap("sequences-parent-count")
+	if err != nil {
+		return nil, err
+	}
+	children, err := config.IntsIntsMultiMap("sequences-children")
+	if err != nil {
+		return nil, err
+	}
+	childCount, err := config.IntsIntMultiMap("sequences-child-count")
+	if err != nil {
+		return nil, err
+	}
+	canonKids, err := config.IntsIntsMultiMap("sequences-canon-kids")
+	if err != nil {
+		return nil, err
+	}
+	canonKidCount, err := config.IntsIntMultiMap("sequences-canon-kid-count")
+	if err != nil {
+		return nil, err
+	}
+	embeddings, err := config.IntsIntsMultiMap("sequences-embeddings")
+	if err != nil {
+		return nil, err
+	}
+	s = &Sequences{
+		MinLength:     min,
+		MaxLength:     max,
+		Parents:       parents,
+		ParentCount:   parentCount,
+		Children:      children,
+		ChildCount:    childCount,
+		CanonKids:     canonKids,
+		CanonKidCount: canonKidCount,
+		Embeddings:    embeddings,
+		config:        config,
+	}
+	return s, nil
+}
+
+func (s *Sequences) Support() int {
+	return s.config.Support
+}
+
+func (s *Sequences) LargestLevel() int {
+	return s.MaxLength
+}
+
+func (s *Sequences) MinimumLevel() int {
+	return s.MinLength
+}
+
+func (s *Sequences) Root() lattice.Node {
+	return s.empty
+}
+
+func (s *Sequences) Acceptable(node lattice.Node) bool {
+	n := node.(*Node)
+	items := len(n.pat.Items)
+	return s.MinLength <= items && items <= s.MaxLength
+}
+
+func (s *Sequences) TooLarge(node lattice.Node) bool {
+	n := node.(*Node)
+	return len(n.pat.Items) > s.MaxLength
+}
+
+func (s *Sequences) ItemName(item int32) string {
+	if int(item) < len(s.ItemNames) {
+		return s.ItemNames[item]
+	}
+	return strconv.Itoa(int(item))
+}
+
+func (s *Sequences) Close() error {
+	s.config.AsyncTasks.Wait()
+	s.Parents.Close()
+	s.ParentCount.Close()
+	s.Children.Close()
+	s.ChildCount.Close()
+	s.CanonKids.Close()
+	s.CanonKidCount.Close()
+	s.Embeddings.Close()
+	return nil
+}
+
+// LineLoader loads one sequence per line. The items of the sequence are
+// strings separated by Separator (or whitespace if it is empty).
+type LineLoader struct {
+	seqs      *Sequences
+	Separator string
+	itemIds   map[string]int32
+}
+
+func NewLineLoader(config *config.Config, min, max int, sep string) (lattice.Loader, error) {
+	seqs, err := NewSequences(config, min, max)
+	if err != nil {
+		return nil, err
+	}
+	l := &LineLoader{
+		seqs:      seqs,
+		Separator: sep,
+		itemIds:   make(map[string]int32),
+	}
+	return l, nil
+}
+
+func (l *LineLoader) split(line string) []string {
+	if l.Separator == "" {
+		return strings.Fields(line)
+	}
+	cols := strings.Split(line, l.Separator)
+	for i := range cols {
+		cols[i] = strings.TrimSpace(cols[i])
+	}
+	return cols
+}
+
+func (l *LineLoader) itemId(name string) int32 {
+	if id, has := l.itemIds[name]; has {
+		return id
+	}
+	id := int32(len(l.seqs.ItemNames))
+	l.itemIds[name] = id
+	l.seqs.ItemNames = append(l.seqs.ItemNames, name)
+	return id
+}
+
+func (l *LineLoader) Load(input lattice.Input) (lattice.DataType, error) {
+	in, closer := input()
+	defer closer()
+	scanner := bufio.NewScanner(in)
+	seqs := make([][]int32, 0, 100)
+	for scanner.Scan() {
+		if len(seqs)%1000 == 0 {
+			errors.Logf("INFO", "line %d", len(seqs))
+		}
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		seq := make([]int32, 0, 10)
+		for _, col := range l.split(line) {
+			if col == "" {
+				continue
+			}
+			seq = append(seq, l.itemId(col))
+		}
+		seqs = append(seqs, seq)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	l.seqs.Seqs = seqs
+	l.seqs.InvertedIndex = make([][]int32, len(l.seqs.ItemNames))
+	for tx, seq := range seqs {
+		seen := make(map[int32]bool)
+		for _, item := range seq {
+			if !seen[item] {
+				seen[item] = true
+				l.seqs.InvertedIndex[item] = append(l.seqs.InvertedIndex[item], int32(tx))
+			}
+		}
+	}
+	l.seqs.empty = &Node{Pattern{[]int32{}}, l.seqs, []int32{}}
+	l.seqs.FrequentItems = l.startingPoints()
+	return l.seqs, nil
+}
+
+func (l *LineLoader) startingPoints() []lattice.Node {
+	nodes := make([]lattice.Node, 0, 10)
+	for item, txs := range l.seqs.InvertedIndex {
+		if len(txs) >= l.seqs.Support() {
+			errors.Logf("INFO", "item %v len(txs) %d", l.seqs.ItemName(int32(item)), len(txs))
+			nodes = append(nodes, &Node{
+				pat: Pattern{[]int32{int32(item)}},
+				dt:  l.seqs,
+				txs: txs,
+			})
+		}
+	}
+	return nodes
+}
@@ -0,0 +1,398 @@
+package sequence
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+	"github.com/timtadh/data-structures/types"
+)
+
+import (
+	"github.com/timtadh/regrax/lattice"
+	"github.com/timtadh/regrax/stores/ints_int"
+	"github.com/timtadh/regrax/stores/ints_ints"
+)
+
+type Pattern struct {
+	Items []int32
+}
+
+type Node struct {
+	pat Pattern
+	dt  *Sequences
+	txs []int32
+}
+
+// IsSubsequence returns true if pat can be obtained from seq by deleting
+// zero or more items (gaps are allowed).
+func IsSubsequence(pat, seq []int32) bool {
+	i := 0
+	for _, item := range seq {
+		if i >= len(pat) {
+			break
+		}
+		if pat[i] == item {
+			i++
+		}
+	}
+	return i >= len(pat)
+}
+
+// LongestCommonSubsequence computes one of the longest common subsequences
+// of a and b.
+func LongestCommonSubsequence(a, b []int32) []int32 {
+	T := make([][]int, len(a)+1)
+	for i := range T {
+		T[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				T[i][j] = T[i+1][j+1] + 1
+			} else if T[i+1][j] >= T[i][j+1] {
+				T[i][j] = T[i+1][j]
+			} else {
+				T[i][j] = T[i][j+1]
+			}
+		}
+	}
+	lcs := make([]int32, 0, T[0][0])
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		if a[i] == b[j] {
+			lcs = append(lcs, a[i])
+			i++
+			j++
+		} else if T[i+1][j] >= T[i][j+1] {
+			i++
+		} else {
+			j++
+		}
+	}
+	return lcs
+}
+
+func TryLoadNode(items []int32, dt *Sequences) (n *Node, _ error) {
+	err := dt.Embeddings.DoFind(items, func(key, txs []int32) error {
+		n = &Node{Pattern{key}, dt, txs}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func LoadNode(items []int32, dt *Sequences) (n *Node, err error) {
+	n, err = TryLoadNode(items, dt)
+	if err != nil {
+		return nil, err
+	} else if n == nil {
+		return nil, errors.Errorf("Expected node %v to be in embeddings store but it wasn't", items)
+	}
+	return n, nil
+}
+
+func (n *Node) Pattern() lattice.Pattern {
+	return &n.pat
+}
+
+func (n *Node) Save() error {
+	if has, err := n.dt.Embeddings.Has(n.pat.Items); err != nil {
+		return err
+	} else if has {
+		return nil
+	}
+	return n.dt.Embeddings.Add(n.pat.Items, n.txs)
+}
+
+func (n *Node) String() string {
+	return fmt.Sprintf("<Node %v %v>", n.pat.Items, len(n.txs))
+}
+
+// supporting computes the sequences containing items. Every sequence
+// supporting a pattern must contain all of its items so only the smallest
+// inverted index list needs to be scanned.
+func (n *Node) supporting(items []int32) []int32 {
+	var smallest []int32
+	for i, item := range items {
+		if i == 0 || len(n.dt.InvertedIndex[item]) < len(smallest) {
+			smallest = n.dt.InvertedIndex[item]
+		}
+	}
+	txs := make([]int32, 0, len(smallest))
+	for _, tx := range smallest {
+		if IsSubsequence(items, n.dt.Seqs[tx]) {
+			txs = append(txs, tx)
+		}
+	}
+	return txs
+}
+
+func (n *Node) Parents() ([]lattice.Node, error) {
+	if len(n.pat.Items) == 0 {
+		return []lattice.Node{}, nil
+	} else if len(n.pat.Items) == 1 {
+		return []lattice.Node{n.dt.empty}, nil
+	}
+	if has, err := n.dt.ParentCount.Has(n.pat.Items); err != nil {
+		return nil, err
+	} else if has {
+		return n.cached(n.dt.Parents, n.pat.Items)
+	}
+	seen := make(map[string]bool)
+	nodes := make([]lattice.Node, 0, len(n.pat.Items))
+	for i := range n.pat.Items {
+		items := make([]int32, 0, len(n.pat.Items)-1)
+		items = append(items, n.pat.Items[:i]...)
+		items = append(items, n.pat.Items[i+1:]...)
+		key := string((&Pattern{items}).Label())
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if node, err := TryLoadNode(items, n.dt); err != nil {
+			return nil, err
+		} else if node != nil {
+			nodes = append(nodes, node)
+			continue
+		}
+		node := &Node{Pattern{items}, n.dt, n.supporting(items)}
+		err := node.Save()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	err := n.cache(n.dt.ParentCount, n.dt.Parents, n.pat.Items, nodes)
+	if err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+func (n *Node) Children() ([]lattice.Node, error) {
+	return n.kids(n.dt.ChildCount, n.dt.Children, n.allCandidateKids)
+}
+
+func (n *Node) CanonKids() ([]lattice.Node, error) {
+	return n.kids(n.dt.CanonKidCount, n.dt.CanonKids, n.canonCandidateKids)
+}
+
+func (n *Node) kids(counts ints_int.MultiMap, kids ints_ints.MultiMap, candidates func() map[string]*Node) ([]lattice.Node, error) {
+	if len(n.pat.Items) == 0 {
+		return n.dt.FrequentItems, nil
+	}
+	if len(n.pat.Items) >= n.dt.MaxLength {
+		return []lattice.Node{}, nil
+	}
+	if has, err := counts.Has(n.pat.Items); err != nil {
+		return nil, err
+	} else if has {
+		return n.cached(kids, n.pat.Items)
+	}
+	nodes, err := n.nodesFromCandidateKids(candidates())
+	if err != nil {
+		return nil, err
+	}
+	err = n.cache(counts, kids, n.pat.Items, nodes)
+	if err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// canonCandidateKids grows the pattern only at the end (prefix growth).
+// Every sequence has exactly one canonical parent: itself with the last
+// item removed.
+func (n *Node) canonCandidateKids() map[string]*Node {
+	return n.candidates(len(n.pat.Items), len(n.pat.Items))
+}
+
+// allCandidateKids inserts an item at every position in the pattern.
+func (n *Node) allCandidateKids() map[string]*Node {
+	return n.candidates(0, len(n.pat.Items))
+}
+
+func (n *Node) candidates(from, to int) map[string]*Node {
+	exts := make(map[string]*Node)
+	for _, tx := range n.txs {
+		seq := n.dt.Seqs[tx]
+		added := make(map[string]bool)
+		for _, item := range seq {
+			for pos := from; pos <= to; pos++ {
+				items := make([]int32, 0, len(n.pat.Items)+1)
+				items = append(items, n.pat.Items[:pos]...)
+				items = append(items, item)
+				items = append(items, n.pat.Items[pos:]...)
+				key := string((&Pattern{items}).Label())
+				if added[key] || !IsSubsequence(items, seq) {
+					continue
+				}
+				added[key] = true
+				if ext, has := exts[key]; has {
+					ext.txs = append(ext.txs, tx)
+				} else {
+					exts[key] = &Node{Pattern{items}, n.dt, []int32{tx}}
+				}
+			}
+		}
+	}
+	return exts
+}
+
+func (n *Node) nodesFromCandidateKids(exts map[string]*Node) ([]lattice.Node, error) {
+	nodes := make([]lattice.Node, 0, 10)
+	for _, node := range exts {
+		if len(node.txs) >= n.dt.Support() {
+			err := node.Save()
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+
+func (n *Node) AdjacentCount() (int, error) {
+	pc, err := n.ParentCount()
+	if err != nil {
+		return 0, err
+	}
+	cc, err := n.ChildCount()
+	if err != nil {
+		return 0, err
+	}
+	return pc + cc, nil
+}
+
+func (n *Node) ParentCount() (int, error) {
+	return n.count(n.dt.ParentCount, n.Parents)
+}
+
+func (n *Node) ChildCount() (int, error) {
+	return n.count(n.dt.ChildCount, n.Children)
+}
+
+func (n *Node) count(counts ints_int.MultiMap, compute func() ([]lattice.Node, error)) (int, error) {
+	if has, err := counts.Has(n.pat.Items); err != nil {
+		return 0, err
+	} else if !has {
+		nodes, err := compute()
+		if err != nil {
+			return 0, err
+		}
+		return len(nodes), nil
+	}
+	var count int32
+	err := counts.DoFind(n.pat.Items, func(_ []int32, c int32) error {
+		count = c
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+func (n *Node) Maximal() (bool, error) {
+	count, err := n.ChildCount()
+	if err != nil {
+		return false, err
+	}
+	return count == 0, nil
+}
+
+func (n *Node) cache(counts ints_int.MultiMap, m ints_ints.MultiMap, key []int32, nodes []lattice.Node) error {
+	for _, node := range nodes {
+		err := m.Add(key, node.(*Node).pat.Items)
+		if err != nil {
+			return err
+		}
+	}
+	return counts.Add(key, int32(len(nodes)))
+}
+
+func (n *Node) cached(m ints_ints.MultiMap, key []int32) (nodes []lattice.Node, _ error) {
+	nodes = make([]lattice.Node, 0, 10)
+	doerr := m.DoFind(key,
+		func(_, value []int32) error {
+			node, err := LoadNode(value, n.dt)
+			if err != nil {
+				return err
+			}
+			nodes = append(nodes, node)
+			return nil
+		})
+	if doerr != nil {
+		return nil, doerr
+	}
+	return nodes, nil
+}
+
+func (n *Node) Lattice() (*lattice.Lattice, error) {
+	return nil, &lattice.NoLattice{}
+}
+
+func (p *Pattern) Label() []byte {
+	size := uint32(len(p.Items))
+	bytes := make([]byte, 4*(size+1))
+	binary.BigEndian.PutUint32(bytes[0:4], size)
+	for i, item := range p.Items {
+		s := 4 * (i + 1)
+		binary.BigEndian.PutUint32(bytes[s:s+4], uint32(item))
+	}
+	return bytes
+}
+
+func (p *Pattern) Level() int {
+	return len(p.Items) + 1
+}
+
+func (a *Pattern) Distance(p lattice.Pattern) float64 {
+	b := p.(*Pattern)
+	lcs := float64(len(LongestCommonSubsequence(a.Items, b.Items)))
+	total := float64(len(a.Items)) + float64(len(b.Items)) - lcs
+	if total == 0 {
+		return 0
+	}
+	return 1.0 - (lcs / total)
+}
+
+func (p *Pattern) Equals(o types.Equatable) bool {
+	switch b := o.(type) {
+	case *Pattern:
+		if len(p.Items) != len(b.Items) {
+			return false
+		}
+		for i := range p.Items {
+			if p.Items[i] != b.Items[i] {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *Pattern) Less(o types.Sortable) bool {
+	switch b := o.(type) {
+	case *Pattern:
+		for i := 0; i < len(p.Items) && i < len(b.Items); i++ {
+			if p.Items[i] != b.Items[i] {
+				return p.Items[i] < b.Items[i]
+			}
+		}
+		return len(p.Items) < len(b.Items)
+	default:
+		return false
+	}
+}
+
+func (p *Pattern) Hash() int {
+	return types.ByteSlice(p.Label()).Hash()
+}
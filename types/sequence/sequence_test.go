@@ -0,0 +1,101 @@
+package sequence
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+import (
+	"io"
+	"sort"
+	"strings"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/lattice"
+)
+
+var seqs = `a b c
+a c
+b a c
+`
+
+func load(t *assert.Assertions) *Sequences {
+	c := &config.Config{Support: 2}
+	l, err := NewLineLoader(c, 0, 10, "")
+	t.Nil(err)
+	dt, err := l.Load(func() (io.Reader, func()) {
+		return strings.NewReader(seqs), func() {}
+	})
+	t.Nil(err)
+	return dt.(*Sequences)
+}
+
+func find(t *assert.Assertions, dt *Sequences, name string) *Node {
+	for _, n := range dt.FrequentItems {
+		if dt.ItemName(n.(*Node).pat.Items[0]) == name {
+			return n.(*Node)
+		}
+	}
+	t.Fail("could not find item", name)
+	return nil
+}
+
+func names(dt *Sequences, nodes []lattice.Node) []string {
+	fmtr := &Formatter{}
+	s := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		s = append(s, fmtr.PatternName(n))
+	}
+	sort.Strings(s)
+	return s
+}
+
+func TestIsSubsequence(x *testing.T) {
+	t := assert.New(x)
+	t.True(IsSubsequence([]int32{1, 3}, []int32{1, 2, 3}))
+	t.True(IsSubsequence([]int32{}, []int32{1, 2, 3}))
+	t.False(IsSubsequence([]int32{3, 1}, []int32{1, 2, 3}))
+	t.False(IsSubsequence([]int32{1, 1}, []int32{1, 2, 3}))
+}
+
+func TestLongestCommonSubsequence(x *testing.T) {
+	t := assert.New(x)
+	t.Equal([]int32{1, 3, 4}, LongestCommonSubsequence([]int32{1, 2, 3, 4}, []int32{5, 1, 3, 4}))
+	t.Equal([]int32{}, LongestCommonSubsequence([]int32{1, 2}, []int32{3}))
+}
+
+func TestKids(x *testing.T) {
+	t := assert.New(x)
+	dt := load(t)
+	defer dt.Close()
+	t.Equal(3, len(dt.FrequentItems))
+	a := find(t, dt, "a")
+	kids, err := a.Children()
+	t.Nil(err)
+	t.Equal([]string{"a c"}, names(dt, kids))
+	kids, err = a.CanonKids()
+	t.Nil(err)
+	t.Equal([]string{"a c"}, names(dt, kids))
+	b := find(t, dt, "b")
+	kids, err = b.Children()
+	t.Nil(err)
+	t.Equal([]string{"b c"}, names(dt, kids))
+	kids, err = b.CanonKids()
+	t.Nil(err)
+	t.Equal([]string{"b c"}, names(dt, kids))
+}
+
+func TestParents(x *testing.T) {
+	t := assert.New(x)
+	dt := load(t)
+	defer dt.Close()
+	a := find(t, dt, "a")
+	kids, err := a.Children()
+	t.Nil(err)
+	parents, err := kids[0].Parents()
+	t.Nil(err)
+	t.Equal([]string{"a", "c"}, names(dt, parents))
+	for _, p := range parents {
+		t.Equal(3, len(p.(*Node).txs))
+	}
+}
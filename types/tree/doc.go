@@ -0,0 +1,9 @@
+/*
+The tree datatype. The input is a forest of rooted, labeled trees (eg. ASTs)
+and a pattern is a rooted labeled tree which is supported by every input
+tree it occurs in. Patterns may be matched as induced subtrees (parent-child
+edges are preserved) or embedded subtrees (parent-child edges in the pattern
+map to ancestor-descendant paths in the input) and may be ordered (sibling
+order must be preserved) or unordered.
+*/
+package tree
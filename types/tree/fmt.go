@@ -0,0 +1,100 @@
+package tree
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+import (
+	"github.com/timtadh/regrax/lattice"
+)
+
+type Formatter struct {
+	PrFmt lattice.PrFormatter
+}
+
+func (f *Formatter) PrFormatter() lattice.PrFormatter {
+	return f.PrFmt
+}
+
+func (f *Formatter) FileExt() string {
+	return ".sexpr"
+}
+
+func atom(label string) string {
+	if label == "" || strings.IndexFunc(label, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"' || r == ';'
+	}) >= 0 {
+		return strconv.Quote(label)
+	}
+	return label
+}
+
+// PatternName writes the pattern as an s-expression (the same format the
+// sexpr loader reads).
+func (f *Formatter) PatternName(node lattice.Node) string {
+	n := node.(*Node)
+	if n.pat.Size() == 0 {
+		return "()"
+	}
+	kids := n.pat.kids()
+	var sexpr func(i int) string
+	sexpr = func(i int) string {
+		label := atom(n.dt.LabelName(n.pat.Labels[i]))
+		if len(kids[i]) == 0 {
+			return label
+		}
+		parts := make([]string, 0, len(kids[i])+1)
+		parts = append(parts, label)
+		for _, k := range kids[i] {
+			parts = append(parts, sexpr(k))
+		}
+		return "(" + strings.Join(parts, " ") + ")"
+	}
+	return sexpr(0)
+}
+
+func (f *Formatter) Pattern(node lattice.Node) (string, error) {
+	return f.PatternName(node), nil
+}
+
+func (f *Formatter) Embeddings(node lattice.Node) ([]string, error) {
+	n := node.(*Node)
+	txs := make([]string, 0, len(n.txs))
+	for _, tx := range n.txs {
+		txs = append(txs, fmt.Sprintf("%v", tx))
+	}
+	return txs, nil
+}
+
+func (f *Formatter) FormatPattern(w io.Writer, node lattice.Node) error {
+	n := node.(*Node)
+	pat, err := f.Pattern(node)
+	if err != nil {
+		return err
+	}
+	max := ""
+	if ismax, err := n.Maximal(); err != nil {
+		return err
+	} else if ismax {
+		max = " ; maximal"
+	}
+	_, err = fmt.Fprintf(w, "%s%s\n", pat, max)
+	return err
+}
+
+func (f *Formatter) FormatEmbeddings(w io.Writer, node lattice.Node) error {
+	txs, err := f.Embeddings(node)
+	if err != nil {
+		return err
+	}
+	pat, err := f.Pattern(node)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s ; %s\n", pat, strings.Join(txs, " "))
+	return err
+}
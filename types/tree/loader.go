@@ -0,0 +1,259 @@
+package tree
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"unicode"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/lattice"
+)
+
+type parsed struct {
+	label string
+	kids  []*parsed
+}
+
+type parser func(io.Reader, func(*parsed) error) error
+
+type loader struct {
+	trees    *Trees
+	parse    parser
+	labelIds map[string]int32
+}
+
+func newLoader(config *config.Config, min, max int, embedded, unordered bool, parse parser) (lattice.Loader, error) {
+	trees, err := NewTrees(config, min, max, embedded, unordered)
+	if err != nil {
+		return nil, err
+	}
+	l := &loader{
+		trees:    trees,
+		parse:    parse,
+		labelIds: make(map[string]int32),
+	}
+	return l, nil
+}
+
+// NewJsonLoader reads a stream of json trees of the form
+//
+//	{"label": "...", "children": [ ... ]}
+func NewJsonLoader(config *config.Config, min, max int, embedded, unordered bool) (lattice.Loader, error) {
+	return newLoader(config, min, max, embedded, unordered, parseJson)
+}
+
+// NewSExprLoader reads a stream of s-expressions. The first atom of a list
+// is the label of the node and the rest are its children. A ';' starts a
+// comment which runs until the end of the line.
+//
+//	(if (< a b) (call f a) (return b))
+func NewSExprLoader(config *config.Config, min, max int, embedded, unordered bool) (lattice.Loader, error) {
+	return newLoader(config, min, max, embedded, unordered, parseSExpr)
+}
+
+func (l *loader) labelId(name string) int32 {
+	if id, has := l.labelIds[name]; has {
+		return id
+	}
+	id := int32(len(l.trees.LabelNames))
+	l.labelIds[name] = id
+	l.trees.LabelNames = append(l.trees.LabelNames, name)
+	return id
+}
+
+func (l *loader) build(root *parsed) *Tree {
+	t := &Tree{has: make(map[int32]bool)}
+	var visit func(n *parsed, parent int32) int32
+	visit = func(n *parsed, parent int32) int32 {
+		id := int32(len(t.Labels))
+		label := l.labelId(n.label)
+		t.Labels = append(t.Labels, label)
+		t.Parent = append(t.Parent, parent)
+		t.End = append(t.End, id)
+		t.Kids = append(t.Kids, nil)
+		t.has[label] = true
+		for _, kid := range n.kids {
+			t.Kids[id] = append(t.Kids[id], visit(kid, id))
+		}
+		t.End[id] = int32(len(t.Labels) - 1)
+		return id
+	}
+	visit(root, -1)
+	return t
+}
+
+func (l *loader) Load(input lattice.Input) (lattice.DataType, error) {
+	in, closer := input()
+	defer closer()
+	err := l.parse(in, func(root *parsed) error {
+		if len(l.trees.Forest)%1000 == 0 {
+			errors.Logf("INFO", "tree %d", len(l.trees.Forest))
+		}
+		l.trees.Forest = append(l.trees.Forest, l.build(root))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	l.trees.InvertedIndex = make([][]int32, len(l.trees.LabelNames))
+	for tx, t := range l.trees.Forest {
+		for label := range t.has {
+			l.trees.InvertedIndex[label] = append(l.trees.InvertedIndex[label], int32(tx))
+		}
+	}
+	l.trees.empty = &Node{&Pattern{}, l.trees, []int32{}}
+	nodes := make([]lattice.Node, 0, 10)
+	for label, txs := range l.trees.InvertedIndex {
+		if len(txs) >= l.trees.Support() {
+			errors.Logf("INFO", "label %v len(txs) %d", l.trees.LabelNames[label], len(txs))
+			l.trees.FrequentLabels = append(l.trees.FrequentLabels, int32(label))
+			nodes = append(nodes, &Node{
+				pat: &Pattern{[]int32{0}, []int32{int32(label)}},
+				dt:  l.trees,
+				txs: txs,
+			})
+		}
+	}
+	l.trees.FrequentItems = nodes
+	return l.trees, nil
+}
+
+type jsonTree struct {
+	Label    interface{} `json:"label"`
+	Children []*jsonTree `json:"children"`
+}
+
+func (j *jsonTree) parsed() *parsed {
+	p := &parsed{label: fmt.Sprintf("%v", j.Label)}
+	if s, ok := j.Label.(string); ok {
+		p.label = s
+	}
+	for _, kid := range j.Children {
+		p.kids = append(p.kids, kid.parsed())
+	}
+	return p
+}
+
+func parseJson(in io.Reader, do func(*parsed) error) error {
+	dec := json.NewDecoder(in)
+	for {
+		var t jsonTree
+		if err := dec.Decode(&t); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := do(t.parsed()); err != nil {
+			return err
+		}
+	}
+}
+
+func sexprTokens(in io.Reader) ([]string, error) {
+	text, err := ioutil.ReadAll(bufio.NewReader(in))
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]string, 0, len(text)/4)
+	runes := []rune(string(text))
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == ';':
+			// comment until the end of the line
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '(' || r == ')':
+			tokens = append(tokens, string(r))
+			i++
+		case r == '"':
+			j := i + 1
+			for j < len(runes) && runes[j] != '"' {
+				if runes[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(runes) {
+				return nil, errors.Errorf("unterminated string in s-expression")
+			}
+			s, err := strconv.Unquote(string(runes[i : j+1]))
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, "\""+s)
+			i = j + 1
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && runes[j] != '(' && runes[j] != ')' && runes[j] != ';' {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+func parseSExpr(in io.Reader, do func(*parsed) error) error {
+	tokens, err := sexprTokens(in)
+	if err != nil {
+		return err
+	}
+	atom := func(tok string) string {
+		if len(tok) > 0 && tok[0] == '"' {
+			return tok[1:]
+		}
+		return tok
+	}
+	var expr func(i int) (*parsed, int, error)
+	expr = func(i int) (*parsed, int, error) {
+		if tokens[i] == ")" {
+			return nil, i, errors.Errorf("unexpected ')' in s-expression")
+		} else if tokens[i] != "(" {
+			return &parsed{label: atom(tokens[i])}, i + 1, nil
+		}
+		i++
+		if i >= len(tokens) || tokens[i] == "(" || tokens[i] == ")" {
+			return nil, i, errors.Errorf("expected a label after '(' in s-expression")
+		}
+		n := &parsed{label: atom(tokens[i])}
+		i++
+		for i < len(tokens) && tokens[i] != ")" {
+			kid, j, err := expr(i)
+			if err != nil {
+				return nil, j, err
+			}
+			n.kids = append(n.kids, kid)
+			i = j
+		}
+		if i >= len(tokens) {
+			return nil, i, errors.Errorf("unbalanced parens in s-expression")
+		}
+		return n, i + 1, nil
+	}
+	for i := 0; i < len(tokens); {
+		n, j, err := expr(i)
+		if err != nil {
+			return err
+		}
+		if err := do(n); err != nil {
+			return err
+		}
+		i = j
+	}
+	return nil
+}
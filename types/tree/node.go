@@ -0,0 +1,316 @@
+package tree
+
+import (
+	"fmt"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+)
+
+import (
+	"github.com/timtadh/regrax/lattice"
+	"github.com/timtadh/regrax/stores/ints_int"
+	"github.com/timtadh/regrax/stores/ints_ints"
+)
+
+type Node struct {
+	pat *Pattern
+	dt  *Trees
+	txs []int32
+}
+
+func TryLoadNode(pat *Pattern, dt *Trees) (n *Node, _ error) {
+	err := dt.Embeddings.DoFind(pat.encode(), func(key, txs []int32) error {
+		n = &Node{decode(key), dt, txs}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func LoadNode(pat *Pattern, dt *Trees) (n *Node, err error) {
+	n, err = TryLoadNode(pat, dt)
+	if err != nil {
+		return nil, err
+	} else if n == nil {
+		return nil, errors.Errorf("Expected node %v to be in embeddings store but it wasn't", pat)
+	}
+	return n, nil
+}
+
+func (n *Node) Pattern() lattice.Pattern {
+	return n.pat
+}
+
+func (n *Node) Save() error {
+	key := n.pat.encode()
+	if has, err := n.dt.Embeddings.Has(key); err != nil {
+		return err
+	} else if has {
+		return nil
+	}
+	return n.dt.Embeddings.Add(key, n.txs)
+}
+
+func (n *Node) String() string {
+	return fmt.Sprintf("<Node %v %v>", n.pat.encode(), len(n.txs))
+}
+
+func (n *Node) normalize(p *Pattern) *Pattern {
+	if n.dt.Unordered {
+		return p.canonical()
+	}
+	return p
+}
+
+// supporting computes the txs in which p occurs by checking the trees
+// containing the least common label of p.
+func (n *Node) supporting(p *Pattern) []int32 {
+	var smallest []int32
+	for i, label := range p.Labels {
+		if i == 0 || len(n.dt.InvertedIndex[label]) < len(smallest) {
+			smallest = n.dt.InvertedIndex[label]
+		}
+	}
+	txs := make([]int32, 0, len(smallest))
+	for _, tx := range smallest {
+		if n.dt.Occurs(p, n.dt.Forest[tx]) {
+			txs = append(txs, tx)
+		}
+	}
+	return txs
+}
+
+// Parents removes a leaf. The root is never removed since the children
+// only ever add leaves.
+func (n *Node) Parents() ([]lattice.Node, error) {
+	if n.pat.Size() == 0 {
+		return []lattice.Node{}, nil
+	} else if n.pat.Size() == 1 {
+		return []lattice.Node{n.dt.empty}, nil
+	}
+	key := n.pat.encode()
+	if has, err := n.dt.ParentCount.Has(key); err != nil {
+		return nil, err
+	} else if has {
+		return n.cached(n.dt.Parents, key)
+	}
+	kids := n.pat.kids()
+	seen := make(map[string]bool)
+	nodes := make([]lattice.Node, 0, n.pat.Size())
+	for i := range kids {
+		if len(kids[i]) != 0 {
+			continue
+		}
+		p := n.normalize(n.pat.remove(i))
+		label := string(p.Label())
+		if seen[label] {
+			continue
+		}
+		seen[label] = true
+		if node, err := TryLoadNode(p, n.dt); err != nil {
+			return nil, err
+		} else if node != nil {
+			nodes = append(nodes, node)
+			continue
+		}
+		node := &Node{p, n.dt, n.supporting(p)}
+		err := node.Save()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	err := n.cache(n.dt.ParentCount, n.dt.Parents, key, nodes)
+	if err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+func (n *Node) Children() ([]lattice.Node, error) {
+	return n.kids(n.dt.ChildCount, n.dt.Children, n.allCandidateKids)
+}
+
+func (n *Node) CanonKids() ([]lattice.Node, error) {
+	return n.kids(n.dt.CanonKidCount, n.dt.CanonKids, n.canonCandidateKids)
+}
+
+func (n *Node) kids(counts ints_int.MultiMap, kids ints_ints.MultiMap, candidates func() []*Pattern) ([]lattice.Node, error) {
+	if n.pat.Size() == 0 {
+		return n.dt.FrequentItems, nil
+	}
+	if n.pat.Size() >= n.dt.MaxNodes {
+		return []lattice.Node{}, nil
+	}
+	key := n.pat.encode()
+	if has, err := counts.Has(key); err != nil {
+		return nil, err
+	} else if has {
+		return n.cached(kids, key)
+	}
+	nodes, err := n.nodesFromCandidateKids(candidates())
+	if err != nil {
+		return nil, err
+	}
+	err = n.cache(counts, kids, key, nodes)
+	if err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// canonCandidateKids performs rightmost path extension: a new last child
+// is added to a node on the rightmost path. For unordered trees only the
+// extensions which are already in canonical form are kept.
+func (n *Node) canonCandidateKids() []*Pattern {
+	exts := make([]*Pattern, 0, 10)
+	for _, parent := range n.pat.rightmostPath() {
+		for _, label := range n.dt.FrequentLabels {
+			ext := n.pat.insert(parent, n.pat.Size(), label)
+			if n.dt.Unordered && compare(ext, ext.canonical()) != 0 {
+				continue
+			}
+			exts = append(exts, ext)
+		}
+	}
+	return exts
+}
+
+// allCandidateKids adds a new leaf in every position of the pattern.
+func (n *Node) allCandidateKids() []*Pattern {
+	kids := n.pat.kids()
+	seen := make(map[string]bool)
+	exts := make([]*Pattern, 0, 10)
+	add := func(p *Pattern) {
+		p = n.normalize(p)
+		label := string(p.Label())
+		if !seen[label] {
+			seen[label] = true
+			exts = append(exts, p)
+		}
+	}
+	for parent := range kids {
+		positions := make([]int, 0, len(kids[parent])+1)
+		if n.dt.Unordered {
+			positions = append(positions, n.pat.end(parent)+1)
+		} else {
+			for _, kid := range kids[parent] {
+				positions = append(positions, kid)
+			}
+			positions = append(positions, n.pat.end(parent)+1)
+		}
+		for _, at := range positions {
+			for _, label := range n.dt.FrequentLabels {
+				add(n.pat.insert(parent, at, label))
+			}
+		}
+	}
+	return exts
+}
+
+func (n *Node) nodesFromCandidateKids(exts []*Pattern) ([]lattice.Node, error) {
+	nodes := make([]lattice.Node, 0, 10)
+	for _, ext := range exts {
+		txs := make([]int32, 0, len(n.txs))
+		for _, tx := range n.txs {
+			if n.dt.Occurs(ext, n.dt.Forest[tx]) {
+				txs = append(txs, tx)
+			}
+		}
+		if len(txs) < n.dt.Support() {
+			continue
+		}
+		node := &Node{ext, n.dt, txs}
+		err := node.Save()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+func (n *Node) AdjacentCount() (int, error) {
+	pc, err := n.ParentCount()
+	if err != nil {
+		return 0, err
+	}
+	cc, err := n.ChildCount()
+	if err != nil {
+		return 0, err
+	}
+	return pc + cc, nil
+}
+
+func (n *Node) ParentCount() (int, error) {
+	return n.count(n.dt.ParentCount, n.Parents)
+}
+
+func (n *Node) ChildCount() (int, error) {
+	return n.count(n.dt.ChildCount, n.Children)
+}
+
+func (n *Node) count(counts ints_int.MultiMap, compute func() ([]lattice.Node, error)) (int, error) {
+	key := n.pat.encode()
+	if has, err := counts.Has(key); err != nil {
+		return 0, err
+	} else if !has {
+		nodes, err := compute()
+		if err != nil {
+			return 0, err
+		}
+		return len(nodes), nil
+	}
+	var count int32
+	err := counts.DoFind(key, func(_ []int32, c int32) error {
+		count = c
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+func (n *Node) Maximal() (bool, error) {
+	count, err := n.ChildCount()
+	if err != nil {
+		return false, err
+	}
+	return count == 0, nil
+}
+
+func (n *Node) cache(counts ints_int.MultiMap, m ints_ints.MultiMap, key []int32, nodes []lattice.Node) error {
+	for _, node := range nodes {
+		err := m.Add(key, node.(*Node).pat.encode())
+		if err != nil {
+			return err
+		}
+	}
+	return counts.Add(key, int32(len(nodes)))
+}
+
+func (n *Node) cached(m ints_ints.MultiMap, key []int32) (nodes []lattice.Node, _ error) {
+	nodes = make([]lattice.Node, 0, 10)
+	doerr := m.DoFind(key,
+		func(_, value []int32) error {
+			node, err := LoadNode(decode(value), n.dt)
+			if err != nil {
+				return err
+			}
+			nodes = append(nodes, node)
+			return nil
+		})
+	if doerr != nil {
+		return nil, doerr
+	}
+	return nodes, nil
+}
+
+func (n *Node) Lattice() (*lattice.Lattice, error) {
+	return nil, &lattice.NoLattice{}
+}
@@ -0,0 +1,285 @@
+package tree
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+import (
+	"github.com/timtadh/data-structures/types"
+)
+
+import (
+	"github.com/timtadh/regrax/lattice"
+)
+
+// A Pattern is a rooted labeled tree stored as its depth first (preorder)
+// depth-label sequence. The root has depth 0.
+type Pattern struct {
+	Depths []int32
+	Labels []int32
+}
+
+func (p *Pattern) Size() int {
+	return len(p.Labels)
+}
+
+// encoding of the pattern used as the key in the stores
+// [d0, l0, d1, l1, ...]
+func (p *Pattern) encode() []int32 {
+	enc := make([]int32, 0, 2*len(p.Labels))
+	for i := range p.Labels {
+		enc = append(enc, p.Depths[i], p.Labels[i])
+	}
+	return enc
+}
+
+func decode(enc []int32) *Pattern {
+	p := &Pattern{
+		Depths: make([]int32, 0, len(enc)/2),
+		Labels: make([]int32, 0, len(enc)/2),
+	}
+	for i := 0; i+1 < len(enc); i += 2 {
+		p.Depths = append(p.Depths, enc[i])
+		p.Labels = append(p.Labels, enc[i+1])
+	}
+	return p
+}
+
+// parents computes the index of the parent of each node (-1 for the root)
+func (p *Pattern) parents() []int {
+	parents := make([]int, len(p.Depths))
+	stack := make([]int, 0, 10)
+	for i, d := range p.Depths {
+		stack = stack[:d]
+		if len(stack) == 0 {
+			parents[i] = -1
+		} else {
+			parents[i] = stack[len(stack)-1]
+		}
+		stack = append(stack, i)
+	}
+	return parents
+}
+
+func (p *Pattern) kids() [][]int {
+	kids := make([][]int, len(p.Depths))
+	for i, parent := range p.parents() {
+		if parent >= 0 {
+			kids[parent] = append(kids[parent], i)
+		}
+	}
+	return kids
+}
+
+// end of the subtree rooted at i (the last index in preorder)
+func (p *Pattern) end(i int) int {
+	j := i + 1
+	for j < len(p.Depths) && p.Depths[j] > p.Depths[i] {
+		j++
+	}
+	return j - 1
+}
+
+// rightmostPath lists the nodes from the root to the last node in preorder
+func (p *Pattern) rightmostPath() []int {
+	if len(p.Depths) == 0 {
+		return []int{}
+	}
+	path := make([]int, 0, 10)
+	for i := len(p.Depths) - 1; i >= 0; {
+		path = append(path, i)
+		parent := -1
+		for j := i - 1; j >= 0; j-- {
+			if p.Depths[j] == p.Depths[i]-1 {
+				parent = j
+				break
+			}
+		}
+		i = parent
+	}
+	return path
+}
+
+// insert adds a new node with label as the child of parent. The new node
+// is placed at preorder position at (which must be directly after a
+// complete subtree of one of parent's children or directly after parent).
+func (p *Pattern) insert(parent, at int, label int32) *Pattern {
+	depth := int32(0)
+	if parent >= 0 {
+		depth = p.Depths[parent] + 1
+	}
+	q := &Pattern{
+		Depths: make([]int32, 0, len(p.Depths)+1),
+		Labels: make([]int32, 0, len(p.Labels)+1),
+	}
+	q.Depths = append(q.Depths, p.Depths[:at]...)
+	q.Labels = append(q.Labels, p.Labels[:at]...)
+	q.Depths = append(q.Depths, depth)
+	q.Labels = append(q.Labels, label)
+	q.Depths = append(q.Depths, p.Depths[at:]...)
+	q.Labels = append(q.Labels, p.Labels[at:]...)
+	return q
+}
+
+// remove deletes the leaf i.
+func (p *Pattern) remove(i int) *Pattern {
+	q := &Pattern{
+		Depths: make([]int32, 0, len(p.Depths)-1),
+		Labels: make([]int32, 0, len(p.Labels)-1),
+	}
+	q.Depths = append(q.Depths, p.Depths[:i]...)
+	q.Labels = append(q.Labels, p.Labels[:i]...)
+	q.Depths = append(q.Depths, p.Depths[i+1:]...)
+	q.Labels = append(q.Labels, p.Labels[i+1:]...)
+	return q
+}
+
+// compare two depth-label sequences. Deeper nodes sort first followed by
+// larger labels. Ordering siblings from largest to smallest under this
+// comparison gives the left-heavy canonical form of an unordered tree in
+// which removing the last node (in preorder) yields a canonical tree.
+func compare(a, b *Pattern) int {
+	for i := 0; i < len(a.Labels) && i < len(b.Labels); i++ {
+		if a.Depths[i] != b.Depths[i] {
+			if a.Depths[i] > b.Depths[i] {
+				return 1
+			}
+			return -1
+		}
+		if a.Labels[i] != b.Labels[i] {
+			if a.Labels[i] > b.Labels[i] {
+				return 1
+			}
+			return -1
+		}
+	}
+	if len(a.Labels) == len(b.Labels) {
+		return 0
+	} else if len(a.Labels) > len(b.Labels) {
+		return 1
+	}
+	return -1
+}
+
+type heavyFirst []*Pattern
+
+func (h heavyFirst) Len() int           { return len(h) }
+func (h heavyFirst) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h heavyFirst) Less(i, j int) bool { return compare(h[i], h[j]) > 0 }
+
+// canonical computes the canonical form of the pattern treated as an
+// unordered tree.
+func (p *Pattern) canonical() *Pattern {
+	if len(p.Labels) == 0 {
+		return p
+	}
+	kids := p.kids()
+	var canon func(i int) *Pattern
+	canon = func(i int) *Pattern {
+		subs := make([]*Pattern, 0, len(kids[i]))
+		for _, k := range kids[i] {
+			subs = append(subs, canon(k))
+		}
+		sort.Sort(heavyFirst(subs))
+		q := &Pattern{
+			Depths: []int32{p.Depths[i]},
+			Labels: []int32{p.Labels[i]},
+		}
+		for _, s := range subs {
+			q.Depths = append(q.Depths, s.Depths...)
+			q.Labels = append(q.Labels, s.Labels...)
+		}
+		return q
+	}
+	return canon(0)
+}
+
+func (p *Pattern) Label() []byte {
+	enc := p.encode()
+	bytes := make([]byte, 4*(len(enc)+1))
+	binary.BigEndian.PutUint32(bytes[0:4], uint32(len(p.Labels)))
+	for i, x := range enc {
+		s := 4 * (i + 1)
+		binary.BigEndian.PutUint32(bytes[s:s+4], uint32(x))
+	}
+	return bytes
+}
+
+func (p *Pattern) Level() int {
+	return len(p.Labels) + 1
+}
+
+// Distance is the Jaccard distance between the label multisets of the
+// patterns.
+func (a *Pattern) Distance(o lattice.Pattern) float64 {
+	b := o.(*Pattern)
+	counts := make(map[int32]int)
+	for _, l := range a.Labels {
+		counts[l]++
+	}
+	inter := 0
+	for _, l := range b.Labels {
+		if counts[l] > 0 {
+			counts[l]--
+			inter++
+		}
+	}
+	union := len(a.Labels) + len(b.Labels) - inter
+	if union == 0 {
+		return 0
+	}
+	return 1.0 - float64(inter)/float64(union)
+}
+
+func (p *Pattern) Equals(o types.Equatable) bool {
+	switch b := o.(type) {
+	case *Pattern:
+		return compare(p, b) == 0
+	default:
+		return false
+	}
+}
+
+func (p *Pattern) Less(o types.Sortable) bool {
+	switch b := o.(type) {
+	case *Pattern:
+		return compare(p, b) < 0
+	default:
+		return false
+	}
+}
+
+func (p *Pattern) Hash() int {
+	return types.ByteSlice(p.Label()).Hash()
+}
+
+// CommonAncestor computes a tree which is a (rooted at the root) subtree of
+// both a and b by greedily matching the children of the roots in order. It
+// is a common ancestor in the lattice but not necessarily the largest one.
+func CommonAncestor(a, b *Pattern) *Pattern {
+	c := &Pattern{Depths: []int32{}, Labels: []int32{}}
+	if a.Size() == 0 || b.Size() == 0 || a.Labels[0] != b.Labels[0] {
+		return c
+	}
+	akids := a.kids()
+	bkids := b.kids()
+	var common func(i, j int, depth int32)
+	common = func(i, j int, depth int32) {
+		c.Depths = append(c.Depths, depth)
+		c.Labels = append(c.Labels, a.Labels[i])
+		next := 0
+		for _, x := range akids[i] {
+			for k := next; k < len(bkids[j]); k++ {
+				y := bkids[j][k]
+				if a.Labels[x] == b.Labels[y] {
+					common(x, y, depth+1)
+					next = k + 1
+					break
+				}
+			}
+		}
+	}
+	common(0, 0, 0)
+	return c
+}
@@ -0,0 +1,169 @@
+package tree
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+import (
+	"io"
+	"strings"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/lattice"
+)
+
+var forest = `
+(a b c)
+(a c b)
+(a (x b) c)
+`
+
+func load(t *assert.Assertions, embedded, unordered bool) *Trees {
+	c := &config.Config{Support: 2}
+	l, err := NewSExprLoader(c, 0, 10, embedded, unordered)
+	t.Nil(err)
+	dt, err := l.Load(func() (io.Reader, func()) {
+		return strings.NewReader(forest), func() {}
+	})
+	t.Nil(err)
+	return dt.(*Trees)
+}
+
+func pattern(t *assert.Assertions, dt *Trees, sexpr string) *Pattern {
+	ids := make(map[string]int32)
+	for i, name := range dt.LabelNames {
+		ids[name] = int32(i)
+	}
+	var p *Pattern
+	err := parseSExpr(strings.NewReader(sexpr), func(root *parsed) error {
+		p = &Pattern{}
+		var visit func(n *parsed, depth int32)
+		visit = func(n *parsed, depth int32) {
+			p.Depths = append(p.Depths, depth)
+			p.Labels = append(p.Labels, ids[n.label])
+			for _, kid := range n.kids {
+				visit(kid, depth+1)
+			}
+		}
+		visit(root, 0)
+		return nil
+	})
+	t.Nil(err)
+	return p
+}
+
+func support(dt *Trees, p *Pattern) int {
+	count := 0
+	for _, tree := range dt.Forest {
+		if dt.Occurs(p, tree) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestOccurs(x *testing.T) {
+	t := assert.New(x)
+	induced := load(t, false, false)
+	t.Equal(2, support(induced, pattern(t, induced, "(a b)")))
+	t.Equal(1, support(induced, pattern(t, induced, "(a b c)")))
+	t.Equal(1, support(induced, pattern(t, induced, "(a c b)")))
+	unordered := load(t, false, true)
+	t.Equal(2, support(unordered, pattern(t, unordered, "(a b c)")))
+	embedded := load(t, true, false)
+	t.Equal(3, support(embedded, pattern(t, embedded, "(a b)")))
+	t.Equal(2, support(embedded, pattern(t, embedded, "(a b c)")))
+}
+
+func TestCanonical(x *testing.T) {
+	t := assert.New(x)
+	dt := load(t, false, true)
+	a := pattern(t, dt, "(a b c)").canonical()
+	b := pattern(t, dt, "(a c b)").canonical()
+	t.True(a.Equals(b))
+}
+
+func TestCanonKids(x *testing.T) {
+	t := assert.New(x)
+	dt := load(t, false, true)
+	fmtr := &Formatter{}
+	var a lattice.Node
+	for _, n := range dt.FrequentItems {
+		if fmtr.PatternName(n) == "a" {
+			a = n
+		}
+	}
+	kids, err := a.CanonKids()
+	t.Nil(err)
+	names := make(map[string]bool)
+	for _, kid := range kids {
+		names[fmtr.PatternName(kid)] = true
+	}
+	t.Equal(map[string]bool{"(a b)": true, "(a c)": true}, names)
+	for _, kid := range kids {
+		grandkids, err := kid.CanonKids()
+		t.Nil(err)
+		for _, gk := range grandkids {
+			t.Equal("(a c b)", fmtr.PatternName(gk))
+		}
+	}
+}
+
+func TestParentsChildrenSymmetric(x *testing.T) {
+	t := assert.New(x)
+	fmtr := &Formatter{}
+	names := func(nodes []lattice.Node) map[string]bool {
+		m := make(map[string]bool)
+		for _, n := range nodes {
+			m[fmtr.PatternName(n)] = true
+		}
+		return m
+	}
+	for _, mode := range [][]bool{{false, false}, {false, true}, {true, false}} {
+		dt := load(t, mode[0], mode[1])
+		seen := make(map[string]bool)
+		queue := append([]lattice.Node{}, dt.FrequentItems...)
+		for len(queue) > 0 {
+			n := queue[0]
+			queue = queue[1:]
+			name := fmtr.PatternName(n)
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			parents, err := n.Parents()
+			t.Nil(err)
+			t.True(len(parents) > 0, "%v %v has no parents", mode, name)
+			for _, p := range parents {
+				kids, err := p.Children()
+				t.Nil(err)
+				t.True(names(kids)[name], "%v %v is not a child of its parent %v", mode, name, fmtr.PatternName(p))
+			}
+			kids, err := n.Children()
+			t.Nil(err)
+			for _, k := range kids {
+				parents, err := k.Parents()
+				t.Nil(err)
+				t.True(names(parents)[name], "%v %v is not a parent of its child %v", mode, name, fmtr.PatternName(k))
+				queue = append(queue, k)
+			}
+		}
+		t.True(len(seen) > len(dt.FrequentItems), "%v", mode)
+	}
+}
+
+func TestAtomRoundTrip(x *testing.T) {
+	t := assert.New(x)
+	for _, label := range []string{"a", "a b", "a;b", "(a)", `"a"`, ""} {
+		var got string
+		err := parseSExpr(strings.NewReader("("+atom(label)+" b)"), func(root *parsed) error {
+			got = root.label
+			return nil
+		})
+		t.Nil(err)
+		t.Equal(label, got)
+	}
+	t.Equal("a", atom("a"))
+	t.Equal(`"a;b"`, atom("a;b"))
+}
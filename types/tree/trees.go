@@ -0,0 +1,222 @@
+package tree
+
+import (
+	"strconv"
+)
+
+import (
+	"github.com/timtadh/regrax/config"
+	"github.com/timtadh/regrax/lattice"
+	"github.com/timtadh/regrax/stores/ints_int"
+	"github.com/timtadh/regrax/stores/ints_ints"
+)
+
+// A Tree is one tree of the input forest. The nodes are numbered in
+// preorder so node i is an ancestor of node j iff i < j <= End[i].
+type Tree struct {
+	Labels []int32
+	Parent []int32
+	End    []int32
+	Kids   [][]int32
+	has    map[int32]bool
+}
+
+func (t *Tree) HasLabel(label int32) bool {
+	return t.has[label]
+}
+
+type Trees struct {
+	MinNodes, MaxNodes int
+	Embedded           bool // match ancestor-descendant rather than parent-child
+	Unordered          bool // ignore the order of siblings
+	Forest             []*Tree
+	InvertedIndex      [][]int32 // label -> txs containing it
+	LabelNames         []string
+	Parents            ints_ints.MultiMap
+	ParentCount        ints_int.MultiMap
+	Children           ints_ints.MultiMap
+	ChildCount         ints_int.MultiMap
+	CanonKids          ints_ints.MultiMap
+	CanonKidCount      ints_int.MultiMap
+	Embeddings         ints_ints.MultiMap
+	FrequentLabels     []int32
+	FrequentItems      []lattice.Node
+	empty              lattice.Node
+	config             *config.Config
+}
+
+func NewTrees(config *config.Config, min, max int, embedded, unordered bool) (t *Trees, err error) {
+	parents, err := config.IntsIntsMultiMap("trees-parents")
+	if err != nil {
+		return nil, err
+	}
+	parentCount, err := config.IntsIntMultiMap("trees-parent-count")
+	if err != nil {
+		return nil, err
+	}
+	children, err := config.IntsIntsMultiMap("trees-children")
+	if err != nil {
+		return nil, err
+	}
+	childCount, err := config.IntsIntMultiMap("trees-child-count")
+	if err != nil {
+		return nil, err
+	}
+	canonKids, err := config.IntsIntsMultiMap("trees-canon-kids")
+	if err != nil {
+		return nil, err
+	}
+	canonKidCount, err := config.IntsIntMultiMap("trees-canon-kid-count")
+	if err != nil {
+		return nil, err
+	}
+	embeddings, err := config.IntsIntsMultiMap("trees-embeddings")
+	if err != nil {
+		return nil, err
+	}
+	t = &Trees{
+		MinNodes:      min,
+		MaxNodes:      max,
+		Embedded:      embedded,
+		Unordered:     unordered,
+		Parents:       parents,
+		ParentCount:   parentCount,
+		Children:      children,
+		ChildCount:    childCount,
+		CanonKids:     canonKids,
+		CanonKidCount: canonKidCount,
+		Embeddings:    embeddings,
+		config:        config,
+	}
+	return t, nil
+}
+
+func (t *Trees) Support() int {
+	return t.config.Support
+}
+
+func (t *Trees) LargestLevel() int {
+	return t.MaxNodes
+}
+
+func (t *Trees) MinimumLevel() int {
+	return t.MinNodes
+}
+
+func (t *Trees) Root() lattice.Node {
+	return t.empty
+}
+
+func (t *Trees) Acceptable(node lattice.Node) bool {
+	n := node.(*Node)
+	size := n.pat.Size()
+	return t.MinNodes <= size && size <= t.MaxNodes
+}
+
+func (t *Trees) TooLarge(node lattice.Node) bool {
+	n := node.(*Node)
+	return n.pat.Size() > t.MaxNodes
+}
+
+func (t *Trees) LabelName(label int32) string {
+	if int(label) < len(t.LabelNames) {
+		return t.LabelNames[label]
+	}
+	return strconv.Itoa(int(label))
+}
+
+func (t *Trees) Close() error {
+	t.config.AsyncTasks.Wait()
+	t.Parents.Close()
+	t.ParentCount.Close()
+	t.Children.Close()
+	t.ChildCount.Close()
+	t.CanonKids.Close()
+	t.CanonKidCount.Close()
+	t.Embeddings.Close()
+	return nil
+}
+
+// Occurs returns true if the pattern has an occurrence in the tree.
+func (t *Trees) Occurs(p *Pattern, tree *Tree) bool {
+	if p.Size() == 0 {
+		return true
+	}
+	for _, l := range p.Labels {
+		if !tree.HasLabel(l) {
+			return false
+		}
+	}
+	parents := p.parents()
+	// prev[i] is the previous sibling of i in the pattern (or -1)
+	prev := make([]int, p.Size())
+	last := make(map[int]int)
+	for i, parent := range parents {
+		if j, has := last[parent]; has {
+			prev[i] = j
+		} else {
+			prev[i] = -1
+		}
+		last[parent] = i
+	}
+	m := make([]int32, p.Size())
+	used := make(map[int32]bool)
+	disjoint := func(a, b int32) bool {
+		return tree.End[a] < b || tree.End[b] < a
+	}
+	var match func(i int) bool
+	try := func(i int, v int32) bool {
+		if tree.Labels[v] != p.Labels[i] || used[v] {
+			return false
+		}
+		if s := prev[i]; s >= 0 {
+			if !t.Unordered {
+				if v <= tree.End[m[s]] {
+					return false
+				}
+			} else {
+				for j := s; j >= 0; j = prev[j] {
+					if !disjoint(m[j], v) {
+						return false
+					}
+				}
+			}
+		}
+		m[i] = v
+		used[v] = true
+		if match(i + 1) {
+			return true
+		}
+		delete(used, v)
+		return false
+	}
+	match = func(i int) bool {
+		if i >= p.Size() {
+			return true
+		}
+		if i == 0 {
+			for v := range tree.Labels {
+				if try(0, int32(v)) {
+					return true
+				}
+			}
+			return false
+		}
+		u := m[parents[i]]
+		if t.Embedded {
+			for v := u + 1; v <= tree.End[u]; v++ {
+				if try(i, v) {
+					return true
+				}
+			}
+		} else {
+			for _, v := range tree.Kids[u] {
+				if try(i, v) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return match(0)
+}